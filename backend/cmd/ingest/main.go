@@ -0,0 +1,216 @@
+// Command ingest loads a raw breach dump. Every value is normalized and
+// hashed with pkg/hashing, exactly like the browser does, before it reaches
+// the database; the per-breach table, the shared sensitive tables and the
+// breach_metadata row are written in one transaction.
+//
+//	ingest -file dump.csv -name acme_2024 -date 2024-03-01 -industry Retail
+//	ingest -file dump.jsonl -name acme_2024 -date 2024-03-01 -map "email=mail,password=pw"
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/database"
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+)
+
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	file := flag.String("file", "", `dump to load, or "-" for stdin`)
+	format := flag.String("format", "", "csv, tsv or jsonl (default: from the file extension)")
+	mapSpec := flag.String("map", "", `explicit column mapping, e.g. "email=E-Mail,firstName=given"`)
+	name := flag.String("name", "", "breach name, also used as its table name (lowercase, digits, underscores)")
+	displayName := flag.String("display-name", "", "human readable breach name")
+	date := flag.String("date", "", "breach date (YYYY-MM-DD)")
+	industry := flag.String("industry", "", "industry of the breached organization")
+	sourceURL := flag.String("source-url", "", "public source for the breach")
+	region := flag.String("region", "", "north_america, europe, asia_pacific or empty")
+	passwordHash := flag.String("password-hash", "", "how the breached service stored passwords, e.g. bcrypt, md5 or plaintext")
+	records := flag.Int64("records", 0, "affected records (default: number of records loaded)")
+	reindex := flag.Bool("reindex", false, "rebuild the breach_index entries for this breach afterwards")
+	flag.Parse()
+
+	if *file == "" || *name == "" || *date == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	breachDate, err := time.Parse("2006-01-02", *date)
+	if err != nil {
+		log.Fatalf("Invalid -date %q: %v", *date, err)
+	}
+	switch *region {
+	case "", "north_america", "europe", "asia_pacific":
+	default:
+		log.Fatalf("Invalid -region %q", *region)
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+		if *format == "ndjson" {
+			*format = "jsonl"
+		}
+	}
+	mapping, err := parseMapping(*mapSpec)
+	if err != nil {
+		log.Fatalf("Invalid -map: %v", err)
+	}
+	for fieldType := range mapping {
+		if !hashing.IsKnownFieldType(fieldType) {
+			log.Fatalf("Invalid -map: unknown field type %q", fieldType)
+		}
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("Failed to initialize DATABASE_URL environment variable")
+	}
+	universalSalt := os.Getenv("UNIVERSAL_SALT")
+	if universalSalt == "" {
+		log.Fatal("UNIVERSAL_SALT environment variable is required")
+	}
+	schemes, err := hashing.ParseSchemes(os.Getenv("HASH_PEPPERS"), os.Getenv("HASH_MIN_VERSION"))
+	if err != nil {
+		log.Fatalf("Failed to configure hash schemes: %v", err)
+	}
+
+	var input io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatalf("Failed to open dump: %v", err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	read, err := newRawReader(*format, input)
+	if err != nil {
+		log.Fatalf("Failed to read dump: %v", err)
+	}
+
+	// Peek at the first record so the mapping can be guessed from its keys
+	first, err := read()
+	if errors.Is(err, io.EOF) {
+		log.Fatal("Dump contains no records")
+	}
+	if err != nil {
+		log.Fatalf("Failed to read dump: %v", err)
+	}
+	if len(mapping) == 0 {
+		columns := make([]string, 0, len(first))
+		for column := range first {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		mapping = guessMapping(columns, hashing.FieldTypes())
+	}
+	if len(mapping) == 0 {
+		log.Fatal(errNoFields)
+	}
+
+	fields := make([]string, 0, len(mapping))
+	for fieldType, column := range mapping {
+		fields = append(fields, fieldType)
+		log.Printf("Mapping %q -> %s", column, fieldType)
+	}
+	sort.Strings(fields)
+
+	next := hashedRecords(first, read, mapping, hashing.NewHasher(universalSalt), schemes)
+
+	db, err := database.InitDB(dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+	if err := database.Migrate(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	ctx := context.Background()
+	metadata := &models.BreachMetadata{
+		Name:                  *name,
+		DisplayName:           *displayName,
+		Date:                  breachDate,
+		AffectedRecords:       *records,
+		Fields:                fields,
+		SourceURL:             *sourceURL,
+		Industry:              *industry,
+		Region:                *region,
+		PasswordHashAlgorithm: strings.ToLower(*passwordHash),
+	}
+	if err := repositories.NewIngestRepository(db).LoadBreach(ctx, metadata, schemes.Latest(), next); err != nil {
+		log.Fatalf("Failed to load %s: %v", *name, err)
+	}
+	log.Printf("Loaded %s (id %d, hash scheme v%d): %d records, fields %s", metadata.Name, metadata.ID, schemes.Latest(), metadata.AffectedRecords, strings.Join(fields, ", "))
+
+	if *reindex {
+		indexed, err := repositories.NewIndexedBreachRepository(db).RebuildIndex(ctx, metadata.Name)
+		if err != nil {
+			log.Fatalf("Failed to index %s: %v", metadata.Name, err)
+		}
+		log.Printf("Indexed %s: %d hashes", metadata.Name, indexed)
+	}
+}
+
+// hashedRecords turns raw records into hashed values keyed by field type.
+// Personal fields are hashed with the latest scheme, sensitive fields stay
+// v1, and emails add the hash of their domain. Values that normalize to
+// nothing are left out rather than hashed.
+func hashedRecords(first map[string]string, read rawReader, mapping columnMapping, hasher *hashing.Hasher, schemes *hashing.Schemes) func() (map[string]string, error) {
+	pending := first
+	return func() (map[string]string, error) {
+		raw := pending
+		pending = nil
+		if raw == nil {
+			var err error
+			if raw, err = read(); err != nil {
+				return nil, err
+			}
+		}
+
+		record := make(map[string]string, len(mapping))
+		for fieldType, column := range mapping {
+			value := raw[column]
+			if hashing.Normalize(value, fieldType) == "" {
+				continue
+			}
+			hash := hasher.FullHash(value, fieldType)
+			if repositories.PersonalColumn(fieldType) != "" {
+				var err error
+				if hash, err = schemes.Derive(hash, hashing.SchemeV1, schemes.Latest()); err != nil {
+					return nil, err
+				}
+			}
+			record[fieldType] = hash
+
+			if fieldType == "email" {
+				if domain := hashing.EmailDomain(value); domain != "" {
+					domainHash, err := schemes.Derive(hasher.DomainHash(domain), hashing.SchemeV1, schemes.Latest())
+					if err != nil {
+						return nil, err
+					}
+					record[repositories.EmailDomainField] = domainHash
+				}
+			}
+		}
+		return record, nil
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const maxJSONLineBytes = 1 << 20
+
+// rawReader yields one raw record per call, keyed by source column, and
+// io.EOF at the end.
+type rawReader func() (map[string]string, error)
+
+func newRawReader(format string, r io.Reader) (rawReader, error) {
+	switch format {
+	case "csv":
+		return newDelimitedReader(r, ',')
+	case "tsv":
+		return newDelimitedReader(r, '\t')
+	case "jsonl":
+		return newJSONLinesReader(r), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q (want csv, tsv or jsonl)", format)
+	}
+}
+
+func newDelimitedReader(r io.Reader, comma rune) (rawReader, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header row: %w", err)
+	}
+	header = append([]string(nil), header...)
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\uFEFF"))
+	}
+
+	return func() (map[string]string, error) {
+		row, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		record := make(map[string]string, len(header))
+		for i, value := range row {
+			if i < len(header) {
+				record[header[i]] = value
+			}
+		}
+		return record, nil
+	}, nil
+}
+
+func newJSONLinesReader(r io.Reader) rawReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLineBytes)
+
+	line := 0
+	return func() (map[string]string, error) {
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+
+			var raw map[string]interface{}
+			if err := json.Unmarshal([]byte(text), &raw); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			record := make(map[string]string, len(raw))
+			for key, value := range raw {
+				switch v := value.(type) {
+				case nil:
+				case string:
+					record[key] = v
+				default:
+					record[key] = fmt.Sprint(v)
+				}
+			}
+			return record, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+}
+
+// columnMapping maps field types to source columns.
+type columnMapping map[string]string
+
+// parseMapping parses "fieldType=Source Column,..." as given on the command
+// line.
+func parseMapping(spec string) (columnMapping, error) {
+	mapping := make(columnMapping)
+	if strings.TrimSpace(spec) == "" {
+		return mapping, nil
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		fieldType, column, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(fieldType) == "" || strings.TrimSpace(column) == "" {
+			return nil, fmt.Errorf("invalid mapping %q (want fieldType=column)", pair)
+		}
+		mapping[strings.TrimSpace(fieldType)] = strings.TrimSpace(column)
+	}
+	return mapping, nil
+}
+
+// guessMapping matches source columns to field types by name, ignoring case
+// and punctuation, so "First Name", "first_name" and "firstName" all map to
+// firstName.
+func guessMapping(columns []string, fieldTypes []string) columnMapping {
+	byKey := make(map[string]string)
+	for _, fieldType := range fieldTypes {
+		byKey[matchKey(fieldType)] = fieldType
+	}
+	// A few common aliases
+	for alias, fieldType := range map[string]string{
+		"mail": "email", "emailaddress": "email", "pass": "password", "passwd": "password",
+		"phonenumber": "phone", "mobile": "phone", "zip": "zipCode", "postcode": "zipCode",
+		"dob": "dateOfBirth", "birthdate": "dateOfBirth", "user": "username", "login": "username",
+		"cc": "creditCard", "cardnumber": "creditCard", "fname": "firstName", "lname": "lastName",
+	} {
+		if _, taken := byKey[alias]; !taken {
+			byKey[alias] = fieldType
+		}
+	}
+
+	mapping := make(columnMapping)
+	for _, column := range columns {
+		fieldType, ok := byKey[matchKey(column)]
+		if !ok {
+			continue
+		}
+		if _, dup := mapping[fieldType]; !dup {
+			mapping[fieldType] = column
+		}
+	}
+	return mapping
+}
+
+func matchKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+var errNoFields = errors.New("no source columns map to a known field type; use -map")
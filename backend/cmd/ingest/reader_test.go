@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
+)
+
+func TestGuessMapping(t *testing.T) {
+	columns := []string{"E-Mail", "First Name", "last_name", "Passwd", "zip", "notes"}
+
+	got := guessMapping(columns, hashing.FieldTypes())
+	want := columnMapping{
+		"email":     "E-Mail",
+		"firstName": "First Name",
+		"lastName":  "last_name",
+		"password":  "Passwd",
+		"zipCode":   "zip",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("guessMapping() = %v, want %v", got, want)
+	}
+}
+
+func TestParseMapping(t *testing.T) {
+	got, err := parseMapping("email = Mail Address, ssn=SSN")
+	if err != nil {
+		t.Fatalf("parseMapping() error = %v", err)
+	}
+	want := columnMapping{"email": "Mail Address", "ssn": "SSN"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseMapping() = %v, want %v", got, want)
+	}
+
+	if _, err := parseMapping("email"); err == nil {
+		t.Error("parseMapping() expected error for missing column")
+	}
+}
+
+func TestRawReaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		want   []map[string]string
+	}{
+		{
+			name:   "csv with BOM and quoted values",
+			format: "csv",
+			input:  "\uFEFFemail,name\nalice@example.com,\"Smith, Alice\"\nbob@example.com\n",
+			want: []map[string]string{
+				{"email": "alice@example.com", "name": "Smith, Alice"},
+				{"email": "bob@example.com"},
+			},
+		},
+		{
+			name:   "tsv",
+			format: "tsv",
+			input:  "email\tphone\nalice@example.com\t555-0100\n",
+			want:   []map[string]string{{"email": "alice@example.com", "phone": "555-0100"}},
+		},
+		{
+			name:   "jsonl skips nulls and blank lines",
+			format: "jsonl",
+			input:  "{\"email\":\"alice@example.com\",\"zip\":12345,\"phone\":null}\n\n{\"email\":\"bob@example.com\"}\n",
+			want: []map[string]string{
+				{"email": "alice@example.com", "zip": "12345"},
+				{"email": "bob@example.com"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			read, err := newRawReader(tt.format, strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("newRawReader() error = %v", err)
+			}
+
+			var got []map[string]string
+			for {
+				record, err := read()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("read() error = %v", err)
+				}
+				got = append(got, record)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,18 +3,25 @@ package main
 import (
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 
 	"github.com/joho/godotenv"
 
 	"github.com/Rikjimue/breach-radar/backend/pkg/api"
 	"github.com/Rikjimue/breach-radar/backend/pkg/database"
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
+	"github.com/Rikjimue/breach-radar/backend/pkg/notify"
+	"github.com/Rikjimue/breach-radar/backend/pkg/scoring"
 )
 
 func main() {
-	// Setup logging
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	// Setup logging. Lines from the log package go through slog as well.
+	slog.SetDefault(slog.New(loadLogHandler(os.Getenv("LOG_FORMAT"))))
 
 	log.Println("Starting API service...")
 
@@ -36,15 +43,47 @@ func main() {
 	if universalSalt == "" {
 		log.Fatal("UNIVERSAL_SALT environment variable is required")
 	}
+	hashSchemes, err := hashing.ParseSchemes(os.Getenv("HASH_PEPPERS"), os.Getenv("HASH_MIN_VERSION"))
+	if err != nil {
+		log.Fatalf("Failed to configure hash schemes: %v", err)
+	}
+	partialHashLength := 0
+	if value := os.Getenv("SENSITIVE_PREFIX_LENGTH"); value != "" {
+		if partialHashLength, err = strconv.Atoi(value); err != nil {
+			log.Fatalf("Invalid SENSITIVE_PREFIX_LENGTH %q", value)
+		}
+	}
+
+	scorer, err := loadScorer(os.Getenv("SCORING_WEIGHTS"))
+	if err != nil {
+		log.Fatalf("Failed to load scoring weights: %v", err)
+	}
+
+	notifier, err := loadNotifier()
+	if err != nil {
+		log.Fatalf("Failed to configure email: %v", err)
+	}
 
 	// Initialize database
 	db, err := database.InitDB(dbURL)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
+	if err := database.Migrate(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
 
 	// Create routing
-	router := api.NewRouter(db)
+	router := api.NewRouter(db, api.Config{
+		RateLimitStore:    os.Getenv("RATE_LIMIT_STORE"),
+		SearchStrategy:    os.Getenv("BREACH_SEARCH_STRATEGY"),
+		HashSchemes:       hashSchemes,
+		PartialHashLength: partialHashLength,
+		Scorer:            scorer,
+		Notifier:          notifier,
+		AppURL:            os.Getenv("APP_URL"),
+		UniversalSalt:     universalSalt,
+	})
 
 	s := &http.Server{
 		Addr:    address,
@@ -52,3 +91,81 @@ func main() {
 	}
 	log.Fatal(s.ListenAndServe())
 }
+
+// loadLogHandler writes JSON lines to stderr, or human-readable text when
+// format is "text".
+func loadLogHandler(format string) slog.Handler {
+	if format == "text" {
+		return slog.NewTextHandler(os.Stderr, nil)
+	}
+	return slog.NewJSONHandler(os.Stderr, nil)
+}
+
+// loadScorer scores with the weights file at path, or the defaults when path
+// is empty. The file is read again on SIGHUP so analysts can tune weights
+// without a restart; a broken file keeps the previous weights.
+func loadScorer(path string) (*scoring.Scorer, error) {
+	if path == "" {
+		return scoring.NewScorer(nil), nil
+	}
+	weights, err := scoring.LoadWeights(path)
+	if err != nil {
+		return nil, err
+	}
+	scorer := scoring.NewScorer(weights)
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			weights, err := scoring.LoadWeights(path)
+			if err != nil {
+				log.Printf("Keeping previous scoring weights -> %v", err)
+				continue
+			}
+			scorer.SetWeights(weights)
+			log.Printf("Reloaded scoring weights from %s", path)
+		}
+	}()
+
+	return scorer, nil
+}
+
+// loadNotifier picks the email driver from MAIL_DRIVER: "smtp" sends through
+// SMTP_HOST, "file" writes .eml files to MAIL_DIR, and "log", the default,
+// only logs messages.
+func loadNotifier() (notify.Notifier, error) {
+	from := os.Getenv("MAIL_FROM")
+
+	switch driver := os.Getenv("MAIL_DRIVER"); driver {
+	case "", "log":
+		return notify.NewLogNotifier(nil), nil
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+		return notify.NewFileNotifier(dir, from)
+	case "smtp":
+		host := os.Getenv("SMTP_HOST")
+		if host == "" || from == "" {
+			return nil, fmt.Errorf("SMTP_HOST and MAIL_FROM are required for the smtp driver")
+		}
+		port := 587
+		if value := os.Getenv("SMTP_PORT"); value != "" {
+			var err error
+			if port, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("invalid SMTP_PORT %q", value)
+			}
+		}
+		return notify.NewSMTPNotifier(notify.SMTPConfig{
+			Host:     host,
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", driver)
+	}
+}
@@ -0,0 +1,107 @@
+// Command rehash moves stored personal hashes to a newer hash scheme (see
+// pkg/hashing) without the raw dumps. To rotate the pepper, append a new
+// version to HASH_PEPPERS, deploy, run rehash, then raise HASH_MIN_VERSION
+// once every breach has been moved so the old versions are no longer
+// searched. Breach tables loaded before hash versions are given their
+// hash_version and id columns first, which rewrites them one at a time.
+// The breach_index entries of every rehashed breach are rebuilt unless
+// -reindex=false is passed, e.g. when BREACH_SEARCH_STRATEGY is not index;
+// searches with the index strategy then report those breaches as unchecked
+// until they are reindexed.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/database"
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+)
+
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	breachName := flag.String("breach", "", "rehash only this breach (default: every breach)")
+	to := flag.Int("to", 0, "target hash scheme version (default: the latest configured)")
+	batchSize := flag.Int("batch", 1000, "rows rehashed per transaction")
+	reindex := flag.Bool("reindex", true, "rebuild the breach_index entries of every rehashed breach")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("Failed to initialize DATABASE_URL environment variable")
+	}
+	schemes, err := hashing.ParseSchemes(os.Getenv("HASH_PEPPERS"), os.Getenv("HASH_MIN_VERSION"))
+	if err != nil {
+		log.Fatalf("Failed to configure hash schemes: %v", err)
+	}
+	if *to == 0 {
+		*to = schemes.Latest()
+	}
+	if !schemes.IsKnown(*to) {
+		log.Fatalf("Hash scheme v%d is not configured in HASH_PEPPERS", *to)
+	}
+	if *batchSize <= 0 {
+		log.Fatal("-batch must be positive")
+	}
+
+	db, err := database.InitDB(dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+	if err := database.Migrate(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	ctx := context.Background()
+	ingestRepo := repositories.NewIngestRepository(db)
+	indexRepo := repositories.NewIndexedBreachRepository(db)
+
+	names := []string{*breachName}
+	if *breachName == "" {
+		if names, err = indexRepo.ListBreachNames(ctx); err != nil {
+			log.Fatalf("Failed to list breaches: %v", err)
+		}
+	}
+
+	failed := 0
+	for _, name := range names {
+		if err := ingestRepo.PrepareBreachTable(ctx, name); err != nil {
+			log.Printf("Failed to prepare %s: %v", name, err)
+			failed++
+			continue
+		}
+
+		rehashed, err := ingestRepo.RehashBreach(ctx, name, *to, *batchSize, schemes.Derive)
+		if err != nil {
+			log.Printf("Failed to rehash %s (%d rows done): %v", name, rehashed, err)
+			failed++
+			continue
+		}
+		log.Printf("Rehashed %s to v%d: %d rows", name, *to, rehashed)
+
+		if *reindex {
+			indexed, err := indexRepo.RebuildIndex(ctx, name)
+			if err != nil {
+				log.Printf("Failed to index %s: %v", name, err)
+				failed++
+				continue
+			}
+			log.Printf("Indexed %s: %d hashes", name, indexed)
+		}
+	}
+
+	if failed > 0 {
+		log.Fatalf("%d of %d breaches failed", failed, len(names))
+	}
+}
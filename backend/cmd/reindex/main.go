@@ -0,0 +1,66 @@
+// Command reindex builds the global breach_index table from the per-breach
+// tables. Run it after enabling BREACH_SEARCH_STRATEGY=index, and again
+// whenever a breach table is loaded or changed.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/database"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+)
+
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	breachName := flag.String("breach", "", "rebuild only this breach (default: every breach)")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("Failed to initialize DATABASE_URL environment variable")
+	}
+
+	db, err := database.InitDB(dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+	if err := database.Migrate(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	ctx := context.Background()
+	repo := repositories.NewIndexedBreachRepository(db)
+
+	names := []string{*breachName}
+	if *breachName == "" {
+		if names, err = repo.ListBreachNames(ctx); err != nil {
+			log.Fatalf("Failed to list breaches: %v", err)
+		}
+	}
+
+	failed := 0
+	for _, name := range names {
+		indexed, err := repo.RebuildIndex(ctx, name)
+		if err != nil {
+			log.Printf("Failed to index %s: %v", name, err)
+			failed++
+			continue
+		}
+		log.Printf("Indexed %s: %d hashes", name, indexed)
+	}
+
+	if failed > 0 {
+		log.Fatalf("%d of %d breaches failed to index", failed, len(names))
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/services"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+	authz         *services.Authorizer
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService, authz *services.Authorizer) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService, authz: authz}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := authorize(w, r, h.authz, models.PermAPIKeysManage)
+	if !ok {
+		return
+	}
+
+	var req models.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	resp, err := h.apiKeyService.CreateAPIKey(r.Context(), user, &req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, resp)
+}
+
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := authorize(w, r, h.authz, models.PermAPIKeysManage)
+	if !ok {
+		return
+	}
+
+	keys, err := h.apiKeyService.ListAPIKeys(r.Context(), user)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) UpdateAPIKey(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := authorize(w, r, h.authz, models.PermAPIKeysManage)
+	if !ok {
+		return
+	}
+	keyID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	key, err := h.apiKeyService.UpdateAPIKey(r.Context(), user, keyID, &req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, key)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := authorize(w, r, h.authz, models.PermAPIKeysManage)
+	if !ok {
+		return
+	}
+	keyID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(r.Context(), user, keyID); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// APIKeyFromRequest extracts an API key from "Authorization: ApiKey <key>"
+// or the X-API-Key header, or returns "" when there is none.
+func APIKeyFromRequest(r *http.Request) string {
+	scheme, key, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if found && strings.EqualFold(scheme, "ApiKey") {
+		return strings.TrimSpace(key)
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+func requireUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	user, ok := services.UserFromContext(r.Context())
+	if !ok {
+		WriteError(w, r, utils.NewAppError(http.StatusUnauthorized, "Authentication required"))
+	}
+	return user, ok
+}
+
+// authorize asks authz whether the caller may do permission and writes the
+// error response when not.
+func authorize(w http.ResponseWriter, r *http.Request, authz *services.Authorizer, permission string) (*models.User, *models.Membership, bool) {
+	user, membership, err := authz.Authorize(r.Context(), permission)
+	if err != nil {
+		WriteError(w, r, err)
+		return nil, nil, false
+	}
+	return user, membership, true
+}
+
+func pathID(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid id"))
+		return 0, false
+	}
+	return id, true
+}
@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"encoding/json"
+	"net"
 	"net/http"
+	"strings"
 
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
 	"github.com/Rikjimue/breach-radar/backend/pkg/services"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
 )
 
 type AuthHandler struct {
@@ -15,9 +20,71 @@ func NewAuthHandler(authService *services.AuthService) *AuthHandler {
 }
 
 func (h *AuthHandler) Signup(w http.ResponseWriter, r *http.Request) {
+	var req models.SignupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
 
+	resp, err := h.authService.Signup(r.Context(), &req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, resp)
 }
 
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req models.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	resp, err := h.authService.Login(r.Context(), &req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := h.authService.Logout(r.Context(), BearerToken(r)); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, user)
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>"
+// header, or returns "" when there is none.
+func BearerToken(r *http.Request) string {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
 
+// ClientIP returns the address of the directly connected peer. Forwarding
+// headers are ignored because they are trivially spoofed.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
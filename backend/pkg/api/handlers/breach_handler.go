@@ -3,41 +3,77 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
 	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
 	"github.com/Rikjimue/breach-radar/backend/pkg/services"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
 )
 
+const maxBulkBodyBytes = 64 << 20
+
 type BreachHandler struct {
-	breachService *services.BreachService
+	breachService     *services.BreachService
+	partialHashLength int
+	authz             *services.Authorizer
+	rateLimit         *services.RateLimitService
+}
+
+// NewBreachHandler accepts sensitive searches whose partial hashes are
+// exactly partialHashLength hex characters long. Bulk searches charge every
+// identity to rateLimit.
+func NewBreachHandler(breachService *services.BreachService, partialHashLength int, authz *services.Authorizer, rateLimit *services.RateLimitService) *BreachHandler {
+	return &BreachHandler{breachService: breachService, partialHashLength: partialHashLength, authz: authz, rateLimit: rateLimit}
 }
 
-func NewBreachHandler(breachService *services.BreachService) *BreachHandler {
-	return &BreachHandler{breachService: breachService}
+// validateFields rejects field types the mode cannot search, which would
+// otherwise be reported as unchecked sources, and sensitive searches whose
+// prefixes do not match the configured k-anonymity length; a wrong length
+// would otherwise silently match nothing.
+func (h *BreachHandler) validateFields(mode string, fields map[string]string) error {
+	for fieldType, hash := range fields {
+		if mode == "personal" {
+			if repositories.PersonalColumn(fieldType) == "" {
+				return utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("%s cannot be searched in personal mode", fieldType))
+			}
+			continue
+		}
+
+		if repositories.SensitiveTable(fieldType) == "" {
+			return utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("%s cannot be searched in sensitive mode", fieldType))
+		}
+		if err := hashing.ValidatePartialHash(hash, h.partialHashLength); err != nil {
+			return utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid partialHash for %s: %v", fieldType, err))
+		}
+	}
+	return nil
 }
 
 func (h *BreachHandler) BreachSearch(w http.ResponseWriter, r *http.Request) {
-	fmt.Println("Recived breach request")
 	var req models.BreachSearchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Printf("Invalid request body -> %v", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
 		return
 	}
 
 	if req.Mode == "" || len(req.Fields) == 0 {
-		log.Printf("Missing required fields: mode=%s, fields_count=%d", req.Mode, len(req.Fields))
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Missing required fields"))
 		return
 	}
 
 	if req.Mode != "personal" && req.Mode != "sensitive" {
-		log.Printf("Invalid mode: %s", req.Mode)
-		http.Error(w, "Invalid mode", http.StatusBadRequest)
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid mode"))
+		return
+	}
+
+	if err := h.validateFields(req.Mode, req.Fields); err != nil {
+		WriteError(w, r, err)
 		return
 	}
 
@@ -46,11 +82,124 @@ func (h *BreachHandler) BreachSearch(w http.ResponseWriter, r *http.Request) {
 
 	matches, err := h.breachService.BreachSearch(ctx, &req)
 	if err != nil {
-		log.Printf("Internal server error -> %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		WriteError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(matches)
 }
+
+// BulkBreachSearch searches many identities in one call and streams one NDJSON
+// line per identity as each search completes. The search mode is taken from
+// the "mode" query parameter and defaults to personal; "strict" and
+// "minOccurrences" apply to every identity as they do in BreachSearch, so a
+// strict search fails only the identities that could not be fully checked.
+// Every identity counts
+// against the daily quota; once it runs out the stream ends with an error.
+func (h *BreachHandler) BulkBreachSearch(w http.ResponseWriter, r *http.Request) {
+	if _, _, ok := authorize(w, r, h.authz, models.PermBulkSearch); !ok {
+		return
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = "personal"
+	}
+	if mode != "personal" && mode != "sensitive" {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid mode"))
+		return
+	}
+	base := models.BreachSearchRequest{Mode: mode}
+	if value := r.URL.Query().Get("strict"); value != "" {
+		strict, err := strconv.ParseBool(value)
+		if err != nil {
+			WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "strict must be true or false"))
+			return
+		}
+		base.Strict = strict
+	}
+	if value := r.URL.Query().Get("minOccurrences"); value != "" {
+		minOccurrences, err := strconv.Atoi(value)
+		if err != nil || minOccurrences < 0 {
+			WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "minOccurrences must be a non-negative integer"))
+			return
+		}
+		base.MinOccurrences = minOccurrences
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBulkBodyBytes)
+	read, err := newBulkItemReader(r)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	clientIP := ClientIP(r)
+	maxIdentities := h.rateLimit.Limits(r.Context(), clientIP).MaxBulkIdentities
+	count := 0
+	next := func() (*models.BulkSearchItem, error) {
+		item, err := read()
+		if err != nil {
+			return nil, err
+		}
+		count++
+		if count > maxIdentities {
+			return nil, utils.NewAppError(http.StatusRequestEntityTooLarge, fmt.Sprintf("Your plan allows at most %d identities per bulk search", maxIdentities))
+		}
+		// The request itself was metered as the first identity
+		if count > 1 {
+			quota, err := h.rateLimit.ChargeQuota(r.Context(), clientIP)
+			if err != nil {
+				return nil, err
+			}
+			if !quota.Allowed {
+				return nil, utils.NewAppError(http.StatusTooManyRequests, fmt.Sprintf("Daily quota exhausted after %d identities", count-1))
+			}
+		}
+		return item, nil
+	}
+	// An identity with fields the mode cannot search fails on its own line
+	validate := func(fields map[string]string) error {
+		return h.validateFields(mode, fields)
+	}
+
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	started := false
+
+	err = h.breachService.BulkSearch(r.Context(), base, validate, next, func(result *models.BulkSearchResult) error {
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+		}
+		return
+	}
+
+	if !started {
+		WriteError(w, r, err)
+		return
+	}
+
+	// The status line is already sent, so report the failure in-band
+	services.RequestLogger(r.Context()).ErrorContext(r.Context(), "Bulk search aborted", "error", err)
+	message := "Bulk search aborted"
+	var appErr *utils.AppError
+	if errors.As(err, &appErr) {
+		message = appErr.Message
+	}
+	enc.Encode(errorResponse{Error: message})
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+const maxNDJSONLineBytes = 1 << 20
+
+// bulkItemReader yields one identity per call and io.EOF at the end.
+type bulkItemReader func() (*models.BulkSearchItem, error)
+
+// newBulkItemReader picks a streaming parser for the request body. A
+// multipart upload is read from its "file" part.
+func newBulkItemReader(r *http.Request) (bulkItemReader, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if mediaType == "multipart/form-data" {
+		mr, err := r.MultipartReader()
+		if err != nil {
+			return nil, utils.NewAppError(http.StatusBadRequest, "Invalid multipart body")
+		}
+		for {
+			part, err := mr.NextPart()
+			if errors.Is(err, io.EOF) {
+				return nil, utils.NewAppError(http.StatusBadRequest, `Missing "file" part`)
+			}
+			if err != nil {
+				return nil, utils.NewAppError(http.StatusBadRequest, "Invalid multipart body")
+			}
+			if part.FormName() != "file" {
+				continue
+			}
+
+			format, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			switch strings.ToLower(path.Ext(part.FileName())) {
+			case ".csv":
+				format = "text/csv"
+			case ".ndjson", ".jsonl":
+				format = "application/x-ndjson"
+			case ".json":
+				format = "application/json"
+			}
+			return readerForFormat(format, part)
+		}
+	}
+
+	return readerForFormat(mediaType, r.Body)
+}
+
+func readerForFormat(format string, body io.Reader) (bulkItemReader, error) {
+	switch format {
+	case "application/json", "":
+		return newJSONArrayReader(body)
+	case "application/x-ndjson", "application/jsonl":
+		return newNDJSONReader(body), nil
+	case "text/csv":
+		return newCSVReader(body)
+	default:
+		return nil, utils.NewAppError(http.StatusUnsupportedMediaType, fmt.Sprintf("Unsupported content type: %s", format))
+	}
+}
+
+// newJSONArrayReader decodes a JSON array of identities one element at a time.
+func newJSONArrayReader(body io.Reader) (bulkItemReader, error) {
+	dec := json.NewDecoder(body)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, utils.NewAppError(http.StatusBadRequest, "Request body must be a JSON array of identities")
+	}
+
+	n := 0
+	return func() (*models.BulkSearchItem, error) {
+		if !dec.More() {
+			return nil, io.EOF
+		}
+		n++
+		var item models.BulkSearchItem
+		if err := dec.Decode(&item); err != nil {
+			return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid identity at index %d", n-1))
+		}
+		defaultItemID(&item, n)
+		return &item, nil
+	}, nil
+}
+
+// newNDJSONReader decodes one identity per non-blank line.
+func newNDJSONReader(body io.Reader) bulkItemReader {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLineBytes)
+
+	n := 0
+	return func() (*models.BulkSearchItem, error) {
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			n++
+			var item models.BulkSearchItem
+			if err := json.Unmarshal([]byte(line), &item); err != nil {
+				return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid identity on record %d", n))
+			}
+			defaultItemID(&item, n)
+			return &item, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, utils.NewAppError(http.StatusBadRequest, "Invalid NDJSON body")
+		}
+		return nil, io.EOF
+	}
+}
+
+// newCSVReader reads a header row naming the field types (plus an optional
+// "id" column) followed by one identity of pre-hashed values per row.
+func newCSVReader(body io.Reader) (bulkItemReader, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, utils.NewAppError(http.StatusBadRequest, "CSV body must start with a header row")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	n := 0
+	return func() (*models.BulkSearchItem, error) {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		n++
+		if err != nil {
+			return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid CSV on record %d", n))
+		}
+
+		item := models.BulkSearchItem{Fields: make(map[string]string)}
+		for i, value := range record {
+			if i >= len(header) || value == "" {
+				continue
+			}
+			if header[i] == "id" {
+				item.ID = value
+				continue
+			}
+			item.Fields[header[i]] = value
+		}
+		defaultItemID(&item, n)
+		return &item, nil
+	}, nil
+}
+
+// defaultItemID numbers identities that were submitted without an ID so
+// every result can still be matched to its input.
+func defaultItemID(item *models.BulkSearchItem, n int) {
+	if item.ID == "" {
+		item.ID = strconv.Itoa(n)
+	}
+}
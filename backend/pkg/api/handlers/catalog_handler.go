@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/services"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+type CatalogHandler struct {
+	catalogService *services.CatalogService
+}
+
+func NewCatalogHandler(catalogService *services.CatalogService) *CatalogHandler {
+	return &CatalogHandler{catalogService: catalogService}
+}
+
+func (h *CatalogHandler) ListBreaches(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	q := services.BreachListQuery{
+		Industry:  params.Get("industry"),
+		FieldType: params.Get("field"),
+		From:      params.Get("from"),
+		To:        params.Get("to"),
+		Sort:      params.Get("sort"),
+		Order:     params.Get("order"),
+	}
+
+	var err error
+	if q.Page, err = queryInt(params.Get("page")); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "page must be a number"))
+		return
+	}
+	if q.PageSize, err = queryInt(params.Get("pageSize")); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "pageSize must be a number"))
+		return
+	}
+
+	resp, err := h.catalogService.ListBreaches(r.Context(), &q)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *CatalogHandler) GetBreach(w http.ResponseWriter, r *http.Request) {
+	breach, err := h.catalogService.GetBreach(r.Context(), r.PathValue("name"))
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, breach)
+}
+
+func (h *CatalogHandler) ListFields(w http.ResponseWriter, r *http.Request) {
+	fields, err := h.catalogService.ListFields(r.Context())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, fields)
+}
+
+// queryInt parses an optional integer query parameter; "" yields 0.
+func queryInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/services"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+type DomainHandler struct {
+	domainService *services.DomainService
+	authz         *services.Authorizer
+}
+
+func NewDomainHandler(domainService *services.DomainService, authz *services.Authorizer) *DomainHandler {
+	return &DomainHandler{domainService: domainService, authz: authz}
+}
+
+func (h *DomainHandler) CreateClaim(w http.ResponseWriter, r *http.Request) {
+	_, member, ok := authorize(w, r, h.authz, models.PermDomainsManage)
+	if !ok {
+		return
+	}
+
+	var req models.CreateDomainClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	claim, err := h.domainService.CreateClaim(r.Context(), member, &req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, claim)
+}
+
+func (h *DomainHandler) ListClaims(w http.ResponseWriter, r *http.Request) {
+	_, member, ok := authorize(w, r, h.authz, models.PermDomainsRead)
+	if !ok {
+		return
+	}
+
+	claims, err := h.domainService.ListClaims(r.Context(), member)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, claims)
+}
+
+func (h *DomainHandler) DeleteClaim(w http.ResponseWriter, r *http.Request) {
+	_, member, ok := authorize(w, r, h.authz, models.PermDomainsManage)
+	if !ok {
+		return
+	}
+	claimID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.domainService.DeleteClaim(r.Context(), member, claimID); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyClaim checks the claim's token with the method in the body, "dns"
+// or "file".
+func (h *DomainHandler) VerifyClaim(w http.ResponseWriter, r *http.Request) {
+	_, member, ok := authorize(w, r, h.authz, models.PermDomainsManage)
+	if !ok {
+		return
+	}
+	claimID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.VerifyDomainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	claim, err := h.domainService.VerifyClaim(r.Context(), member, claimID, &req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, claim)
+}
+
+// SearchDomain lists the breached accounts of a verified domain. A POST
+// body of v1 email hashes limits it to those accounts.
+func (h *DomainHandler) SearchDomain(w http.ResponseWriter, r *http.Request) {
+	_, member, ok := authorize(w, r, h.authz, models.PermDomainsRead)
+	if !ok {
+		return
+	}
+	claimID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req *models.DomainSearchRequest
+	if r.Method == http.MethodPost {
+		req = &models.DomainSearchRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
+			return
+		}
+	}
+
+	results, err := h.domainService.Search(r.Context(), member, claimID, req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, results)
+}
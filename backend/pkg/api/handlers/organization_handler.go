@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/services"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+type OrganizationHandler struct {
+	orgService *services.OrganizationService
+	authz      *services.Authorizer
+}
+
+func NewOrganizationHandler(orgService *services.OrganizationService, authz *services.Authorizer) *OrganizationHandler {
+	return &OrganizationHandler{orgService: orgService, authz: authz}
+}
+
+func (h *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	membership, err := h.orgService.CreateOrganization(r.Context(), user, &req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, membership)
+}
+
+// GetOrganization serves the caller's organization and their role in it.
+func (h *OrganizationHandler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	membership, err := h.orgService.GetOrganization(r.Context(), user)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, membership)
+}
+
+func (h *OrganizationHandler) UpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	_, member, ok := authorize(w, r, h.authz, models.PermOrgManage)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	membership, err := h.orgService.UpdateOrganization(r.Context(), member, &req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, membership)
+}
+
+func (h *OrganizationHandler) DeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	_, member, ok := authorize(w, r, h.authz, models.PermOrgManage)
+	if !ok {
+		return
+	}
+
+	if err := h.orgService.DeleteOrganization(r.Context(), member); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) LeaveOrganization(w http.ResponseWriter, r *http.Request) {
+	user, member, ok := authorize(w, r, h.authz, models.PermMembersRead)
+	if !ok {
+		return
+	}
+
+	if err := h.orgService.LeaveOrganization(r.Context(), user, member); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	_, member, ok := authorize(w, r, h.authz, models.PermMembersRead)
+	if !ok {
+		return
+	}
+
+	members, err := h.orgService.ListMembers(r.Context(), member)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, members)
+}
+
+func (h *OrganizationHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	_, member, ok := authorize(w, r, h.authz, models.PermMembersManage)
+	if !ok {
+		return
+	}
+	userID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	updated, err := h.orgService.UpdateMemberRole(r.Context(), member, userID, &req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, updated)
+}
+
+func (h *OrganizationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	_, member, ok := authorize(w, r, h.authz, models.PermMembersManage)
+	if !ok {
+		return
+	}
+	userID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.orgService.RemoveMember(r.Context(), member, userID); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	user, member, ok := authorize(w, r, h.authz, models.PermMembersManage)
+	if !ok {
+		return
+	}
+
+	var req models.CreateInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	invitation, err := h.orgService.CreateInvitation(r.Context(), user, member, &req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, invitation)
+}
+
+func (h *OrganizationHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	_, member, ok := authorize(w, r, h.authz, models.PermMembersManage)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.orgService.ListInvitations(r.Context(), member)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, invitations)
+}
+
+func (h *OrganizationHandler) DeleteInvitation(w http.ResponseWriter, r *http.Request) {
+	_, member, ok := authorize(w, r, h.authz, models.PermMembersManage)
+	if !ok {
+		return
+	}
+	invitationID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.orgService.DeleteInvitation(r.Context(), member, invitationID); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.AcceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	membership, err := h.orgService.AcceptInvitation(r.Context(), user, &req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, membership)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/services"
+)
+
+type RangeHandler struct {
+	rangeService *services.RangeService
+}
+
+func NewRangeHandler(rangeService *services.RangeService) *RangeHandler {
+	return &RangeHandler{rangeService: rangeService}
+}
+
+// GetRange serves GET /api/v0/range/{fieldType}/{prefix}. The body is one
+// "suffix:count" line per hash, or JSON when asked for with the Accept header
+// or ?format=json. "Add-Padding: true" mixes in random count-0 suffixes.
+func (h *RangeHandler) GetRange(w http.ResponseWriter, r *http.Request) {
+	hashRange, err := h.rangeService.GetRange(r.Context(), r.PathValue("fieldType"), r.PathValue("prefix"))
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	asJSON := r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json")
+
+	// Padded bodies differ on every request but carry the same data, hence a
+	// weak validator computed before padding.
+	etag := rangeETag(hashRange, asJSON)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("Vary", "Accept, Add-Padding")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if strings.EqualFold(r.Header.Get("Add-Padding"), "true") {
+		if hashRange, err = services.PadRange(hashRange); err != nil {
+			WriteError(w, r, err)
+			return
+		}
+	}
+
+	if asJSON {
+		WriteJSON(w, http.StatusOK, hashRange)
+		return
+	}
+
+	var b strings.Builder
+	for _, suffix := range hashRange.Suffixes {
+		b.WriteString(suffix.Suffix)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(suffix.Count))
+		b.WriteString("\r\n")
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(b.String()))
+}
+
+func rangeETag(hashRange *models.HashRange, asJSON bool) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s/%s/%t\n", hashRange.FieldType, hashRange.Prefix, asJSON)
+	for _, suffix := range hashRange.Suffixes {
+		fmt.Fprintf(h, "%s:%d\n", suffix.Suffix, suffix.Count)
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches implements the weak comparison If-None-Match calls for.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/services"
+)
+
+type RemediationHandler struct {
+	remediationService *services.RemediationService
+}
+
+func NewRemediationHandler(remediationService *services.RemediationService) *RemediationHandler {
+	return &RemediationHandler{remediationService: remediationService}
+}
+
+// ListRemediation serves the knowledge base. field can be repeated or
+// comma separated; breach adds the advice specific to that breach.
+func (h *RemediationHandler) ListRemediation(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	var fieldTypes []string
+	for _, value := range params["field"] {
+		for _, fieldType := range strings.Split(value, ",") {
+			if fieldType = strings.TrimSpace(fieldType); fieldType != "" {
+				fieldTypes = append(fieldTypes, fieldType)
+			}
+		}
+	}
+
+	resp, err := h.remediationService.ListRemediation(r.Context(), fieldTypes, params.Get("breach"))
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, resp)
+}
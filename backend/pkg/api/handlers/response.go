@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/services"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+type errorResponse struct {
+	Error     string   `json:"error"`
+	Unchecked []string `json:"unchecked,omitempty"`
+}
+
+func WriteJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// WriteError reports an AppError with its own status and message; anything
+// else is logged under r's request ID and hidden behind a generic 500.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	ctx := r.Context()
+	logger := services.RequestLogger(ctx)
+
+	var partialErr *utils.PartialResultError
+	if errors.As(err, &partialErr) {
+		logger.WarnContext(ctx, "Partial result", "error", err)
+		WriteJSON(w, partialErr.Code, errorResponse{Error: partialErr.Message, Unchecked: partialErr.Unchecked})
+		return
+	}
+
+	var appErr *utils.AppError
+	if errors.As(err, &appErr) {
+		if appErr.Err != nil {
+			logger.ErrorContext(ctx, "Request failed", "error", err)
+		}
+		WriteJSON(w, appErr.Code, errorResponse{Error: appErr.Message})
+		return
+	}
+
+	logger.ErrorContext(ctx, "Internal server error", "error", err)
+	WriteJSON(w, http.StatusInternalServerError, errorResponse{Error: "Internal server error"})
+}
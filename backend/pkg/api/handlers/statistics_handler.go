@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/services"
+)
+
+type StatisticsHandler struct {
+	statisticsService *services.StatisticsService
+}
+
+func NewStatisticsHandler(statisticsService *services.StatisticsService) *StatisticsHandler {
+	return &StatisticsHandler{statisticsService: statisticsService}
+}
+
+func (h *StatisticsHandler) GetStatistics(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.statisticsService.GetStatistics(r.Context())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	WriteJSON(w, http.StatusOK, stats)
+}
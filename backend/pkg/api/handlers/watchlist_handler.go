@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/services"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+type WatchlistHandler struct {
+	watchlistService *services.WatchlistService
+	authz            *services.Authorizer
+}
+
+func NewWatchlistHandler(watchlistService *services.WatchlistService, authz *services.Authorizer) *WatchlistHandler {
+	return &WatchlistHandler{watchlistService: watchlistService, authz: authz}
+}
+
+func (h *WatchlistHandler) CreateEntry(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := authorize(w, r, h.authz, models.PermWatchlistWrite)
+	if !ok {
+		return
+	}
+
+	var req models.CreateWatchlistEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	entry, err := h.watchlistService.CreateEntry(r.Context(), user, &req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, entry)
+}
+
+func (h *WatchlistHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := authorize(w, r, h.authz, models.PermWatchlistRead)
+	if !ok {
+		return
+	}
+
+	entries, err := h.watchlistService.ListEntries(r.Context(), user)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, entries)
+}
+
+func (h *WatchlistHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := authorize(w, r, h.authz, models.PermWatchlistWrite)
+	if !ok {
+		return
+	}
+	entryID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.watchlistService.DeleteEntry(r.Context(), user, entryID); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListAlerts returns the caller's alerts, newest first. unacknowledged=true
+// leaves out the ones already seen.
+func (h *WatchlistHandler) ListAlerts(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := authorize(w, r, h.authz, models.PermWatchlistRead)
+	if !ok {
+		return
+	}
+
+	unacknowledgedOnly := r.URL.Query().Get("unacknowledged") == "true"
+	alerts, err := h.watchlistService.ListAlerts(r.Context(), user, unacknowledgedOnly)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, alerts)
+}
+
+func (h *WatchlistHandler) AcknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := authorize(w, r, h.authz, models.PermWatchlistWrite)
+	if !ok {
+		return
+	}
+	alertID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.watchlistService.AcknowledgeAlert(r.Context(), user, alertID); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/services"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+	authz          *services.Authorizer
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService, authz *services.Authorizer) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService, authz: authz}
+}
+
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := authorize(w, r, h.authz, models.PermWebhooksManage)
+	if !ok {
+		return
+	}
+
+	var req models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, utils.NewAppError(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	resp, err := h.webhookService.CreateWebhook(r.Context(), user, &req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, resp)
+}
+
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := authorize(w, r, h.authz, models.PermWebhooksManage)
+	if !ok {
+		return
+	}
+
+	webhooks, err := h.webhookService.ListWebhooks(r.Context(), user)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, webhooks)
+}
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := authorize(w, r, h.authz, models.PermWebhooksManage)
+	if !ok {
+		return
+	}
+	webhookID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(r.Context(), user, webhookID); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries serves the delivery log of a webhook. status=dead lists
+// the deliveries that ran out of attempts.
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := authorize(w, r, h.authz, models.PermWebhooksManage)
+	if !ok {
+		return
+	}
+	webhookID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), user, webhookID, r.URL.Query().Get("status"))
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := authorize(w, r, h.authz, models.PermWebhooksManage)
+	if !ok {
+		return
+	}
+	deliveryID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.RetryDelivery(r.Context(), user, deliveryID); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
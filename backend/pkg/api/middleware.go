@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/api/handlers"
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/services"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// Request IDs sent by clients or proxies are kept when they look like an
+// identifier; anything else could be used to forge log lines.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+type middleware func(http.Handler) http.Handler
+
+// chain wraps next in middlewares, the first one outermost.
+func chain(next http.Handler, middlewares ...middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		next = middlewares[i](next)
+	}
+	return next
+}
+
+type requestContextKey int
+
+const requestInfoKey requestContextKey = iota
+
+// requestInfo is shared by the middlewares of one request. The auth
+// middleware runs inside the access log, so it records the caller here
+// rather than in a context the access log never sees.
+type requestInfo struct {
+	userID   uint64
+	apiKeyID uint64
+}
+
+func requestInfoFromContext(ctx context.Context) *requestInfo {
+	info, _ := ctx.Value(requestInfoKey).(*requestInfo)
+	return info
+}
+
+// recordCaller notes who made the request for the access log.
+func recordCaller(ctx context.Context, user *models.User, key *models.APIKey) {
+	info := requestInfoFromContext(ctx)
+	if info == nil {
+		return
+	}
+	info.userID = user.ID
+	if key != nil {
+		info.apiKeyID = key.ID
+	}
+}
+
+// withRequestID propagates the caller's X-Request-ID, or assigns a new one,
+// and echoes it in the response.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		ctx := services.ContextWithRequestID(r.Context(), id)
+		ctx = context.WithValue(ctx, requestInfoKey, &requestInfo{})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// responseRecorder captures the status and size of a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// Flush keeps streamed responses such as bulk searches working.
+func (rec *responseRecorder) Flush() {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// accessLog writes one line per request. Only the matched route pattern is
+// logged, never the path, query or body, since those carry hashes.
+func accessLog(logger *slog.Logger) middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			route := r.Pattern
+			if route == "" {
+				route = "unmatched"
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("route", route),
+				slog.Int("status", status),
+				slog.Duration("latency", time.Since(start)),
+				slog.Int64("bytes", rec.bytes),
+				slog.String("ip", handlers.ClientIP(r)),
+			}
+			if id := services.RequestIDFromContext(r.Context()); id != "" {
+				attrs = append(attrs, slog.String("request_id", id))
+			}
+			if info := requestInfoFromContext(r.Context()); info != nil {
+				if info.userID != 0 {
+					attrs = append(attrs, slog.Uint64("user_id", info.userID))
+				}
+				if info.apiKeyID != 0 {
+					attrs = append(attrs, slog.Uint64("api_key_id", info.apiKeyID))
+				}
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			logger.LogAttrs(r.Context(), level, "request", attrs...)
+		})
+	}
+}
+
+// recoverPanic turns a panicking handler into a 500 response instead of a
+// dropped connection. The panic and its stack are logged with the request ID.
+func recoverPanic(logger *slog.Logger) middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec, ok := w.(*responseRecorder)
+			if !ok {
+				rec = &responseRecorder{ResponseWriter: w}
+			}
+
+			defer func() {
+				value := recover()
+				if value == nil {
+					return
+				}
+				if value == http.ErrAbortHandler {
+					panic(value)
+				}
+
+				attrs := []slog.Attr{
+					slog.String("panic", fmt.Sprint(value)),
+					slog.String("stack", string(debug.Stack())),
+				}
+				if id := services.RequestIDFromContext(r.Context()); id != "" {
+					attrs = append(attrs, slog.String("request_id", id))
+				}
+				logger.LogAttrs(r.Context(), slog.LevelError, "handler panicked", attrs...)
+
+				// Too late to change a response that has started
+				if rec.status != 0 {
+					return
+				}
+				handlers.WriteError(rec, r, utils.NewAppError(http.StatusInternalServerError, "Internal server error"))
+			}()
+
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
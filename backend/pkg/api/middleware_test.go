@@ -0,0 +1,156 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/api/handlers"
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+)
+
+func newTestChain(logs *bytes.Buffer) http.Handler {
+	logger := slog.New(slog.NewJSONHandler(logs, nil))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /range/{prefix}", func(w http.ResponseWriter, r *http.Request) {
+		recordCaller(r.Context(), &models.User{ID: 7}, &models.APIKey{ID: 3})
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("POST /panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	mux.HandleFunc("GET /fail", func(w http.ResponseWriter, r *http.Request) {
+		handlers.WriteError(w, r, errors.New("connection refused"))
+	})
+
+	return chain(mux, withRequestID, accessLog(logger), recoverPanic(logger))
+}
+
+func decodeLogLines(t *testing.T, logs *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestMiddleware_RequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "propagated", incoming: "edge-1234.abc", keep: true},
+		{name: "assigned", incoming: ""},
+		{name: "unsafe replaced", incoming: "id\nlevel=ERROR"},
+		{name: "too long replaced", incoming: strings.Repeat("a", 65)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			req := httptest.NewRequest(http.MethodGet, "/range/5baa6", nil)
+			if tt.incoming != "" {
+				req.Header.Set(requestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			newTestChain(&logs).ServeHTTP(rec, req)
+
+			id := rec.Header().Get(requestIDHeader)
+			if tt.keep && id != tt.incoming {
+				t.Errorf("request id = %q, want %q", id, tt.incoming)
+			}
+			if !tt.keep && (id == tt.incoming || !requestIDPattern.MatchString(id)) {
+				t.Errorf("request id = %q, want a new one", id)
+			}
+			if got := decodeLogLines(t, &logs)[0]["request_id"]; got != id {
+				t.Errorf("logged request_id = %v, want %q", got, id)
+			}
+		})
+	}
+}
+
+func TestMiddleware_AccessLog(t *testing.T) {
+	var logs bytes.Buffer
+	req := httptest.NewRequest(http.MethodGet, "/range/5baa6?hash=21bd12dc183f740ee76f27b78eb39c8a", nil)
+	rec := httptest.NewRecorder()
+	newTestChain(&logs).ServeHTTP(rec, req)
+
+	if strings.Contains(logs.String(), "5baa6") || strings.Contains(logs.String(), "21bd12dc") {
+		t.Fatalf("access log leaks the path or query: %s", logs.String())
+	}
+
+	entry := decodeLogLines(t, &logs)[0]
+	want := map[string]any{
+		"method":     "GET",
+		"route":      "GET /range/{prefix}",
+		"status":     float64(200),
+		"bytes":      float64(2),
+		"user_id":    float64(7),
+		"api_key_id": float64(3),
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s = %v, want %v", key, entry[key], value)
+		}
+	}
+	if _, ok := entry["latency"]; !ok {
+		t.Error("latency is missing")
+	}
+}
+
+func TestMiddleware_RecoversPanic(t *testing.T) {
+	var logs bytes.Buffer
+	req := httptest.NewRequest(http.MethodPost, "/panic", strings.NewReader(`{"fields":{"email":"secret"}}`))
+	rec := httptest.NewRecorder()
+	newTestChain(&logs).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["error"] != "Internal server error" {
+		t.Errorf("body = %q, want a JSON error", rec.Body.String())
+	}
+	if strings.Contains(logs.String(), "secret") {
+		t.Errorf("logs contain the request body: %s", logs.String())
+	}
+
+	lines := decodeLogLines(t, &logs)
+	if len(lines) != 2 || lines[0]["panic"] != "boom" || lines[1]["status"] != float64(500) {
+		t.Fatalf("log lines = %v, want the panic then a 500 access line", lines)
+	}
+	if lines[0]["request_id"] != rec.Header().Get(requestIDHeader) {
+		t.Errorf("panic logged without the request id")
+	}
+}
+
+func TestMiddleware_ErrorsLoggedWithRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	defer slog.SetDefault(previous)
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	rec := httptest.NewRecorder()
+	newTestChain(&logs).ServeHTTP(rec, req)
+
+	lines := decodeLogLines(t, &logs)
+	if len(lines) != 2 || lines[0]["error"] != "connection refused" {
+		t.Fatalf("log lines = %v, want the error then the access line", lines)
+	}
+	if lines[0]["request_id"] != rec.Header().Get(requestIDHeader) {
+		t.Errorf("error logged with request_id %v, want %q", lines[0]["request_id"], rec.Header().Get(requestIDHeader))
+	}
+}
@@ -1,39 +1,205 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"log"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/Rikjimue/breach-radar/backend/pkg/api/handlers"
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/notify"
 	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/scoring"
 	"github.com/Rikjimue/breach-radar/backend/pkg/services"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
 )
 
-// TODO: Implement middleware, implement sub-routing
+const (
+	statisticsRefreshInterval = 10 * time.Minute
+	watchlistScanInterval     = time.Minute
+	webhookDeliveryInterval   = 5 * time.Second
+	alertEmailInterval        = 5 * time.Minute
+	rateLimitPruneInterval    = 10 * time.Minute
+)
+
+type Config struct {
+	// RateLimitStore selects where rate limit counters live: "memory" for a
+	// single instance or "postgres" to share them between replicas.
+	RateLimitStore string
+	// SearchStrategy selects how personal searches find breaches: "table"
+	// queries every breach table, "index" uses the global breach_index.
+	SearchStrategy string
+	// HashSchemes are the hash schemes searches cover; nil means v1 only.
+	HashSchemes *hashing.Schemes
+	// PartialHashLength is the k-anonymity prefix length sensitive searches
+	// must send; 0 means hashing.PartialHashLength.
+	PartialHashLength int
+	// Scorer rates breaches and search results; nil uses the default
+	// scoring weights.
+	Scorer *scoring.Scorer
+	// Notifier sends alert emails; nil logs them instead.
+	Notifier notify.Notifier
+	// AppURL is the frontend address links in emails point to.
+	AppURL string
+	// UniversalSalt is the salt the ingester hashed breaches with. Domain
+	// searches need it to hash verified domains the same way.
+	UniversalSalt string
+	// Logger receives access logs and recovered panics; nil uses
+	// slog.Default().
+	Logger *slog.Logger
+}
 
-// Create router
-func NewRouter(db *sql.DB) *http.ServeMux {
+// NewRouter serves the API. Every request gets a request ID, an access log
+// line, and a 500 instead of a dropped connection if its handler panics.
+func NewRouter(db *sql.DB, cfg Config) http.Handler {
 	mux := http.NewServeMux()
 
 	// Initialize repositories
-	//userRepo := repositories.NewSQLUserRepository(db)
-	breachRepo := repositories.NewSQLBreachRepository(db)
+	userRepo := repositories.NewSQLUserRepository(db)
+	remediationRepo := repositories.NewSQLRemediationRepository(db)
+	watchlistRepo := repositories.NewSQLWatchlistRepository(db)
+	webhookRepo := repositories.NewSQLWebhookRepository(db)
+	domainRepo := repositories.NewSQLDomainRepository(db)
+	orgRepo := repositories.NewSQLOrganizationRepository(db)
+
+	var breachRepo repositories.BreachRepository
+	switch cfg.SearchStrategy {
+	case "index":
+		breachRepo = repositories.NewIndexedBreachRepository(db)
+	case "", "table":
+		breachRepo = repositories.NewSQLBreachRepository(db)
+	default:
+		log.Fatalf("Unknown search strategy %q", cfg.SearchStrategy)
+	}
+
+	partialHashLength := cfg.PartialHashLength
+	if partialHashLength == 0 {
+		partialHashLength = hashing.PartialHashLength
+	}
+	if err := hashing.ValidatePartialHashLength(partialHashLength); err != nil {
+		log.Fatalf("Invalid partial hash length: %v", err)
+	}
+
+	var rateLimitRepo repositories.RateLimitRepository
+	switch cfg.RateLimitStore {
+	case "postgres":
+		rateLimitRepo = repositories.NewSQLRateLimitRepository(db)
+	case "", "memory":
+		rateLimitRepo = repositories.NewMemoryRateLimitRepository()
+	default:
+		log.Fatalf("Unknown rate limit store %q", cfg.RateLimitStore)
+	}
 
 	// Initialize Services
-	//authService := services.NewAuthService(userRepo)
-	breachService := services.NewBreachService(breachRepo)
+	authService := services.NewAuthService(userRepo)
+	authorizer := services.NewAuthorizer(orgRepo)
+	orgService := services.NewOrganizationService(orgRepo, cfg.Notifier, cfg.AppURL)
+	apiKeyService := services.NewAPIKeyService(userRepo)
+	remediationService := services.NewRemediationService(remediationRepo)
+	breachService := services.NewBreachService(breachRepo, cfg.HashSchemes, cfg.Scorer, remediationService)
+	rateLimitService := services.NewRateLimitService(rateLimitRepo, rateLimitPruneInterval)
+	go rateLimitService.Run(context.Background())
+	rangeService := services.NewRangeService(breachRepo, partialHashLength)
+	catalogService := services.NewCatalogService(breachRepo)
+	statisticsService := services.NewStatisticsService(breachRepo, cfg.Scorer, statisticsRefreshInterval)
+	go statisticsService.Run(context.Background())
+	webhookService := services.NewWebhookService(webhookRepo, nil, webhookDeliveryInterval)
+	go webhookService.Run(context.Background())
+	watchlistService := services.NewWatchlistService(watchlistRepo, breachRepo, cfg.HashSchemes, webhookService, watchlistScanInterval)
+	go watchlistService.Run(context.Background())
+	notificationService := services.NewNotificationService(userRepo, watchlistRepo, cfg.Notifier, cfg.AppURL, alertEmailInterval)
+	go notificationService.Run(context.Background())
+	domainService := services.NewDomainService(domainRepo, breachRepo, hashing.NewHasher(cfg.UniversalSalt), cfg.HashSchemes, nil, nil)
 
 	// Initialize handlers
-	//authHandler := handlers.NewAuthHandler(authService)
-	breachHandler := handlers.NewBreachHandler(breachService)
+	authHandler := handlers.NewAuthHandler(authService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService, authorizer)
+	breachHandler := handlers.NewBreachHandler(breachService, partialHashLength, authorizer, rateLimitService)
+	rangeHandler := handlers.NewRangeHandler(rangeService)
+	catalogHandler := handlers.NewCatalogHandler(catalogService)
+	statisticsHandler := handlers.NewStatisticsHandler(statisticsService)
+	remediationHandler := handlers.NewRemediationHandler(remediationService)
+	watchlistHandler := handlers.NewWatchlistHandler(watchlistService, authorizer)
+	webhookHandler := handlers.NewWebhookHandler(webhookService, authorizer)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	domainHandler := handlers.NewDomainHandler(domainService, authorizer)
+	orgHandler := handlers.NewOrganizationHandler(orgService, authorizer)
 
 	// Setup routes
-	//mux.HandleFunc("POST /api/v0/signup", authHandler.Signup)
-	//mux.HandleFunc("POST /api/v0/login", authHandler.Login)
+	auth := &authMiddleware{authService: authService, apiKeyService: apiKeyService, authorizer: authorizer}
+	public := auth.with(authPublic, "")
+	required := auth.with(authRequired, "")
+	metered := func(next http.Handler) http.Handler { return rateLimit(rateLimitService, next) }
+
+	mux.Handle("POST /api/v0/signup", setupCORS(public(http.HandlerFunc(authHandler.Signup))))
+	mux.Handle("POST /api/v0/login", setupCORS(public(http.HandlerFunc(authHandler.Login))))
+	mux.Handle("POST /api/v0/logout", setupCORS(required(http.HandlerFunc(authHandler.Logout))))
+	mux.Handle("GET /api/v0/me", setupCORS(required(http.HandlerFunc(authHandler.Me))))
+	mux.Handle("GET /api/v0/me/notifications", setupCORS(required(http.HandlerFunc(notificationHandler.GetPreferences))))
+	mux.Handle("PATCH /api/v0/me/notifications", setupCORS(required(http.HandlerFunc(notificationHandler.UpdatePreferences))))
+
+	mux.Handle("GET /api/v0/api-keys", setupCORS(required(http.HandlerFunc(apiKeyHandler.ListAPIKeys))))
+	mux.Handle("POST /api/v0/api-keys", setupCORS(required(http.HandlerFunc(apiKeyHandler.CreateAPIKey))))
+	mux.Handle("PATCH /api/v0/api-keys/{id}", setupCORS(required(http.HandlerFunc(apiKeyHandler.UpdateAPIKey))))
+	mux.Handle("DELETE /api/v0/api-keys/{id}", setupCORS(required(http.HandlerFunc(apiKeyHandler.RevokeAPIKey))))
+
+	mux.Handle("GET /api/v0/watchlist", setupCORS(required(http.HandlerFunc(watchlistHandler.ListEntries))))
+	mux.Handle("POST /api/v0/watchlist", setupCORS(required(http.HandlerFunc(watchlistHandler.CreateEntry))))
+	mux.Handle("DELETE /api/v0/watchlist/{id}", setupCORS(required(http.HandlerFunc(watchlistHandler.DeleteEntry))))
+	mux.Handle("GET /api/v0/alerts", setupCORS(required(http.HandlerFunc(watchlistHandler.ListAlerts))))
+	mux.Handle("POST /api/v0/alerts/{id}/acknowledge", setupCORS(required(http.HandlerFunc(watchlistHandler.AcknowledgeAlert))))
+
+	mux.Handle("GET /api/v0/webhooks", setupCORS(required(http.HandlerFunc(webhookHandler.ListWebhooks))))
+	mux.Handle("POST /api/v0/webhooks", setupCORS(required(http.HandlerFunc(webhookHandler.CreateWebhook))))
+	mux.Handle("DELETE /api/v0/webhooks/{id}", setupCORS(required(http.HandlerFunc(webhookHandler.DeleteWebhook))))
+	mux.Handle("GET /api/v0/webhooks/{id}/deliveries", setupCORS(required(http.HandlerFunc(webhookHandler.ListDeliveries))))
+	mux.Handle("POST /api/v0/webhook-deliveries/{id}/retry", setupCORS(required(http.HandlerFunc(webhookHandler.RetryDelivery))))
+
+	mux.Handle("GET /api/v0/org", setupCORS(required(http.HandlerFunc(orgHandler.GetOrganization))))
+	mux.Handle("POST /api/v0/org", setupCORS(required(http.HandlerFunc(orgHandler.CreateOrganization))))
+	mux.Handle("PATCH /api/v0/org", setupCORS(required(http.HandlerFunc(orgHandler.UpdateOrganization))))
+	mux.Handle("DELETE /api/v0/org", setupCORS(required(http.HandlerFunc(orgHandler.DeleteOrganization))))
+	mux.Handle("POST /api/v0/org/leave", setupCORS(required(http.HandlerFunc(orgHandler.LeaveOrganization))))
+	mux.Handle("GET /api/v0/org/members", setupCORS(required(http.HandlerFunc(orgHandler.ListMembers))))
+	mux.Handle("PATCH /api/v0/org/members/{id}", setupCORS(required(http.HandlerFunc(orgHandler.UpdateMember))))
+	mux.Handle("DELETE /api/v0/org/members/{id}", setupCORS(required(http.HandlerFunc(orgHandler.RemoveMember))))
+	mux.Handle("GET /api/v0/org/invitations", setupCORS(required(http.HandlerFunc(orgHandler.ListInvitations))))
+	mux.Handle("POST /api/v0/org/invitations", setupCORS(required(http.HandlerFunc(orgHandler.CreateInvitation))))
+	mux.Handle("DELETE /api/v0/org/invitations/{id}", setupCORS(required(http.HandlerFunc(orgHandler.DeleteInvitation))))
+	mux.Handle("POST /api/v0/invitations/accept", setupCORS(required(http.HandlerFunc(orgHandler.AcceptInvitation))))
 
-	mux.Handle("/api/v0/breach-search", setupCORS(http.HandlerFunc(breachHandler.BreachSearch)))
+	mux.Handle("GET /api/v0/domains", setupCORS(required(http.HandlerFunc(domainHandler.ListClaims))))
+	mux.Handle("POST /api/v0/domains", setupCORS(required(http.HandlerFunc(domainHandler.CreateClaim))))
+	mux.Handle("DELETE /api/v0/domains/{id}", setupCORS(required(http.HandlerFunc(domainHandler.DeleteClaim))))
+	mux.Handle("POST /api/v0/domains/{id}/verify", setupCORS(required(http.HandlerFunc(domainHandler.VerifyClaim))))
+	mux.Handle("GET /api/v0/domains/{id}/breaches", setupCORS(required(http.HandlerFunc(domainHandler.SearchDomain))))
+	mux.Handle("POST /api/v0/domains/{id}/breaches", setupCORS(required(http.HandlerFunc(domainHandler.SearchDomain))))
 
-	return mux
+	mux.Handle("/api/v0/breach-search", setupCORS(auth.with(authOptional, models.ScopeBreachSearch)(metered(http.HandlerFunc(breachHandler.BreachSearch)))))
+	mux.Handle("POST /api/v0/breach-search/bulk", setupCORS(auth.with(authRequired, models.ScopeBreachBulk)(metered(http.HandlerFunc(breachHandler.BulkBreachSearch)))))
+
+	mux.Handle("GET /api/v0/range/{fieldType}/{prefix}", setupCORS(auth.with(authOptional, models.ScopeBreachSearch)(metered(http.HandlerFunc(rangeHandler.GetRange)))))
+
+	mux.Handle("GET /api/v0/breaches", setupCORS(public(http.HandlerFunc(catalogHandler.ListBreaches))))
+	mux.Handle("GET /api/v0/breaches/{name}", setupCORS(public(http.HandlerFunc(catalogHandler.GetBreach))))
+	mux.Handle("GET /api/v0/fields", setupCORS(public(http.HandlerFunc(catalogHandler.ListFields))))
+	mux.Handle("GET /api/v0/statistics", setupCORS(public(http.HandlerFunc(statisticsHandler.GetStatistics))))
+	mux.Handle("GET /api/v0/remediation", setupCORS(public(http.HandlerFunc(remediationHandler.ListRemediation))))
+
+	// Answer CORS preflight for method-scoped routes
+	mux.Handle("OPTIONS /api/v0/", setupCORS(http.NotFoundHandler()))
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return chain(mux, withRequestID, accessLog(logger), recoverPanic(logger))
 }
 
 func setupCORS(next http.Handler) http.Handler {
@@ -41,8 +207,9 @@ func setupCORS(next http.Handler) http.Handler {
 
 		// Set CORS headers
 		w.Header().Set("Access-Control-Allow-Origin", "*") // TODO: Change for production
-		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-API-Key, Add-Padding, If-None-Match, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After, ETag, X-Request-ID")
 
 		// Handle preflight requests
 		if r.Method == "OPTIONS" {
@@ -53,3 +220,120 @@ func setupCORS(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+type authMode int
+
+const (
+	// authPublic never looks at credentials.
+	authPublic authMode = iota
+	// authOptional attaches the caller when credentials are sent, but lets
+	// anonymous requests through. Credentials that are sent and invalid are
+	// rejected.
+	authOptional
+	// authRequired rejects requests without valid credentials.
+	authRequired
+)
+
+type authMiddleware struct {
+	authService   *services.AuthService
+	apiKeyService *services.APIKeyService
+	authorizer    *services.Authorizer
+}
+
+// with returns middleware for the given mode. API keys are only accepted on
+// routes that name a scope, and only when the key carries that scope;
+// session tokens are accepted everywhere. The caller's organization
+// membership is loaded along with them.
+func (m *authMiddleware) with(mode authMode, scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if mode == authPublic {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			if apiKey := handlers.APIKeyFromRequest(r); apiKey != "" {
+				if scope == "" {
+					handlers.WriteError(w, r, utils.NewAppError(http.StatusUnauthorized, "API keys are not accepted on this endpoint"))
+					return
+				}
+				user, key, err := m.apiKeyService.Authenticate(ctx, apiKey)
+				if err != nil {
+					handlers.WriteError(w, r, err)
+					return
+				}
+				if !key.HasScope(scope) {
+					handlers.WriteError(w, r, utils.NewAppError(http.StatusForbidden, "API key is missing scope "+scope))
+					return
+				}
+				recordCaller(ctx, user, key)
+				ctx, err = m.withMembership(services.ContextWithAPIKey(ctx, key), user)
+				if err != nil {
+					handlers.WriteError(w, r, err)
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			token := handlers.BearerToken(r)
+			if token == "" {
+				if mode == authRequired {
+					handlers.WriteError(w, r, utils.NewAppError(http.StatusUnauthorized, "Authentication required"))
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, err := m.authService.Authenticate(ctx, token)
+			if err != nil {
+				handlers.WriteError(w, r, err)
+				return
+			}
+			recordCaller(ctx, user, nil)
+			ctx, err = m.withMembership(ctx, user)
+			if err != nil {
+				handlers.WriteError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func (m *authMiddleware) withMembership(ctx context.Context, user *models.User) (context.Context, error) {
+	membership, err := m.authorizer.Membership(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return services.ContextWithMembership(services.ContextWithUser(ctx, user), membership), nil
+}
+
+// rateLimit meters requests against the caller's plan. It must run after the
+// auth middleware so that the caller's identity is in the context.
+func rateLimit(rateLimitService *services.RateLimitService, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, err := rateLimitService.Check(r.Context(), handlers.ClientIP(r))
+		if err != nil {
+			handlers.WriteError(w, r, err)
+			return
+		}
+
+		if result.Limit > 0 {
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(int(time.Until(result.Reset).Seconds())))
+		}
+
+		if !result.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+			handlers.WriteError(w, r, utils.NewAppError(http.StatusTooManyRequests, "Rate limit exceeded"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,21 @@
+-- breach_metadata has always been created by hand; declare it here so that
+-- fresh databases match what the repositories expect.
+CREATE TABLE IF NOT EXISTS breach_metadata (
+    id               BIGSERIAL PRIMARY KEY,
+    name             TEXT NOT NULL UNIQUE,
+    display_name     TEXT NOT NULL,
+    breach_date      DATE NOT NULL,
+    affected_records BIGINT NOT NULL DEFAULT 0,
+    fields           TEXT[] NOT NULL DEFAULT '{}',
+    source_url       TEXT NOT NULL DEFAULT '',
+    industry         TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS breach_index (
+    field_type TEXT NOT NULL,
+    hash       TEXT NOT NULL,
+    breach_id  BIGINT NOT NULL REFERENCES breach_metadata(id) ON DELETE CASCADE,
+    PRIMARY KEY (field_type, hash, breach_id)
+);
+
+CREATE INDEX IF NOT EXISTS breach_index_breach_id_idx ON breach_index (breach_id);
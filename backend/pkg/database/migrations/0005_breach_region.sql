@@ -0,0 +1,3 @@
+-- Region where most affected records are located; used for the global
+-- impact statistics. One of north_america, europe, asia_pacific or ''.
+ALTER TABLE breach_metadata ADD COLUMN IF NOT EXISTS region TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,37 @@
+-- Shared tables for sensitive field hashes. Searches only ever send a hash
+-- prefix for these, so rows from every breach live together and are told
+-- apart by breach_source (the breach_metadata name).
+CREATE TABLE IF NOT EXISTS breach_ssn_data (
+    breach_source TEXT NOT NULL,
+    "ssn_hash"    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS breach_ssn_data_hash_idx ON breach_ssn_data ("ssn_hash");
+CREATE INDEX IF NOT EXISTS breach_ssn_data_source_idx ON breach_ssn_data (breach_source);
+
+CREATE TABLE IF NOT EXISTS breach_credit_card_data (
+    breach_source     TEXT NOT NULL,
+    "creditCard_hash" TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS breach_credit_card_data_hash_idx ON breach_credit_card_data ("creditCard_hash");
+CREATE INDEX IF NOT EXISTS breach_credit_card_data_source_idx ON breach_credit_card_data (breach_source);
+
+CREATE TABLE IF NOT EXISTS breach_license_data (
+    breach_source        TEXT NOT NULL,
+    "driverLicense_hash" TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS breach_license_data_hash_idx ON breach_license_data ("driverLicense_hash");
+CREATE INDEX IF NOT EXISTS breach_license_data_source_idx ON breach_license_data (breach_source);
+
+CREATE TABLE IF NOT EXISTS breach_passport_data (
+    breach_source   TEXT NOT NULL,
+    "passport_hash" TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS breach_passport_data_hash_idx ON breach_passport_data ("passport_hash");
+CREATE INDEX IF NOT EXISTS breach_passport_data_source_idx ON breach_passport_data (breach_source);
+
+CREATE TABLE IF NOT EXISTS breach_password_data (
+    breach_source   TEXT NOT NULL,
+    "password_hash" TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS breach_password_data_hash_idx ON breach_password_data ("password_hash");
+CREATE INDEX IF NOT EXISTS breach_password_data_source_idx ON breach_password_data (breach_source);
@@ -0,0 +1,5 @@
+-- Record which hash scheme (see pkg/hashing) each stored row uses. Every
+-- breach loaded so far holds the browser's v1 hashes. Breach tables made
+-- before this are given their hash_version and id columns by cmd/rehash,
+-- one table at a time, since adding an id rewrites the table.
+ALTER TABLE breach_index ADD COLUMN IF NOT EXISTS hash_version SMALLINT NOT NULL DEFAULT 1;
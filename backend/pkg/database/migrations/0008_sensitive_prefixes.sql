@@ -0,0 +1,23 @@
+-- Sensitive searches look rows up by hash prefix. Store the longest prefix a
+-- server may be configured with (hashing.MaxPartialHashLength) so shorter
+-- prefixes become an indexed LIKE 'prefix%' instead of LEFT() on every row.
+
+ALTER TABLE breach_ssn_data
+    ADD COLUMN IF NOT EXISTS "ssn_prefix" TEXT GENERATED ALWAYS AS (LEFT("ssn_hash", 16)) STORED;
+CREATE INDEX IF NOT EXISTS breach_ssn_data_prefix_idx ON breach_ssn_data ("ssn_prefix" text_pattern_ops);
+
+ALTER TABLE breach_credit_card_data
+    ADD COLUMN IF NOT EXISTS "creditCard_prefix" TEXT GENERATED ALWAYS AS (LEFT("creditCard_hash", 16)) STORED;
+CREATE INDEX IF NOT EXISTS breach_credit_card_data_prefix_idx ON breach_credit_card_data ("creditCard_prefix" text_pattern_ops);
+
+ALTER TABLE breach_license_data
+    ADD COLUMN IF NOT EXISTS "driverLicense_prefix" TEXT GENERATED ALWAYS AS (LEFT("driverLicense_hash", 16)) STORED;
+CREATE INDEX IF NOT EXISTS breach_license_data_prefix_idx ON breach_license_data ("driverLicense_prefix" text_pattern_ops);
+
+ALTER TABLE breach_passport_data
+    ADD COLUMN IF NOT EXISTS "passport_prefix" TEXT GENERATED ALWAYS AS (LEFT("passport_hash", 16)) STORED;
+CREATE INDEX IF NOT EXISTS breach_passport_data_prefix_idx ON breach_passport_data ("passport_prefix" text_pattern_ops);
+
+ALTER TABLE breach_password_data
+    ADD COLUMN IF NOT EXISTS "password_prefix" TEXT GENERATED ALWAYS AS (LEFT("password_hash", 16)) STORED;
+CREATE INDEX IF NOT EXISTS breach_password_data_prefix_idx ON breach_password_data ("password_prefix" text_pattern_ops);
@@ -0,0 +1,8 @@
+-- How many records of a breach hold each sensitive hash. New breaches store
+-- one row per distinct hash; rows loaded earlier stay one per record and
+-- are summed at query time.
+ALTER TABLE breach_ssn_data ADD COLUMN IF NOT EXISTS occurrences INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE breach_credit_card_data ADD COLUMN IF NOT EXISTS occurrences INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE breach_license_data ADD COLUMN IF NOT EXISTS occurrences INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE breach_passport_data ADD COLUMN IF NOT EXISTS occurrences INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE breach_password_data ADD COLUMN IF NOT EXISTS occurrences INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+-- How the breached service stored passwords, e.g. bcrypt, md5 or plaintext;
+-- weakly hashed password dumps score higher. '' when unknown.
+ALTER TABLE breach_metadata ADD COLUMN IF NOT EXISTS password_hash_algorithm TEXT NOT NULL DEFAULT '';
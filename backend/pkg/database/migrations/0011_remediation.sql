@@ -0,0 +1,70 @@
+-- Remediation knowledge base: what to do when a field type shows up in a
+-- breach. Rows without a breach apply to every breach; rows for a breach add
+-- advice specific to it, e.g. that the service already forced a reset.
+CREATE TABLE IF NOT EXISTS remediation (
+    id          BIGSERIAL PRIMARY KEY,
+    field_type  TEXT NOT NULL,
+    breach_name TEXT REFERENCES breach_metadata(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    urgency     TEXT NOT NULL CHECK (urgency IN ('Critical', 'High', 'Medium', 'Low')),
+    actions     TEXT[] NOT NULL,
+    links       JSONB NOT NULL DEFAULT '[]'
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS remediation_field_breach_idx ON remediation (field_type, COALESCE(breach_name, ''));
+CREATE INDEX IF NOT EXISTS remediation_breach_name_idx ON remediation (breach_name) WHERE breach_name IS NOT NULL;
+
+INSERT INTO remediation (field_type, urgency, actions, links) VALUES
+    ('password', 'Critical', ARRAY[
+        'Change this password on every account that uses it, starting with email and banking',
+        'Use a password manager to give every account a unique password',
+        'Turn on two-factor authentication wherever it is offered'
+    ], '[]'),
+    ('creditCard', 'Critical', ARRAY[
+        'Ask your card issuer to cancel the card and send a new number',
+        'Review recent statements and dispute any charge you do not recognize',
+        'Set up transaction alerts with your bank'
+    ], '[{"title": "Report identity theft (US)", "url": "https://www.identitytheft.gov/"}]'),
+    ('ssn', 'Critical', ARRAY[
+        'Freeze your credit with every major credit bureau',
+        'Place a fraud alert on your credit reports',
+        'Check your credit reports for accounts you did not open'
+    ], '[{"title": "Report identity theft (US)", "url": "https://www.identitytheft.gov/"}]'),
+    ('passport', 'High', ARRAY[
+        'Report the passport number as compromised to the issuing authority',
+        'Apply for a replacement passport if the authority recommends it',
+        'Watch for accounts or bookings opened in your name'
+    ], '[]'),
+    ('driverLicense', 'High', ARRAY[
+        'Ask your licensing authority whether a new license number can be issued',
+        'Check your credit reports for accounts you did not open',
+        'Be wary of calls or letters quoting your license number'
+    ], '[{"title": "Report identity theft (US)", "url": "https://www.identitytheft.gov/"}]'),
+    ('email', 'High', ARRAY[
+        'Expect targeted phishing: do not follow links in unexpected emails',
+        'Make sure your email account has a unique password and two-factor authentication',
+        'Check which accounts use this address for password resets'
+    ], '[]'),
+    ('phone', 'Medium', ARRAY[
+        'Ask your carrier for a port-out PIN to prevent SIM swapping',
+        'Be wary of calls and texts asking for codes or personal details',
+        'Avoid using SMS as your only second factor'
+    ], '[]'),
+    ('dateOfBirth', 'Medium', ARRAY[
+        'Do not use your date of birth as a security answer or PIN',
+        'Be wary of callers who quote your date of birth to gain trust'
+    ], '[]'),
+    ('address', 'Medium', ARRAY[
+        'Watch for mail or deliveries you did not order',
+        'Be wary of letters that quote your address to appear legitimate'
+    ], '[]'),
+    ('username', 'Medium', ARRAY[
+        'Change the password of every account using this username',
+        'Consider a different username for sensitive accounts'
+    ], '[]'),
+    ('firstName', 'Low', ARRAY['Be wary of messages that use your name to appear legitimate'], '[]'),
+    ('lastName', 'Low', ARRAY['Be wary of messages that use your name to appear legitimate'], '[]'),
+    ('city', 'Low', ARRAY['Avoid using your city as a security answer'], '[]'),
+    ('state', 'Low', ARRAY['Avoid using your state as a security answer'], '[]'),
+    ('zipCode', 'Low', ARRAY['Avoid using your zip code as a security answer'], '[]'),
+    ('country', 'Low', ARRAY['Be wary of messages that use your location to appear legitimate'], '[]')
+ON CONFLICT DO NOTHING;
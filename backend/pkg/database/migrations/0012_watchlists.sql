@@ -0,0 +1,33 @@
+-- Identities users want to be told about when a later breach exposes them.
+-- fields maps field type to hash, all of hash_version.
+CREATE TABLE IF NOT EXISTS watchlist_entries (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    label        TEXT NOT NULL,
+    fields       JSONB NOT NULL,
+    hash_version SMALLINT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS watchlist_entries_user_id_idx ON watchlist_entries (user_id);
+
+-- Breaches the watchlists have been checked against. Breaches registered
+-- before watchlists existed are not new to anyone.
+CREATE TABLE IF NOT EXISTS watchlist_scans (
+    breach_id  BIGINT PRIMARY KEY REFERENCES breach_metadata(id) ON DELETE CASCADE,
+    scanned_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO watchlist_scans (breach_id) SELECT id FROM breach_metadata ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS watchlist_alerts (
+    id              BIGSERIAL PRIMARY KEY,
+    entry_id        BIGINT NOT NULL REFERENCES watchlist_entries(id) ON DELETE CASCADE,
+    breach_id       BIGINT NOT NULL REFERENCES breach_metadata(id) ON DELETE CASCADE,
+    matched_fields  TEXT[] NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    acknowledged_at TIMESTAMPTZ,
+    UNIQUE (entry_id, breach_id)
+);
+
+CREATE INDEX IF NOT EXISTS watchlist_alerts_breach_id_idx ON watchlist_alerts (breach_id);
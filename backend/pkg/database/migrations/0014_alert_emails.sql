@@ -0,0 +1,9 @@
+-- How each user wants watchlist alerts emailed.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS alert_emails TEXT NOT NULL DEFAULT 'daily'
+    CHECK (alert_emails IN ('off', 'immediate', 'daily'));
+
+-- Alerts are emailed once. Alerts raised before email existed count as sent.
+ALTER TABLE watchlist_alerts ADD COLUMN IF NOT EXISTS emailed_at TIMESTAMPTZ;
+UPDATE watchlist_alerts SET emailed_at = created_at WHERE emailed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS watchlist_alerts_unemailed_idx ON watchlist_alerts (created_at) WHERE emailed_at IS NULL;
@@ -0,0 +1,17 @@
+-- Email domains accounts claim for domain monitoring. A claim proves
+-- ownership by publishing token in DNS or on the domain's web server. Many
+-- accounts may claim a domain, but only one can hold it verified.
+-- Breach tables loaded before this only have email hashes and are not
+-- covered by domain searches; the ingester adds an email_domain column.
+CREATE TABLE IF NOT EXISTS domain_claims (
+    id                  BIGSERIAL PRIMARY KEY,
+    user_id             BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    domain              TEXT NOT NULL,
+    token               TEXT NOT NULL,
+    verification_method TEXT CHECK (verification_method IN ('dns', 'file')),
+    verified_at         TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, domain)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS domain_claims_verified_domain_idx ON domain_claims (domain) WHERE verified_at IS NOT NULL;
@@ -0,0 +1,47 @@
+-- Organizations group accounts into a team with roles. An account belongs
+-- to at most one organization.
+CREATE TABLE IF NOT EXISTS organizations (
+    id         BIGSERIAL PRIMARY KEY,
+    name       TEXT NOT NULL,
+    plan       TEXT NOT NULL DEFAULT 'enterprise',
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    org_id     BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id    BIGINT NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    role       TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'analyst', 'viewer')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS organization_invitations (
+    id          BIGSERIAL PRIMARY KEY,
+    org_id      BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email       TEXT NOT NULL,
+    role        TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'analyst', 'viewer')),
+    token_hash  TEXT NOT NULL UNIQUE,
+    invited_by  BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS organization_invitations_pending_idx ON organization_invitations (org_id, email) WHERE accepted_at IS NULL;
+
+-- Domain claims belonged to enterprise accounts until now. Give every
+-- account with claims an organization it owns and move the claims there.
+INSERT INTO organizations (name, created_by)
+SELECT COALESCE(NULLIF(u.organization, ''), u.email), u.id
+FROM users u
+WHERE EXISTS (SELECT 1 FROM domain_claims d WHERE d.user_id = u.id);
+
+INSERT INTO organization_members (org_id, user_id, role)
+SELECT id, created_by, 'owner' FROM organizations;
+
+ALTER TABLE domain_claims ADD COLUMN org_id BIGINT REFERENCES organizations(id) ON DELETE CASCADE;
+UPDATE domain_claims d SET org_id = o.id FROM organizations o WHERE o.created_by = d.user_id;
+ALTER TABLE domain_claims ALTER COLUMN org_id SET NOT NULL;
+ALTER TABLE domain_claims DROP COLUMN user_id;
+ALTER TABLE domain_claims ADD UNIQUE (org_id, domain);
@@ -0,0 +1,3 @@
+-- Expired buckets and past usage days are deleted periodically
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets (updated_at);
+CREATE INDEX IF NOT EXISTS idx_daily_usage_day ON daily_usage (day);
@@ -0,0 +1,7 @@
+-- When each breach was last written to breach_index. Breaches loaded or
+-- rehashed since are missing from index searches and reported as unchecked.
+ALTER TABLE breach_metadata ADD COLUMN IF NOT EXISTS indexed_at TIMESTAMPTZ;
+
+UPDATE breach_metadata bm
+SET indexed_at = now()
+WHERE EXISTS (SELECT 1 FROM breach_index bi WHERE bi.breach_id = bm.id);
@@ -0,0 +1,37 @@
+package hashing
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"strings"
+)
+
+// emailDomainSalt salts email domain hashes. They are only ever computed
+// on the server, from breach dumps and from domains customers have
+// verified, so the browser has no counterpart.
+const emailDomainSalt = "email_domain_salt"
+
+// EmailDomain returns the normalized domain of an email address, or "" if
+// it has none.
+func EmailDomain(email string) string {
+	normalized := Normalize(email, "email")
+	at := strings.LastIndexByte(normalized, '@')
+	if at < 0 {
+		return ""
+	}
+	return NormalizeDomain(normalized[at+1:])
+}
+
+// NormalizeDomain lowercases a domain and drops surrounding whitespace and
+// a trailing root dot.
+func NormalizeDomain(domain string) string {
+	return strings.TrimSuffix(jsToLower(strings.TrimFunc(domain, isJSWhitespace)), ".")
+}
+
+// DomainHash returns the hex SHA-512 of universal salt + domain salt +
+// normalized domain. This is the v1 domain hash; it is stored under the
+// peppered schemes like the email it belongs to, through Schemes.Derive.
+func (h *Hasher) DomainHash(domain string) string {
+	sum := sha512.Sum512([]byte(h.universalSalt + emailDomainSalt + NormalizeDomain(domain)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,233 @@
+// Package hashing implements the salting and hashing scheme of the frontend
+// HashingService (frontend/lib/hashingService.ts). Hashes produced here are
+// byte-for-byte identical to the ones the browser sends; both sides are
+// checked against testdata/hashing-vectors.json at the repository root.
+package hashing
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// PartialHashLength is the number of leading hex characters of a full hash
+// that the browser sends for sensitive fields (k-anonymity). Servers may be
+// configured with any length between MinPartialHashLength and
+// MaxPartialHashLength; shorter prefixes return more candidates per lookup.
+const (
+	PartialHashLength    = 8
+	MinPartialHashLength = 5
+	MaxPartialHashLength = 16
+)
+
+// FullHashLength is the length of a hex encoded SHA-512 hash.
+const FullHashLength = sha512.Size * 2
+
+const defaultSalt = "default_salt"
+
+var fieldSalts = map[string]string{
+	"email":         "email_salt",
+	"phone":         "phone_salt",
+	"firstName":     "fname_salt",
+	"lastName":      "lname_salt",
+	"ssn":           "ssn_salt",
+	"creditCard":    "cc_salt",
+	"password":      "password_salt",
+	"username":      "username_salt",
+	"address":       "address_salt",
+	"city":          "city_salt",
+	"state":         "state_salt",
+	"zipCode":       "zip_salt",
+	"country":       "country_salt",
+	"dateOfBirth":   "dob_salt",
+	"driverLicense": "dl_salt",
+	"passport":      "passport_salt",
+}
+
+// Hasher hashes values with a universal salt.
+type Hasher struct {
+	universalSalt string
+}
+
+func NewHasher(universalSalt string) *Hasher {
+	return &Hasher{universalSalt: universalSalt}
+}
+
+// FullHash returns the hex SHA-512 of universal salt + field salt +
+// normalized value.
+func (h *Hasher) FullHash(value, fieldType string) string {
+	sum := sha512.Sum512([]byte(h.universalSalt + FieldSalt(fieldType) + Normalize(value, fieldType)))
+	return hex.EncodeToString(sum[:])
+}
+
+// PartialHash returns the prefix of fullHash that is sent for sensitive
+// fields.
+func PartialHash(fullHash string) string {
+	if len(fullHash) < PartialHashLength {
+		return fullHash
+	}
+	return fullHash[:PartialHashLength]
+}
+
+// IsFullHash reports whether s looks like a FullHash result.
+func IsFullHash(s string) bool {
+	return len(s) == FullHashLength && isLowerHex(s)
+}
+
+// IsPartialHash reports whether s looks like a PartialHash result.
+func IsPartialHash(s string) bool {
+	return ValidatePartialHash(s, PartialHashLength) == nil
+}
+
+// ValidatePartialHash checks that s is a prefix of exactly length lowercase
+// hex characters.
+func ValidatePartialHash(s string, length int) error {
+	if len(s) != length {
+		return fmt.Errorf("must be %d hex characters, got %d", length, len(s))
+	}
+	if !isLowerHex(s) {
+		return fmt.Errorf("must only contain lowercase hex characters")
+	}
+	return nil
+}
+
+// ValidatePartialHashLength checks a configured prefix length.
+func ValidatePartialHashLength(length int) error {
+	if length < MinPartialHashLength || length > MaxPartialHashLength {
+		return fmt.Errorf("partial hash length must be between %d and %d, got %d", MinPartialHashLength, MaxPartialHashLength, length)
+	}
+	return nil
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// FieldSalt returns the salt for fieldType. Unknown field types share a
+// default salt, as in the frontend.
+func FieldSalt(fieldType string) string {
+	if salt, ok := fieldSalts[fieldType]; ok {
+		return salt
+	}
+	return defaultSalt
+}
+
+// IsKnownFieldType reports whether fieldType has its own salt.
+func IsKnownFieldType(fieldType string) bool {
+	_, ok := fieldSalts[fieldType]
+	return ok
+}
+
+// FieldTypes returns every known field type, sorted.
+func FieldTypes() []string {
+	fieldTypes := make([]string, 0, len(fieldSalts))
+	for fieldType := range fieldSalts {
+		fieldTypes = append(fieldTypes, fieldType)
+	}
+	sort.Strings(fieldTypes)
+	return fieldTypes
+}
+
+// Normalize matches HashingService.normalizeInput: trim, then digits only
+// for numeric identifiers and lowercase for everything else (passwords
+// included).
+func Normalize(value, fieldType string) string {
+	value = strings.TrimFunc(value, isJSWhitespace)
+
+	switch fieldType {
+	case "phone", "ssn", "creditCard", "driverLicense":
+		return digitsOnly(value)
+	default:
+		return jsToLower(value)
+	}
+}
+
+// isJSWhitespace matches what String.prototype.trim strips. Unlike
+// unicode.IsSpace it includes the byte order mark and excludes U+0085.
+func isJSWhitespace(r rune) bool {
+	if r == '\uFEFF' {
+		return true
+	}
+	return r != '\u0085' && unicode.IsSpace(r)
+}
+
+// digitsOnly matches the frontend's value.replace(/\D/g, ""), where \D is
+// anything but an ASCII digit.
+func digitsOnly(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// jsToLower matches String.prototype.toLowerCase, which applies the full
+// Unicode case mapping where strings.ToLower only applies the simple one.
+// The two differ for U+0130 and for final capital sigma.
+func jsToLower(value string) string {
+	runes := []rune(value)
+	var b strings.Builder
+	b.Grow(len(value))
+	for i, r := range runes {
+		switch r {
+		case '\u0130': // İ
+			b.WriteString("i\u0307")
+		case 'Σ':
+			if isFinalSigma(runes, i) {
+				b.WriteRune('ς')
+			} else {
+				b.WriteRune('σ')
+			}
+		default:
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// isFinalSigma implements the Final_Sigma casing context: a cased letter
+// before the sigma and none after it, skipping case-ignorable characters.
+func isFinalSigma(runes []rune, i int) bool {
+	before := false
+	for j := i - 1; j >= 0; j-- {
+		if isCaseIgnorable(runes[j]) {
+			continue
+		}
+		before = isCased(runes[j])
+		break
+	}
+	if !before {
+		return false
+	}
+	for j := i + 1; j < len(runes); j++ {
+		if isCaseIgnorable(runes[j]) {
+			continue
+		}
+		return !isCased(runes[j])
+	}
+	return true
+}
+
+func isCased(r rune) bool {
+	return unicode.IsUpper(r) || unicode.IsLower(r) || unicode.IsTitle(r) ||
+		unicode.In(r, unicode.Other_Lowercase, unicode.Other_Uppercase)
+}
+
+func isCaseIgnorable(r rune) bool {
+	switch r {
+	case '\'', '.', ':', '^', '`', '\u00B7', '\u2018', '\u2019', '\u2024', '\u2027', '\uFE13', '\uFE52', '\uFE55', '\uFF07', '\uFF0E', '\uFF1A':
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Lm, unicode.Sk)
+}
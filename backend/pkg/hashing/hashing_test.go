@@ -0,0 +1,138 @@
+package hashing
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// The vectors are shared with the frontend, which must produce the same
+// hashes (frontend/lib/hashingService.test.ts).
+const vectorsPath = "../../../testdata/hashing-vectors.json"
+
+type goldenVectors struct {
+	UniversalSalt string `json:"universalSalt"`
+	Vectors       []struct {
+		FieldType   string `json:"fieldType"`
+		Value       string `json:"value"`
+		Normalized  string `json:"normalized"`
+		FullHash    string `json:"fullHash"`
+		PartialHash string `json:"partialHash"`
+	} `json:"vectors"`
+}
+
+func TestGoldenVectors(t *testing.T) {
+	data, err := os.ReadFile(vectorsPath)
+	if err != nil {
+		t.Fatalf("failed to read golden vectors: %v", err)
+	}
+	var golden goldenVectors
+	if err := json.Unmarshal(data, &golden); err != nil {
+		t.Fatalf("failed to parse golden vectors: %v", err)
+	}
+	if len(golden.Vectors) == 0 {
+		t.Fatal("no golden vectors")
+	}
+
+	hasher := NewHasher(golden.UniversalSalt)
+	for _, v := range golden.Vectors {
+		t.Run(v.FieldType+"/"+v.Value, func(t *testing.T) {
+			if got := Normalize(v.Value, v.FieldType); got != v.Normalized {
+				t.Errorf("Normalize() = %q, want %q", got, v.Normalized)
+			}
+			fullHash := hasher.FullHash(v.Value, v.FieldType)
+			if fullHash != v.FullHash {
+				t.Errorf("FullHash() = %s, want %s", fullHash, v.FullHash)
+			}
+			if got := PartialHash(fullHash); got != v.PartialHash {
+				t.Errorf("PartialHash() = %s, want %s", got, v.PartialHash)
+			}
+			if !IsFullHash(fullHash) || !IsPartialHash(PartialHash(fullHash)) {
+				t.Errorf("hash %s failed validation", fullHash)
+			}
+		})
+	}
+}
+
+func TestHashValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		full    bool
+		partial bool
+	}{
+		{name: "partial", value: "0a1b2c3d", partial: true},
+		{name: "uppercase partial", value: "0A1B2C3D"},
+		{name: "short partial", value: "0a1b2c"},
+		{name: "non hex partial", value: "0a1b2c3g"},
+		{name: "full", value: "d022150a57f68744a904ed7283f80f323c5c54b17a842eef5ed6d7118a6ad5654de9233bd4627e58b60c7a2be08ca5b7b736ededabf136bc3bf6fdcea345abf2", full: true},
+		{name: "empty", value: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsFullHash(tt.value); got != tt.full {
+				t.Errorf("IsFullHash() = %v, want %v", got, tt.full)
+			}
+			if got := IsPartialHash(tt.value); got != tt.partial {
+				t.Errorf("IsPartialHash() = %v, want %v", got, tt.partial)
+			}
+		})
+	}
+}
+
+func TestValidatePartialHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		length  int
+		wantErr bool
+	}{
+		{name: "configured length", value: "0a1b2c", length: 6},
+		{name: "frontend length on a 6 character server", value: "0a1b2c3d", length: 6, wantErr: true},
+		{name: "uppercase", value: "0A1B2C", length: 6, wantErr: true},
+		{name: "wildcard", value: "0a1b2%", length: 6, wantErr: true},
+		{name: "empty", value: "", length: 6, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePartialHash(tt.value, tt.length)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePartialHash() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	for length, valid := range map[int]bool{4: false, 5: true, 8: true, 16: true, 17: false} {
+		if err := ValidatePartialHashLength(length); (err == nil) != valid {
+			t.Errorf("ValidatePartialHashLength(%d) error = %v, want valid %v", length, err, valid)
+		}
+	}
+}
+
+func TestEmailDomain(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{email: "Jane.Doe@Example.COM", want: "example.com"},
+		{email: "  ops@mail.example.com. ", want: "mail.example.com"},
+		{email: `"odd@name"@example.org`, want: "example.org"},
+		{email: "no-domain", want: ""},
+		{email: "trailing@", want: ""},
+	}
+	for _, tt := range tests {
+		if got := EmailDomain(tt.email); got != tt.want {
+			t.Errorf("EmailDomain(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+
+	hasher := NewHasher("salt")
+	if hasher.DomainHash("Example.com.") != hasher.DomainHash(EmailDomain("jane@example.com")) {
+		t.Error("DomainHash() differs for the same domain")
+	}
+	if !IsFullHash(hasher.DomainHash("example.com")) {
+		t.Error("DomainHash() is not a full hash")
+	}
+}
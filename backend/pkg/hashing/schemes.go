@@ -0,0 +1,164 @@
+package hashing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SchemeV1 is the hash the browser computes: FullHash with the public
+// universal salt. Every later version wraps the previous one in
+// HMAC-SHA-256 keyed with a server-side pepper:
+//
+//	v1 = FullHash(value)
+//	vN = hex(HMAC-SHA-256(pepperN, vN-1))
+//
+// Because each version only needs the previous hash, stored rows can be
+// moved to a new pepper without the raw values, and a dump of the tables is
+// useless without every pepper in the chain. Sensitive fields are always
+// stored as v1: their k-anonymity lookup has the browser compare candidate
+// hashes itself, which it could not do with a pepper it does not know.
+const SchemeV1 = 1
+
+// minPepperBytes is the shortest pepper accepted.
+const minPepperBytes = 16
+
+// Schemes holds the configured peppers and which scheme versions are still
+// searched.
+type Schemes struct {
+	peppers   map[int][]byte
+	latest    int
+	minActive int
+}
+
+// NewSchemes validates peppers, keyed by version, which must run from 2 up
+// without gaps. Versions below minActive are retired: they are no longer
+// searched, but their peppers are still needed to derive later versions.
+func NewSchemes(peppers map[int][]byte, minActive int) (*Schemes, error) {
+	latest := SchemeV1
+	for version := SchemeV1 + 1; ; version++ {
+		pepper, ok := peppers[version]
+		if !ok {
+			break
+		}
+		if len(pepper) < minPepperBytes {
+			return nil, fmt.Errorf("pepper for hash scheme v%d must be at least %d bytes", version, minPepperBytes)
+		}
+		latest = version
+	}
+	if len(peppers) != latest-SchemeV1 {
+		return nil, fmt.Errorf("hash scheme peppers must be numbered from v2 without gaps")
+	}
+
+	if minActive == 0 {
+		minActive = SchemeV1
+	}
+	if minActive < SchemeV1 || minActive > latest {
+		return nil, fmt.Errorf("minimum active hash scheme v%d is not configured", minActive)
+	}
+
+	return &Schemes{peppers: peppers, latest: latest, minActive: minActive}, nil
+}
+
+// ParseSchemes reads peppers given as "2:<hex>,3:<hex>" and the minimum
+// active version, as in the HASH_PEPPERS and HASH_MIN_VERSION environment
+// variables. An empty minActive keeps every version active.
+func ParseSchemes(spec, minActive string) (*Schemes, error) {
+	minVersion := SchemeV1
+	if minActive != "" {
+		var err error
+		if minVersion, err = strconv.Atoi(strings.TrimPrefix(minActive, "v")); err != nil {
+			return nil, fmt.Errorf("invalid minimum hash scheme %q", minActive)
+		}
+	}
+
+	peppers := make(map[int][]byte)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		versionText, pepperText, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid pepper %q: want <version>:<hex>", entry)
+		}
+		version, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(versionText), "v"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pepper version %q", versionText)
+		}
+		if _, dup := peppers[version]; dup {
+			return nil, fmt.Errorf("duplicate pepper for hash scheme v%d", version)
+		}
+		pepper, err := hex.DecodeString(strings.TrimSpace(pepperText))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pepper for hash scheme v%d: %w", version, err)
+		}
+		peppers[version] = pepper
+	}
+	return NewSchemes(peppers, minVersion)
+}
+
+// V1Schemes returns the configuration without any pepper.
+func V1Schemes() *Schemes {
+	return &Schemes{peppers: map[int][]byte{}, latest: SchemeV1, minActive: SchemeV1}
+}
+
+// Latest returns the version new rows are written with.
+func (s *Schemes) Latest() int {
+	return s.latest
+}
+
+// Active returns the versions that are searched, oldest first.
+func (s *Schemes) Active() []int {
+	versions := make([]int, 0, s.latest-s.minActive+1)
+	for version := s.minActive; version <= s.latest; version++ {
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+// IsKnown reports whether version is configured, active or not.
+func (s *Schemes) IsKnown(version int) bool {
+	return version >= SchemeV1 && version <= s.latest
+}
+
+// Derive turns a hash of version from into a hash of version to. Hashes can
+// only be derived forwards.
+func (s *Schemes) Derive(hash string, from, to int) (string, error) {
+	if !s.IsKnown(from) || !s.IsKnown(to) {
+		return "", fmt.Errorf("unknown hash scheme v%d or v%d", from, to)
+	}
+	if to < from {
+		return "", fmt.Errorf("cannot derive hash scheme v%d from v%d", to, from)
+	}
+	for version := from + 1; version <= to; version++ {
+		mac := hmac.New(sha256.New, s.peppers[version])
+		mac.Write([]byte(hash))
+		hash = hex.EncodeToString(mac.Sum(nil))
+	}
+	return hash, nil
+}
+
+// DeriveActive returns the hash for every active version that can be derived
+// from a hash of version from, oldest first.
+func (s *Schemes) DeriveActive(hash string, from int) ([]string, error) {
+	if !s.IsKnown(from) {
+		return nil, fmt.Errorf("unknown hash scheme v%d", from)
+	}
+
+	var hashes []string
+	for _, version := range s.Active() {
+		if version < from {
+			continue
+		}
+		derived, err := s.Derive(hash, from, version)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, derived)
+	}
+	return hashes, nil
+}
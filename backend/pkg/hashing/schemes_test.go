@@ -0,0 +1,105 @@
+package hashing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+const (
+	testPepper2 = "00112233445566778899aabbccddeeff"
+	testPepper3 = "ffeeddccbbaa99887766554433221100"
+)
+
+func TestParseSchemes(t *testing.T) {
+	tests := []struct {
+		name      string
+		peppers   string
+		minActive string
+		latest    int
+		active    []int
+		wantErr   bool
+	}{
+		{name: "no peppers", latest: 1, active: []int{1}},
+		{name: "one pepper", peppers: "2:" + testPepper2, latest: 2, active: []int{1, 2}},
+		{name: "rotation in progress", peppers: "v3:" + testPepper3 + ", v2:" + testPepper2, minActive: "2", latest: 3, active: []int{2, 3}},
+		{name: "gap", peppers: "3:" + testPepper3, wantErr: true},
+		{name: "short pepper", peppers: "2:0011", wantErr: true},
+		{name: "not hex", peppers: "2:" + strings.Repeat("z", 32), wantErr: true},
+		{name: "duplicate", peppers: "2:" + testPepper2 + ",2:" + testPepper3, wantErr: true},
+		{name: "min above latest", peppers: "2:" + testPepper2, minActive: "3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schemes, err := ParseSchemes(tt.peppers, tt.minActive)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ParseSchemes() expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSchemes() error = %v", err)
+			}
+			if schemes.Latest() != tt.latest {
+				t.Errorf("Latest() = %d, want %d", schemes.Latest(), tt.latest)
+			}
+			if got := schemes.Active(); len(got) != len(tt.active) || got[0] != tt.active[0] {
+				t.Errorf("Active() = %v, want %v", got, tt.active)
+			}
+		})
+	}
+}
+
+func TestSchemesDerive(t *testing.T) {
+	schemes, err := ParseSchemes("2:"+testPepper2+",3:"+testPepper3, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := NewHasher("salt").FullHash("alice@example.com", "email")
+
+	pepper2, _ := hex.DecodeString(testPepper2)
+	mac := hmac.New(sha256.New, pepper2)
+	mac.Write([]byte(v1))
+	wantV2 := hex.EncodeToString(mac.Sum(nil))
+
+	v2, err := schemes.Derive(v1, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v2 != wantV2 {
+		t.Errorf("Derive(v1, 1, 2) = %s, want %s", v2, wantV2)
+	}
+
+	// Rotating from stored v2 rows must give the same result as hashing v1
+	// straight to v3.
+	fromV2, err := schemes.Derive(v2, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromV1, err := schemes.Derive(v1, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromV2 != fromV1 {
+		t.Errorf("Derive via v2 = %s, direct = %s", fromV2, fromV1)
+	}
+
+	if _, err := schemes.Derive(v2, 2, 1); err == nil {
+		t.Error("Derive() backwards expected error")
+	}
+	if _, err := schemes.Derive(v1, 1, 4); err == nil {
+		t.Error("Derive() to unknown version expected error")
+	}
+
+	hashes, err := schemes.DeriveActive(v1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hashes) != 3 || hashes[0] != v1 || hashes[1] != v2 || hashes[2] != fromV1 {
+		t.Errorf("DeriveActive() = %v", hashes)
+	}
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+// API key scopes
+const (
+	ScopeBreachSearch = "breach:search"
+	ScopeBreachBulk   = "breach:bulk"
+)
+
+var APIKeyScopes = []string{ScopeBreachSearch, ScopeBreachBulk}
+
+type APIKey struct {
+	ID         uint64     `json:"id" db:"id"`
+	UserID     uint64     `json:"userId" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"` // first characters of the key, shown so users can tell keys apart
+	KeyHash    string     `json:"-" db:"key_hash"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	LastUsedAt *time.Time `json:"lastUsedAt" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revokedAt" db:"revoked_at"`
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+type UpdateAPIKeyRequest struct {
+	Name   *string  `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// CreateAPIKeyResponse is the only time the plaintext key is returned.
+type CreateAPIKeyResponse struct {
+	Key    string  `json:"key"`
+	APIKey *APIKey `json:"apiKey"`
+}
@@ -10,6 +10,17 @@ type ExactMatch struct {
 	AffectedRecords string   `json:"affectedRecords"`
 	MatchedFields   []string `json:"matchedFields"`
 	PartialMatch    bool     `json:"partialMatch"`
+	Severity        string   `json:"severity"`
+	RiskScore       int      `json:"riskScore"`
+	// SameRecordFields groups matched fields that were found together in
+	// one record, i.e. belong to the same leaked profile. A field can be in
+	// several groups when it matches in several records.
+	SameRecordFields [][]string `json:"sameRecordFields"`
+	// IndependentFields matched, but never in a record with another
+	// matched field, e.g. a common surname somewhere in the breach.
+	IndependentFields []string `json:"independentFields"`
+	// Remediation advises what to do about the matched fields.
+	Remediation []Remediation `json:"remediation"`
 }
 
 type BreachCandidate struct {
@@ -18,6 +29,29 @@ type BreachCandidate struct {
 	AffectedRecords string              `json:"affectedRecords"`
 	HashCandidates  map[string][]string `json:"hashCandidates"`
 	PartialMatch    bool                `json:"partialMatch"`
+	Severity        string              `json:"severity"`
+	RiskScore       int                 `json:"riskScore"`
+	// HashPrevalence describes every hash in HashCandidates, keyed by hash.
+	HashPrevalence map[string]HashPrevalence `json:"hashPrevalence"`
+	// Remediation advises what to do if a candidate turns out to match.
+	Remediation []Remediation `json:"remediation"`
+}
+
+// HashPrevalence says how common a sensitive hash is: how often it occurs in
+// one breach and across every breach, and when it was first and last seen.
+type HashPrevalence struct {
+	Occurrences      int    `json:"occurrences"`
+	TotalOccurrences int    `json:"totalOccurrences"`
+	Breaches         int    `json:"breaches"`
+	FirstSeen        string `json:"firstSeen"`
+	LastSeen         string `json:"lastSeen"`
+}
+
+// RiskScore rates a breach or a whole search result from 0 to 100, see
+// pkg/scoring.
+type RiskScore struct {
+	Score    int    `json:"score"`
+	Severity string `json:"severity"` // Critical, High, Medium or Low
 }
 
 type BreachMetadata struct {
@@ -29,9 +63,44 @@ type BreachMetadata struct {
 	Fields          []string  `json:"fields" db:"fields"`
 	SourceURL       string    `json:"sourceUrl" db:"source_url"`
 	Industry        string    `json:"industry" db:"industry"`
+	Region          string    `json:"region" db:"region"`
+	// PasswordHashAlgorithm is how the breached service stored passwords,
+	// e.g. "bcrypt" or "plaintext"; "" if unknown.
+	PasswordHashAlgorithm string `json:"passwordHashAlgorithm" db:"password_hash_algorithm"`
 }
 
 type SensitiveTables struct {
 	Field      string
 	TableNames []string
 }
+
+// BreachFieldMatch is a breach together with the searched fields found in it.
+type BreachFieldMatch struct {
+	Breach        BreachMetadata
+	MatchedFields []string
+}
+
+// BreachListFilter selects and orders breaches for the catalog. Zero values
+// mean "no filter".
+type BreachListFilter struct {
+	Industry  string
+	FieldType string
+	From      *time.Time
+	To        *time.Time
+	SortBy    string // "date" or "size"
+	Ascending bool
+	Limit     int // 0 returns every breach
+	Offset    int
+}
+
+type BreachListResponse struct {
+	Breaches []BreachMetadata `json:"breaches"`
+	Total    int              `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"pageSize"`
+}
+
+type FieldCoverage struct {
+	Field    string `json:"field"`
+	Breaches int    `json:"breaches"`
+}
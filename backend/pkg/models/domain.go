@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+)
+
+// Domain verification methods
+const (
+	DomainVerificationDNS  = "dns"
+	DomainVerificationFile = "file"
+)
+
+// DomainClaim is an email domain an organization wants to monitor.
+// Searches are only allowed once ownership is verified.
+type DomainClaim struct {
+	ID                 uint64     `json:"id" db:"id"`
+	OrgID              uint64     `json:"orgId" db:"org_id"`
+	Domain             string     `json:"domain" db:"domain"`
+	Token              string     `json:"-" db:"token"`
+	VerificationMethod string     `json:"verificationMethod,omitempty" db:"verification_method"`
+	VerifiedAt         *time.Time `json:"verifiedAt" db:"verified_at"`
+	CreatedAt          time.Time  `json:"createdAt" db:"created_at"`
+
+	// How to prove ownership, filled in by the service
+	Verification *DomainVerification `json:"verification,omitempty"`
+}
+
+// DomainVerification tells the owner what to publish. Either one works.
+type DomainVerification struct {
+	DNSRecordName  string `json:"dnsRecordName"`
+	DNSRecordValue string `json:"dnsRecordValue"`
+	FileURL        string `json:"fileUrl"`
+	FileContent    string `json:"fileContent"`
+}
+
+type CreateDomainClaimRequest struct {
+	Domain string `json:"domain"`
+}
+
+type VerifyDomainRequest struct {
+	Method string `json:"method"`
+}
+
+// DomainAccount is one breached account of a domain. Emails are only
+// stored hashed, so accounts are identified by their email hash under
+// HashVersion. Only v1, the hash the browser computes, can be computed
+// outside the server; later versions are peppered. To recognize accounts,
+// search with the v1 hashes of the domain's addresses, and matches are
+// reported by those.
+type DomainAccount struct {
+	EmailHash     string   `json:"emailHash"`
+	HashVersion   int      `json:"hashVersion"`
+	ExposedFields []string `json:"exposedFields"`
+}
+
+type DomainBreach struct {
+	Breach           string          `json:"breach"`
+	DisplayName      string          `json:"displayName"`
+	Date             time.Time       `json:"date"`
+	AffectedAccounts int64           `json:"affectedAccounts"`
+	Accounts         []DomainAccount `json:"accounts"`
+	// Truncated is set when Accounts holds fewer than AffectedAccounts.
+	Truncated bool `json:"truncated"`
+}
+
+// DomainSearchRequest limits a domain search to accounts whose email has
+// one of EmailHashes, the v1 hashes of addresses at the domain.
+type DomainSearchRequest struct {
+	EmailHashes []string `json:"emailHashes"`
+}
+
+type DomainSearchResponse struct {
+	Domain   string         `json:"domain"`
+	Breaches []DomainBreach `json:"breaches"`
+	// Uncovered lists breaches with emails that were loaded without domain
+	// hashes and so could not be searched.
+	Uncovered []string `json:"uncovered"`
+}
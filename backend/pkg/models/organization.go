@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+)
+
+// Organization roles, from most to least privileged
+const (
+	RoleOwner   = "owner"
+	RoleAdmin   = "admin"
+	RoleAnalyst = "analyst"
+	RoleViewer  = "viewer"
+)
+
+var OrgRoles = []string{RoleOwner, RoleAdmin, RoleAnalyst, RoleViewer}
+
+// Permissions checked by the authorization layer
+const (
+	PermOrgManage      = "org:manage"
+	PermMembersRead    = "members:read"
+	PermMembersManage  = "members:manage"
+	PermAPIKeysManage  = "api_keys:manage"
+	PermBulkSearch     = "breach:bulk"
+	PermWatchlistRead  = "watchlist:read"
+	PermWatchlistWrite = "watchlist:write"
+	PermWebhooksManage = "webhooks:manage"
+	PermDomainsRead    = "domains:read"
+	PermDomainsManage  = "domains:manage"
+)
+
+type Organization struct {
+	ID        uint64    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Plan      string    `json:"plan" db:"plan"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// Membership is the organization an account belongs to and its role there.
+type Membership struct {
+	Organization
+	Role string `json:"role" db:"role"`
+}
+
+type Member struct {
+	UserID    uint64    `json:"userId" db:"user_id"`
+	Email     string    `json:"email" db:"email"`
+	FirstName string    `json:"firstName" db:"first_name"`
+	LastName  string    `json:"lastName" db:"last_name"`
+	Role      string    `json:"role" db:"role"`
+	JoinedAt  time.Time `json:"joinedAt" db:"created_at"`
+}
+
+type Invitation struct {
+	ID        uint64    `json:"id" db:"id"`
+	OrgID     uint64    `json:"orgId" db:"org_id"`
+	Email     string    `json:"email" db:"email"`
+	Role      string    `json:"role" db:"role"`
+	TokenHash string    `json:"-" db:"token_hash"`
+	InvitedBy uint64    `json:"invitedBy" db:"invited_by"`
+	ExpiresAt time.Time `json:"expiresAt" db:"expires_at"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+type CreateInvitationRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+type UpdateMemberRequest struct {
+	Role string `json:"role"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// HashRange lists every stored hash of one sensitive field type that starts
+// with Prefix, as suffixes with the number of rows holding them.
+type HashRange struct {
+	FieldType string       `json:"fieldType"`
+	Prefix    string       `json:"prefix"`
+	Suffixes  []HashSuffix `json:"suffixes"`
+}
+
+// HashSuffix is one hash of a range without its prefix. Padding entries have
+// a count of 0.
+type HashSuffix struct {
+	Suffix string `json:"suffix"`
+	Count  int    `json:"count"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// Remediation is advice for someone whose FieldType showed up in a breach.
+type Remediation struct {
+	FieldType string `json:"fieldType"`
+	// Breach is the breach this advice is specific to; "" applies to every
+	// breach exposing FieldType.
+	Breach  string            `json:"breach,omitempty"`
+	Urgency string            `json:"urgency"` // Critical, High, Medium or Low
+	Actions []string          `json:"actions"`
+	Links   []RemediationLink `json:"links"`
+}
+
+type RemediationLink struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+type RemediationResponse struct {
+	Remediation []Remediation `json:"remediation"`
+}
@@ -3,14 +3,48 @@ package models
 type BreachSearchRequest struct {
 	Mode   string            `json:"mode"` // "personal" or "sensitive"
 	Fields map[string]string `json:"fields"`
+	// Strict fails the whole search when any breach cannot be checked,
+	// instead of returning an incomplete result.
+	Strict bool `json:"strict"`
+	// HashVersion is the hash scheme the field hashes were computed with.
+	// Browsers always send v1; 0 means v1.
+	HashVersion int `json:"hashVersion,omitempty"`
+	// MinOccurrences drops sensitive candidates seen fewer times across all
+	// breaches, e.g. to only reject common passwords at signup.
+	MinOccurrences int `json:"minOccurrences,omitempty"`
 }
 
+// Incomplete is set when some breaches could not be checked; an empty result
+// is then not proof that the identity was not breached.
 type PersonalSearchResponse struct {
 	ExactMatches []ExactMatch `json:"exactMatches"`
 	SearchFields []string     `json:"searchFields"`
+	// Exposure rates the result as a whole from the fields that matched.
+	Exposure          RiskScore `json:"exposure"`
+	Incomplete        bool      `json:"incomplete"`
+	UncheckedBreaches []string  `json:"uncheckedBreaches,omitempty"`
 }
 
+// Candidates are unverified until the client compares the full hashes, so
+// sensitive results carry no exposure score.
 type SensitiveSearchResponse struct {
 	CandidateBreaches []BreachCandidate `json:"candidateBreaches"`
 	SearchFields      []string          `json:"searchFields"`
+	Incomplete        bool              `json:"incomplete"`
+	UncheckedBreaches []string          `json:"uncheckedBreaches,omitempty"`
+	UncheckedFields   []string          `json:"uncheckedFields,omitempty"`
+}
+
+type BulkSearchItem struct {
+	ID          string            `json:"id"`
+	Fields      map[string]string `json:"fields"`
+	HashVersion int               `json:"hashVersion,omitempty"`
+}
+
+// BulkSearchResult is streamed back once per submitted identity, in the order
+// the searches finish.
+type BulkSearchResult struct {
+	ID     string      `json:"id"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
 }
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+)
+
+// Statistics backs the frontend statistics page. Its shape follows
+// frontend/app/api/statistics/route.ts.
+type Statistics struct {
+	TotalRecords         string             `json:"totalRecords"`
+	TotalRecordsChange   string             `json:"totalRecordsChange"`
+	ActiveBreaches       int                `json:"activeBreaches"`
+	ActiveBreachesChange int                `json:"activeBreachesChange"`
+	DataSources          string             `json:"dataSources"`
+	DataSourcesChange    string             `json:"dataSourcesChange"`
+	BreachActivity       []int              `json:"breachActivity"` // breaches per day, oldest first, last 30 days
+	BreachSeverity       SeverityBreakdown  `json:"breachSeverity"`
+	TopIndustries        []IndustryStat     `json:"topIndustries"`
+	DataTypes            []DataTypeStat     `json:"dataTypes"`
+	RecentBreaches       []RecentBreachStat `json:"recentBreaches"`
+	GlobalImpact         GlobalImpact       `json:"globalImpact"`
+	GeneratedAt          time.Time          `json:"generatedAt"`
+}
+
+// SeverityBreakdown holds the percentage of breaches at each severity.
+type SeverityBreakdown struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+}
+
+type IndustryStat struct {
+	Industry string `json:"industry"`
+	Breaches int    `json:"breaches"`
+	Trend    string `json:"trend"` // "up" or "down"
+}
+
+type DataTypeStat struct {
+	Type       string `json:"type"`
+	Percentage int    `json:"percentage"`
+}
+
+type RecentBreachStat struct {
+	Name     string `json:"name"`
+	Records  string `json:"records"`
+	TimeAgo  string `json:"timeAgo"`
+	Severity string `json:"severity"`
+}
+
+type GlobalImpact struct {
+	NorthAmerica string `json:"northAmerica"`
+	Europe       string `json:"europe"`
+	AsiaPacific  string `json:"asiaPacific"`
+	OtherRegions string `json:"otherRegions"`
+}
@@ -1,4 +1,58 @@
 package models
 
+import (
+	"time"
+)
+
 type User struct {
+	ID           uint64    `json:"id" db:"id"`
+	Email        string    `json:"email" db:"email"`
+	PasswordHash string    `json:"-" db:"password_hash"`
+	FirstName    string    `json:"firstName" db:"first_name"`
+	LastName     string    `json:"lastName" db:"last_name"`
+	Organization string    `json:"organization" db:"organization"`
+	Plan         string    `json:"plan" db:"plan"` // "free", "professional" or "enterprise"
+	AlertEmails  string    `json:"alertEmails" db:"alert_emails"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// How watchlist alerts are emailed
+const (
+	AlertEmailsOff       = "off"
+	AlertEmailsImmediate = "immediate"
+	AlertEmailsDaily     = "daily"
+)
+
+var AlertEmailOptions = []string{AlertEmailsOff, AlertEmailsImmediate, AlertEmailsDaily}
+
+type NotificationPreferences struct {
+	AlertEmails string `json:"alertEmails"`
+}
+
+type Session struct {
+	ID        uint64    `json:"id" db:"id"`
+	UserID    uint64    `json:"userId" db:"user_id"`
+	TokenHash string    `json:"-" db:"token_hash"`
+	ExpiresAt time.Time `json:"expiresAt" db:"expires_at"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+type SignupRequest struct {
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+	FirstName    string `json:"firstName"`
+	LastName     string `json:"lastName"`
+	Organization string `json:"organization"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type AuthResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	User      *User     `json:"user"`
 }
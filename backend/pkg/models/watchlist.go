@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// WatchlistEntry is an identity a user wants to hear about when a breach
+// imported later contains it.
+type WatchlistEntry struct {
+	ID     uint64 `json:"id" db:"id"`
+	UserID uint64 `json:"userId" db:"user_id"`
+	Label  string `json:"label" db:"label"`
+	// Hashes maps field type to hash, all of HashVersion. They are never
+	// sent back; Fields lists the watched field types instead.
+	Hashes      map[string]string `json:"-" db:"fields"`
+	HashVersion int               `json:"-" db:"hash_version"`
+	Fields      []string          `json:"fields"`
+	CreatedAt   time.Time         `json:"createdAt" db:"created_at"`
+}
+
+// CreateWatchlistEntryRequest carries v1 hashes of personal fields, as the
+// browser computes them for searches.
+type CreateWatchlistEntryRequest struct {
+	Label  string            `json:"label"`
+	Fields map[string]string `json:"fields"`
+}
+
+// WatchlistAlert records that a newly registered breach contains fields of a
+// watchlist entry.
+type WatchlistAlert struct {
+	ID             uint64     `json:"id" db:"id"`
+	EntryID        uint64     `json:"entryId" db:"entry_id"`
+	EntryLabel     string     `json:"entryLabel"`
+	UserID         uint64     `json:"-" db:"user_id"`
+	BreachID       uint64     `json:"-" db:"breach_id"`
+	Breach         string     `json:"breach"`
+	BreachDate     time.Time  `json:"breachDate"`
+	MatchedFields  []string   `json:"matchedFields" db:"matched_fields"`
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+	AcknowledgedAt *time.Time `json:"acknowledgedAt" db:"acknowledged_at"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook event types
+const (
+	// EventBreachMatch is sent to an account when a new breach contains one
+	// of its watchlist entries.
+	EventBreachMatch = "breach.match"
+	// EventBreachAdded is sent to every subscriber when a breach is
+	// registered.
+	EventBreachAdded = "breach.added"
+)
+
+var WebhookEventTypes = []string{EventBreachMatch, EventBreachAdded}
+
+// Webhook delivery states
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+type Webhook struct {
+	ID         uint64    `json:"id" db:"id"`
+	UserID     uint64    `json:"userId" db:"user_id"`
+	URL        string    `json:"url" db:"url"`
+	Secret     string    `json:"-" db:"secret"`
+	EventTypes []string  `json:"eventTypes" db:"event_types"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+}
+
+// CreateWebhookRequest may leave Secret empty to have one generated.
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"eventTypes"`
+}
+
+// CreateWebhookResponse is the only time the secret is returned.
+type CreateWebhookResponse struct {
+	Secret  string   `json:"secret"`
+	Webhook *Webhook `json:"webhook"`
+}
+
+// WebhookEvent is the body of every webhook request. Retries of an event
+// keep its ID so receivers can drop duplicates.
+type WebhookEvent struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"`
+	// UserID limits the event to one account's webhooks; 0 sends it to
+	// every webhook subscribed to Type.
+	UserID uint64 `json:"-"`
+}
+
+// BreachMatchEvent is the data of a breach.match event.
+type BreachMatchEvent struct {
+	EntryID       uint64    `json:"entryId"`
+	EntryLabel    string    `json:"entryLabel"`
+	Breach        string    `json:"breach"`
+	BreachDate    time.Time `json:"breachDate"`
+	MatchedFields []string  `json:"matchedFields"`
+}
+
+type WebhookDelivery struct {
+	ID            uint64           `json:"id" db:"id"`
+	WebhookID     uint64           `json:"webhookId" db:"webhook_id"`
+	EventID       string           `json:"eventId" db:"event_id"`
+	EventType     string           `json:"eventType" db:"event_type"`
+	Payload       json.RawMessage  `json:"payload" db:"payload"`
+	Status        string           `json:"status" db:"status"`
+	Attempts      int              `json:"attempts" db:"attempts"`
+	NextAttemptAt *time.Time       `json:"nextAttemptAt" db:"next_attempt_at"`
+	CreatedAt     time.Time        `json:"createdAt" db:"created_at"`
+	DeliveredAt   *time.Time       `json:"deliveredAt" db:"delivered_at"`
+	Log           []WebhookAttempt `json:"log"`
+
+	// Filled in for the delivery worker only
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// WebhookAttempt is one request of a delivery. StatusCode is 0 when no
+// response came back.
+type WebhookAttempt struct {
+	AttemptedAt time.Time `json:"attemptedAt" db:"attempted_at"`
+	StatusCode  int       `json:"statusCode" db:"status_code"`
+	Error       string    `json:"error,omitempty" db:"error"`
+	DurationMs  int64     `json:"durationMs" db:"duration_ms"`
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// FileNotifier writes every message to its own .eml file in a directory,
+// where a mail client can open it.
+type FileNotifier struct {
+	dir  string
+	from string
+	seq  atomic.Uint64
+}
+
+func NewFileNotifier(dir, from string) (*FileNotifier, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating mail directory %s: %w", dir, err)
+	}
+	return &FileNotifier{dir: dir, from: from}, nil
+}
+
+func (n *FileNotifier) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	body, err := Encode(n.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%04d.eml", now.UTC().Format("20060102T150405"), n.seq.Add(1))
+	if err := os.WriteFile(filepath.Join(n.dir, name), body, 0o644); err != nil {
+		return fmt.Errorf("error writing %s: %w", name, err)
+	}
+	return nil
+}
+
+// LogNotifier prints the text part of every message to a logger.
+type LogNotifier struct {
+	logger *log.Logger
+}
+
+// NewLogNotifier logs with logger; nil uses the standard logger.
+func NewLogNotifier(logger *log.Logger) *LogNotifier {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Send(ctx context.Context, msg Message) error {
+	n.logger.Printf("Email to %s: %s\n%s", msg.To, msg.Subject, msg.Text)
+	return nil
+}
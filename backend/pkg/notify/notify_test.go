@@ -0,0 +1,224 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+)
+
+// smtpSink is a minimal SMTP server that keeps every message it receives.
+type smtpSink struct {
+	listener net.Listener
+	messages chan string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &smtpSink{listener: listener, messages: make(chan string, 10)}
+	t.Cleanup(func() { listener.Close() })
+	go sink.serve()
+	return sink
+}
+
+func (s *smtpSink) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpSink) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpSink) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 sink ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.Fields(line + " x")[0])
+		switch verb {
+		case "EHLO":
+			reply("250-sink")
+			reply("250 8BITMIME")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			s.messages <- data.String()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func TestSMTPNotifier_Send(t *testing.T) {
+	sink := newSMTPSink(t)
+	notifier := NewSMTPNotifier(SMTPConfig{Host: "127.0.0.1", Port: sink.port(), From: "alerts@breachradar.test", Timeout: 5 * time.Second})
+
+	msg := Message{To: "analyst@example.com", Subject: "Breach détecté", Text: "Plain body", HTML: "<p>HTML body</p>"}
+	if err := notifier.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	var raw string
+	select {
+	case raw = <-sink.messages:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sink received no message")
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if subject != msg.Subject || parsed.Header.Get("To") != msg.To {
+		t.Errorf("headers: subject %q, to %q", subject, parsed.Header.Get("To"))
+	}
+
+	_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := parts.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(part)
+		bodies = append(bodies, string(body))
+	}
+	if len(bodies) != 2 || bodies[0] != msg.Text || bodies[1] != msg.HTML {
+		t.Errorf("parts = %q, want text and html", bodies)
+	}
+}
+
+func TestSMTPNotifier_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	notifier := NewSMTPNotifier(SMTPConfig{Host: "127.0.0.1", Port: port, From: "alerts@breachradar.test"})
+	if err := notifier.Send(context.Background(), Message{To: "a@example.com", Subject: "s", Text: "t"}); err == nil {
+		t.Error("Send() to closed port succeeded")
+	}
+}
+
+func TestEncode_RejectsHeaderInjection(t *testing.T) {
+	_, err := Encode("alerts@breachradar.test", Message{To: "a@example.com\r\nBcc: b@example.com", Subject: "s", Text: "t"}, time.Now())
+	if err == nil {
+		t.Error("Encode() accepted a line break in To")
+	}
+}
+
+func TestTemplates_Render(t *testing.T) {
+	templates := DefaultTemplates()
+	breachDate := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	alerts := AlertData{
+		Name:      "Sam",
+		AlertsURL: "https://app.example.com/alerts",
+		Alerts: []models.WatchlistAlert{
+			{EntryLabel: "Work <email>", Breach: "breach_new_2024", BreachDate: breachDate, MatchedFields: []string{"email", "phone"}},
+		},
+	}
+	link := LinkData{Name: "Sam", URL: "https://app.example.com/link", ExpiresIn: "1 hour"}
+	invitation := InvitationData{Organization: "Acme", InvitedBy: "Sam", Role: "analyst", URL: "https://app.example.com/invitations/accept?token=t", ExpiresIn: "7 days"}
+
+	tests := []struct {
+		event       string
+		data        any
+		wantSubject string
+		wantText    string
+	}{
+		{event: EventAlert, data: alerts, wantSubject: "Work <email> was found in a new breach", wantText: "- Work <email>: breach_new_2024 (March 1, 2024), exposed email, phone"},
+		{event: EventDigest, data: alerts, wantSubject: "Your Breach Radar digest: 1 new alert", wantText: "https://app.example.com/alerts"},
+		{event: EventVerifyEmail, data: link, wantSubject: "Confirm your Breach Radar email address", wantText: "https://app.example.com/link"},
+		{event: EventPasswordReset, data: link, wantSubject: "Reset your Breach Radar password", wantText: "expires in 1 hour"},
+		{event: EventInvitation, data: invitation, wantSubject: "Sam invited you to Acme on Breach Radar", wantText: "as analyst"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.event, func(t *testing.T) {
+			msg, err := templates.Render(tt.event, "sam@example.com", tt.data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if msg.Subject != tt.wantSubject {
+				t.Errorf("subject = %q, want %q", msg.Subject, tt.wantSubject)
+			}
+			if !strings.Contains(msg.Text, tt.wantText) {
+				t.Errorf("text = %q, want it to contain %q", msg.Text, tt.wantText)
+			}
+			if msg.HTML == "" || strings.Contains(msg.HTML, "<email>") {
+				t.Errorf("html missing or unescaped: %q", msg.HTML)
+			}
+		})
+	}
+
+	if _, err := templates.Render("unknown", "sam@example.com", nil); err == nil {
+		t.Error("Render() of unknown event succeeded")
+	}
+}
+
+func TestFileNotifier_Send(t *testing.T) {
+	dir := t.TempDir()
+	notifier, err := NewFileNotifier(dir, "alerts@breachradar.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := notifier.Send(context.Background(), Message{To: "a@example.com", Subject: "s" + strconv.Itoa(i), Text: "t"}); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("wrote %d files, want 2", len(entries))
+	}
+}
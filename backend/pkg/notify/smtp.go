@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+const defaultSMTPTimeout = 30 * time.Second
+
+type SMTPConfig struct {
+	Host string
+	Port int
+	// Username and Password are optional. net/smtp only sends them over
+	// TLS or to localhost.
+	Username string
+	Password string
+	From     string
+	// Timeout bounds a whole send; 0 means 30 seconds.
+	Timeout time.Duration
+}
+
+// SMTPNotifier opens a connection per message and upgrades it with
+// STARTTLS whenever the server offers it.
+type SMTPNotifier struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPNotifier(cfg SMTPConfig) *SMTPNotifier {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultSMTPTimeout
+	}
+	return &SMTPNotifier{cfg: cfg}
+}
+
+func (n *SMTPNotifier) Send(ctx context.Context, msg Message) error {
+	body, err := Encode(n.cfg.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error connecting to %s: %w", addr, err)
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error greeting %s: %w", addr, err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.cfg.Host}); err != nil {
+			return fmt.Errorf("error starting TLS with %s: %w", addr, err)
+		}
+	}
+	if n.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return fmt.Errorf("error authenticating with %s: %w", addr, err)
+		}
+	}
+
+	if err := client.Mail(n.cfg.From); err != nil {
+		return fmt.Errorf("error sending MAIL FROM: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("error sending RCPT TO: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error sending DATA: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("error writing message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error finishing message: %w", err)
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,155 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+)
+
+// Events with default templates. Each has a .txt template, which must
+// define "subject", and optionally an .html one.
+const (
+	// EventAlert reports watchlist alerts as they happen.
+	EventAlert = "alert"
+	// EventDigest reports a day's watchlist alerts.
+	EventDigest = "digest"
+	// EventVerifyEmail asks a user to confirm their address.
+	EventVerifyEmail = "verify_email"
+	// EventPasswordReset sends a password reset link.
+	EventPasswordReset = "password_reset"
+	// EventInvitation invites someone to join an organization.
+	EventInvitation = "invitation"
+)
+
+// AlertData is the data of EventAlert and EventDigest.
+type AlertData struct {
+	Name      string
+	Alerts    []models.WatchlistAlert
+	AlertsURL string
+}
+
+// LinkData is the data of EventVerifyEmail and EventPasswordReset.
+type LinkData struct {
+	Name      string
+	URL       string
+	ExpiresIn string
+}
+
+// InvitationData is the data of EventInvitation.
+type InvitationData struct {
+	Organization string
+	InvitedBy    string
+	Role         string
+	URL          string
+	ExpiresIn    string
+}
+
+//go:embed templates
+var defaultTemplates embed.FS
+
+var templateFuncs = map[string]any{
+	"join": strings.Join,
+	"date": func(t time.Time) string { return t.Format("January 2, 2006") },
+}
+
+type Templates struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+// DefaultTemplates returns the templates built into the binary.
+func DefaultTemplates() *Templates {
+	sub, err := fs.Sub(defaultTemplates, "templates")
+	if err != nil {
+		panic(err)
+	}
+	templates, err := LoadTemplates(sub)
+	if err != nil {
+		panic(err)
+	}
+	return templates
+}
+
+// LoadTemplates parses <event>.txt and <event>.html from the root of fsys.
+func LoadTemplates(fsys fs.FS) (*Templates, error) {
+	t := &Templates{
+		text: make(map[string]*texttemplate.Template),
+		html: make(map[string]*htmltemplate.Template),
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error reading templates: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		event := strings.TrimSuffix(name, path.Ext(name))
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("error reading template %s: %w", name, err)
+		}
+
+		switch path.Ext(name) {
+		case ".txt":
+			tmpl, err := texttemplate.New(event).Funcs(templateFuncs).Parse(string(content))
+			if err != nil {
+				return nil, fmt.Errorf("error parsing template %s: %w", name, err)
+			}
+			if tmpl.Lookup("subject") == nil {
+				return nil, fmt.Errorf("template %s does not define a subject", name)
+			}
+			t.text[event] = tmpl
+		case ".html":
+			tmpl, err := htmltemplate.New(event).Funcs(templateFuncs).Parse(string(content))
+			if err != nil {
+				return nil, fmt.Errorf("error parsing template %s: %w", name, err)
+			}
+			t.html[event] = tmpl
+		}
+	}
+
+	for event := range t.html {
+		if t.text[event] == nil {
+			return nil, fmt.Errorf("template %s.html has no text version", event)
+		}
+	}
+	return t, nil
+}
+
+// Render builds the message for event to to.
+func (t *Templates) Render(event, to string, data any) (Message, error) {
+	text := t.text[event]
+	if text == nil {
+		return Message{}, fmt.Errorf("no template for %s", event)
+	}
+
+	var subject, body bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("error rendering %s subject: %w", event, err)
+	}
+	if err := text.Execute(&body, data); err != nil {
+		return Message{}, fmt.Errorf("error rendering %s: %w", event, err)
+	}
+	msg := Message{
+		To:      to,
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(body.String()) + "\n",
+	}
+
+	if html := t.html[event]; html != nil {
+		var htmlBody bytes.Buffer
+		if err := html.Execute(&htmlBody, data); err != nil {
+			return Message{}, fmt.Errorf("error rendering %s html: %w", event, err)
+		}
+		msg.HTML = htmlBody.String()
+	}
+	return msg, nil
+}
@@ -3,17 +3,46 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
 	"github.com/Rikjimue/breach-radar/backend/pkg/models"
 	"github.com/lib/pq"
 )
 
+var ErrBreachNotFound = errors.New("breach not found")
+
 type BreachRepository interface {
 	GetBreachesWithFields(ctx context.Context, fieldNames []string) ([]models.BreachMetadata, error)
-	FindExactMatches(ctx context.Context, breachName string, fieldHashes map[string]string) ([]string, error)
-	FindSensitiveMatches(ctx context.Context, fieldType, partialHash string) (map[string][]string, error)
+	// FindExactMatches reports which fields of breachName hold any of the
+	// given hashes. Each field can carry one hash per active hash scheme.
+	FindExactMatches(ctx context.Context, breachName string, fieldHashes map[string][]string) ([]string, error)
+	// FindRecordMatches returns, for every row of breachName in which at
+	// least two of the given fields match, the set of fields matching in
+	// that row. Identical sets are only returned once.
+	FindRecordMatches(ctx context.Context, breachName string, fieldHashes map[string][]string) ([][]string, error)
+	// FindPresentHashes returns which of hashes occur in the fieldType
+	// column of breachName.
+	FindPresentHashes(ctx context.Context, breachName, fieldType string, hashes []string) ([]string, error)
+	// FindSensitiveMatches returns, per breach source, every full hash that
+	// starts with partialHash and how many times it occurs in that breach.
+	FindSensitiveMatches(ctx context.Context, fieldType, partialHash string) (map[string]map[string]int, error)
+	// BreachesWithEmailDomain returns the names of the breach tables that
+	// store email domain hashes and so can be searched by domain.
+	BreachesWithEmailDomain(ctx context.Context) ([]string, error)
+	// FindDomainAccounts returns up to limit accounts of breachName whose
+	// email domain hash is one of domainHashes, with which of fields each
+	// exposes, and how many accounts there are in total. A non-nil
+	// emailHashes only returns accounts whose email hash is one of them.
+	FindDomainAccounts(ctx context.Context, breachName string, domainHashes, emailHashes, fields []string, limit int) ([]models.DomainAccount, int64, error)
 	GetBreachMetadata(ctx context.Context, breachName string) (*models.BreachMetadata, error)
+	ListBreaches(ctx context.Context, filter models.BreachListFilter) ([]models.BreachMetadata, int, error)
+	GetFieldCoverage(ctx context.Context) ([]models.FieldCoverage, error)
+	GetCatalogStatistics(ctx context.Context, periods StatisticsPeriods) (*CatalogStatistics, error)
+	// ForEachBreach calls fn with the scoring inputs of every breach.
+	ForEachBreach(ctx context.Context, fn func(*models.BreachMetadata) error) error
 }
 
 type SQLBreachRepository struct {
@@ -26,7 +55,7 @@ func NewSQLBreachRepository(db *sql.DB) *SQLBreachRepository {
 
 func (r *SQLBreachRepository) GetBreachesWithFields(ctx context.Context, fieldNames []string) ([]models.BreachMetadata, error) {
 	query := `
-		SELECT name, display_name, breach_date, affected_records, fields
+		SELECT name, display_name, breach_date, affected_records, fields, password_hash_algorithm
 		FROM breach_metadata 
 		WHERE fields && $1
 		ORDER BY breach_date DESC`
@@ -40,56 +69,170 @@ func (r *SQLBreachRepository) GetBreachesWithFields(ctx context.Context, fieldNa
 	var breaches []models.BreachMetadata
 	for rows.Next() {
 		var breach models.BreachMetadata
-		if err := rows.Scan(&breach.Name, &breach.DisplayName, &breach.Date, &breach.AffectedRecords, pq.Array(&breach.Fields)); err != nil {
-			continue
+		if err := rows.Scan(&breach.Name, &breach.DisplayName, &breach.Date, &breach.AffectedRecords, pq.Array(&breach.Fields), &breach.PasswordHashAlgorithm); err != nil {
+			return nil, fmt.Errorf("error scanning breach metadata: %w", err)
 		}
 		breaches = append(breaches, breach)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying breach metadata: %w", err)
+	}
 
 	return breaches, nil
 }
 
-func (r *SQLBreachRepository) GetBreachMetadata(ctx context.Context, breachName string) (*models.BreachMetadata, error) {
-	query := `SELECT name, display_name, breach_date, affected_records FROM breach_metadata WHERE name = $1`
+const breachMetadataColumns = `id, name, display_name, breach_date, affected_records, fields, source_url, industry, region, password_hash_algorithm`
 
+func scanBreachMetadata(row interface{ Scan(...any) error }) (*models.BreachMetadata, error) {
 	var metadata models.BreachMetadata
-	err := r.db.QueryRowContext(ctx, query, breachName).Scan(
+	err := row.Scan(
+		&metadata.ID,
 		&metadata.Name,
-		&metadata.DisplayName, // Added display_name
+		&metadata.DisplayName,
 		&metadata.Date,
 		&metadata.AffectedRecords,
+		pq.Array(&metadata.Fields),
+		&metadata.SourceURL,
+		&metadata.Industry,
+		&metadata.Region,
+		&metadata.PasswordHashAlgorithm,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+func (r *SQLBreachRepository) GetBreachMetadata(ctx context.Context, breachName string) (*models.BreachMetadata, error) {
+	query := `SELECT ` + breachMetadataColumns + ` FROM breach_metadata WHERE name = $1`
+
+	metadata, err := scanBreachMetadata(r.db.QueryRowContext(ctx, query, breachName))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrBreachNotFound, breachName)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error getting breach metadata for %s: %w", breachName, err)
 	}
 
-	return &metadata, nil
+	return metadata, nil
 }
 
-func (r *SQLBreachRepository) FindExactMatches(ctx context.Context, breachName string, fieldHashes map[string]string) ([]string, error) {
-	var matchedFields []string
+func (r *SQLBreachRepository) ListBreaches(ctx context.Context, filter models.BreachListFilter) ([]models.BreachMetadata, int, error) {
+	where := `
+		WHERE ($1 = '' OR LOWER(industry) = LOWER($1))
+		  AND ($2 = '' OR $2 = ANY(fields))
+		  AND ($3::date IS NULL OR breach_date >= $3)
+		  AND ($4::date IS NULL OR breach_date <= $4)`
+	args := []interface{}{filter.Industry, filter.FieldType, filter.From, filter.To}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM breach_metadata`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error counting breaches: %w", err)
+	}
+
+	orderColumn := "breach_date"
+	if filter.SortBy == "size" {
+		orderColumn = "affected_records"
+	}
+	direction := "DESC"
+	if filter.Ascending {
+		direction = "ASC"
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM breach_metadata %s ORDER BY %s %s, name LIMIT NULLIF($5, 0) OFFSET $6`,
+		breachMetadataColumns, where, orderColumn, direction)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing breaches: %w", err)
+	}
+	defer rows.Close()
+
+	breaches := []models.BreachMetadata{}
+	for rows.Next() {
+		metadata, err := scanBreachMetadata(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error scanning breach metadata: %w", err)
+		}
+		breaches = append(breaches, *metadata)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error listing breaches: %w", err)
+	}
+
+	return breaches, total, nil
+}
+
+func (r *SQLBreachRepository) GetFieldCoverage(ctx context.Context) ([]models.FieldCoverage, error) {
+	query := `
+		SELECT field, COUNT(*)
+		FROM breach_metadata, unnest(fields) AS field
+		GROUP BY field
+		ORDER BY field`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting field coverage: %w", err)
+	}
+	defer rows.Close()
+
+	coverage := []models.FieldCoverage{}
+	for rows.Next() {
+		var c models.FieldCoverage
+		if err := rows.Scan(&c.Field, &c.Breaches); err != nil {
+			return nil, fmt.Errorf("error scanning field coverage: %w", err)
+		}
+		coverage = append(coverage, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting field coverage: %w", err)
+	}
+
+	return coverage, nil
+}
 
-	for fieldType, fullHash := range fieldHashes {
+// FindExactMatches checks every requested field against the breach table in a
+// single round trip. Each column gets its own EXISTS so that per-column
+// indexes can be used.
+func (r *SQLBreachRepository) FindExactMatches(ctx context.Context, breachName string, fieldHashes map[string][]string) ([]string, error) {
+	var fieldTypes []string
+	var checks []string
+	var args []interface{}
+
+	for fieldType, hashes := range fieldHashes {
 		columnName := r.getColumnName(fieldType)
-		if columnName == "" {
+		if columnName == "" || len(hashes) == 0 {
 			continue
 		}
 
-		query := fmt.Sprintf(`
-			SELECT COUNT(*) 
-			FROM %s 
-			WHERE %s = $1`,
+		args = append(args, pq.Array(hashes))
+		checks = append(checks, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM %s WHERE %s = ANY($%d))",
 			pq.QuoteIdentifier(breachName),
 			pq.QuoteIdentifier(columnName),
-		)
+			len(args),
+		))
+		fieldTypes = append(fieldTypes, fieldType)
+	}
 
-		var count int
-		err := r.db.QueryRowContext(ctx, query, fullHash).Scan(&count)
-		if err != nil {
-			continue
-		}
+	if len(checks) == 0 {
+		return nil, nil
+	}
+
+	found := make([]bool, len(checks))
+	dest := make([]interface{}, len(checks))
+	for i := range found {
+		dest[i] = &found[i]
+	}
 
-		if count > 0 {
+	query := "SELECT " + strings.Join(checks, ", ")
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(dest...); err != nil {
+		return nil, fmt.Errorf("error querying breach table %s: %w", breachName, err)
+	}
+
+	var matchedFields []string
+	for i, fieldType := range fieldTypes {
+		if found[i] {
 			matchedFields = append(matchedFields, fieldType)
 		}
 	}
@@ -97,71 +240,297 @@ func (r *SQLBreachRepository) FindExactMatches(ctx context.Context, breachName s
 	return matchedFields, nil
 }
 
-func (r *SQLBreachRepository) FindSensitiveMatches(ctx context.Context, fieldType, partialHash string) (map[string][]string, error) {
+// FindRecordMatches only reads rows matching at least one field, so the
+// per-column indexes narrow the scan before the fields of each row are
+// compared.
+func (r *SQLBreachRepository) FindRecordMatches(ctx context.Context, breachName string, fieldHashes map[string][]string) ([][]string, error) {
+	var checks []string
+	var args []interface{}
+
+	for fieldType, hashes := range fieldHashes {
+		columnName := r.getColumnName(fieldType)
+		if columnName == "" || len(hashes) == 0 {
+			continue
+		}
+
+		args = append(args, pq.Array(hashes), fieldType)
+		checks = append(checks, fmt.Sprintf("%s = ANY($%d)", pq.QuoteIdentifier(columnName), len(args)-1))
+	}
+
+	if len(checks) < 2 {
+		return nil, nil
+	}
+
+	labels := make([]string, len(checks))
+	for i, check := range checks {
+		labels[i] = fmt.Sprintf("CASE WHEN %s THEN $%d::text END", check, 2*i+2)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT DISTINCT matched
+		FROM (
+			SELECT array_remove(ARRAY[%s], NULL) AS matched
+			FROM %s
+			WHERE %s
+		) rows
+		WHERE cardinality(matched) >= 2`,
+		strings.Join(labels, ", "),
+		pq.QuoteIdentifier(breachName),
+		strings.Join(checks, " OR "),
+	)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error correlating records in %s: %w", breachName, err)
+	}
+	defer rows.Close()
+
+	var records [][]string
+	for rows.Next() {
+		var fields []string
+		if err := rows.Scan(pq.Array(&fields)); err != nil {
+			return nil, fmt.Errorf("error scanning correlated record in %s: %w", breachName, err)
+		}
+		records = append(records, fields)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error correlating records in %s: %w", breachName, err)
+	}
+
+	return records, nil
+}
+
+func (r *SQLBreachRepository) FindPresentHashes(ctx context.Context, breachName, fieldType string, hashes []string) ([]string, error) {
+	columnName := r.getColumnName(fieldType)
+	if columnName == "" {
+		return nil, fmt.Errorf("unsupported field type: %s", fieldType)
+	}
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`SELECT DISTINCT %s FROM %s WHERE %s = ANY($1)`,
+		pq.QuoteIdentifier(columnName),
+		pq.QuoteIdentifier(breachName),
+		pq.QuoteIdentifier(columnName),
+	)
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(hashes))
+	if err != nil {
+		return nil, fmt.Errorf("error querying breach table %s: %w", breachName, err)
+	}
+	defer rows.Close()
+
+	var present []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("error scanning breach table %s: %w", breachName, err)
+		}
+		present = append(present, hash)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying breach table %s: %w", breachName, err)
+	}
+
+	return present, nil
+}
+
+func (r *SQLBreachRepository) BreachesWithEmailDomain(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT bm.name
+		FROM breach_metadata bm
+		JOIN information_schema.columns c ON c.table_name = bm.name
+		WHERE c.table_schema = 'public' AND c.column_name = $1
+		ORDER BY bm.name`
+
+	rows, err := r.db.QueryContext(ctx, query, EmailDomainColumn)
+	if err != nil {
+		return nil, fmt.Errorf("error listing breaches with email domains: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("error scanning breach name: %w", err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing breaches with email domains: %w", err)
+	}
+
+	return names, nil
+}
+
+func (r *SQLBreachRepository) FindDomainAccounts(ctx context.Context, breachName string, domainHashes, emailHashes, fields []string, limit int) ([]models.DomainAccount, int64, error) {
+	var exposed []string
+	for _, fieldType := range fields {
+		column := r.getColumnName(fieldType)
+		if column == "" {
+			continue
+		}
+		exposed = append(exposed, fmt.Sprintf(`CASE WHEN %s IS NOT NULL THEN %s END`,
+			pq.QuoteIdentifier(column), pq.QuoteLiteral(fieldType)))
+	}
+	exposedExpr := `ARRAY[]::text[]`
+	if len(exposed) > 0 {
+		exposedExpr = fmt.Sprintf(`array_remove(ARRAY[%s]::text[], NULL)`, strings.Join(exposed, ", "))
+	}
+
+	emailColumn := pq.QuoteIdentifier(r.getColumnName("email"))
+	emailFilter := emailColumn + ` IS NOT NULL`
+	args := []any{pq.Array(domainHashes), limit}
+	if emailHashes != nil {
+		emailFilter = emailColumn + ` = ANY($3)`
+		args = append(args, pq.Array(emailHashes))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s, hash_version, %s, COUNT(*) OVER ()
+		FROM %s
+		WHERE %s = ANY($1) AND %s
+		ORDER BY id
+		LIMIT $2`,
+		emailColumn,
+		exposedExpr,
+		pq.QuoteIdentifier(breachName),
+		pq.QuoteIdentifier(EmailDomainColumn),
+		emailFilter,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying domain accounts in %s: %w", breachName, err)
+	}
+	defer rows.Close()
+
+	var total int64
+	accounts := []models.DomainAccount{}
+	for rows.Next() {
+		var account models.DomainAccount
+		if err := rows.Scan(&account.EmailHash, &account.HashVersion, pq.Array(&account.ExposedFields), &total); err != nil {
+			return nil, 0, fmt.Errorf("error scanning domain account in %s: %w", breachName, err)
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error querying domain accounts in %s: %w", breachName, err)
+	}
+
+	return accounts, total, nil
+}
+
+func (r *SQLBreachRepository) FindSensitiveMatches(ctx context.Context, fieldType, partialHash string) (map[string]map[string]int, error) {
 	tableName := r.getSensitiveTableName(fieldType)
 	if tableName == "" {
 		return nil, fmt.Errorf("unsupported sensitive field type: %s", fieldType)
 	}
+	// The prefix column is only MaxPartialHashLength long, and the LIKE
+	// pattern must not carry wildcards
+	if hashing.ValidatePartialHashLength(len(partialHash)) != nil || hashing.ValidatePartialHash(partialHash, len(partialHash)) != nil {
+		return nil, fmt.Errorf("invalid partial hash for %s", fieldType)
+	}
 
-	columnName := fieldType + "_hash"
+	columnName := SensitiveHashColumn(fieldType)
 
+	// Rows loaded before occurrence counts were stored once per record, so
+	// sum rather than trust a single row
 	query := fmt.Sprintf(`
-		SELECT breach_source, %s
-		FROM %s 
-		WHERE LEFT(%s, 6) = $1
-		ORDER BY breach_source, %s`,
+		SELECT breach_source, %s, SUM(occurrences)
+		FROM %s
+		WHERE %s LIKE $1
+		GROUP BY breach_source, %s`,
 		pq.QuoteIdentifier(columnName),
 		pq.QuoteIdentifier(tableName),
-		pq.QuoteIdentifier(columnName),
+		pq.QuoteIdentifier(SensitivePrefixColumn(fieldType)),
 		pq.QuoteIdentifier(columnName),
 	)
 
-	rows, err := r.db.QueryContext(ctx, query, partialHash)
+	rows, err := r.db.QueryContext(ctx, query, partialHash+"%")
 	if err != nil {
 		return nil, fmt.Errorf("error querying sensitive data table %s: %w", tableName, err)
 	}
 	defer rows.Close()
 
-	breachCandidates := make(map[string][]string)
+	breachCandidates := make(map[string]map[string]int)
 	for rows.Next() {
 		var breachSource, fullHash string
-		if err := rows.Scan(&breachSource, &fullHash); err != nil {
-			continue
+		var occurrences int
+		if err := rows.Scan(&breachSource, &fullHash, &occurrences); err != nil {
+			return nil, fmt.Errorf("error scanning sensitive data table %s: %w", tableName, err)
+		}
+		if breachCandidates[breachSource] == nil {
+			breachCandidates[breachSource] = make(map[string]int)
 		}
-		breachCandidates[breachSource] = append(breachCandidates[breachSource], fullHash)
+		breachCandidates[breachSource][fullHash] = occurrences
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying sensitive data table %s: %w", tableName, err)
 	}
 
 	return breachCandidates, nil
 }
 
-func (r *SQLBreachRepository) getSensitiveTableName(fieldType string) string {
-	sensitiveTableMap := map[string]string{
-		"ssn":           "breach_ssn_data",
-		"creditCard":    "breach_credit_card_data",
-		"driverLicense": "breach_license_data",
-		"passport":      "breach_passport_data",
-		"password":      "breach_password_data",
-	}
+// Tables holding hashes of sensitive fields, keyed by field type. Each has a
+// breach_source column, a "<fieldType>_hash" column and an occurrences
+// column.
+var sensitiveTableMap = map[string]string{
+	"ssn":           "breach_ssn_data",
+	"creditCard":    "breach_credit_card_data",
+	"driverLicense": "breach_license_data",
+	"passport":      "breach_passport_data",
+	"password":      "breach_password_data",
+}
+
+// Columns of the per-breach tables, keyed by field type.
+var columnMap = map[string]string{
+	"email":       "email",
+	"firstName":   "first_name",
+	"lastName":    "last_name",
+	"phone":       "phone",
+	"username":    "username",
+	"address":     "address",
+	"city":        "city",
+	"state":       "state",
+	"zipCode":     "zip_code",
+	"country":     "country",
+	"dateOfBirth": "date_of_birth",
+}
+
+// SensitiveTable returns the shared table for a sensitive field type, or ""
+// if fieldType is not sensitive.
+func SensitiveTable(fieldType string) string {
 	return sensitiveTableMap[fieldType]
 }
 
-func (r *SQLBreachRepository) getColumnName(fieldType string) string {
-	columnMap := map[string]string{
-		"email":       "email",
-		"firstName":   "first_name",
-		"lastName":    "last_name",
-		"phone":       "phone",
-		"username":    "username",
-		"address":     "address",
-		"city":        "city",
-		"state":       "state",
-		"zipCode":     "zip_code",
-		"country":     "country",
-		"dateOfBirth": "date_of_birth",
-	}
+// SensitiveHashColumn returns the hash column of a sensitive table.
+func SensitiveHashColumn(fieldType string) string {
+	return fieldType + "_hash"
+}
+
+// SensitivePrefixColumn returns the generated hash prefix column of a
+// sensitive table.
+func SensitivePrefixColumn(fieldType string) string {
+	return fieldType + "_prefix"
+}
+
+// PersonalColumn returns the per-breach table column for a personal field
+// type, or "" if fieldType is not a personal field.
+func PersonalColumn(fieldType string) string {
 	return columnMap[fieldType]
 }
 
+func (r *SQLBreachRepository) getSensitiveTableName(fieldType string) string {
+	return SensitiveTable(fieldType)
+}
+
+func (r *SQLBreachRepository) getColumnName(fieldType string) string {
+	return PersonalColumn(fieldType)
+}
+
 func (r *SQLBreachRepository) CheckTableExists(ctx context.Context, tableName string) (bool, error) {
 	query := `
 		SELECT EXISTS (
@@ -195,10 +564,13 @@ func (r *SQLBreachRepository) GetAvailableFields(ctx context.Context) ([]string,
 	for rows.Next() {
 		var field string
 		if err := rows.Scan(&field); err != nil {
-			continue
+			return nil, fmt.Errorf("error scanning available fields: %w", err)
 		}
 		fields = append(fields, field)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting available fields: %w", err)
+	}
 
 	return fields, nil
 }
@@ -214,3 +586,26 @@ func (r *SQLBreachRepository) GetBreachCount(ctx context.Context) (int, error) {
 
 	return count, nil
 }
+
+// ListBreachNames returns the table name of every registered breach.
+func (r *SQLBreachRepository) ListBreachNames(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT name FROM breach_metadata ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("error listing breaches: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("error scanning breach name: %w", err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing breaches: %w", err)
+	}
+
+	return names, nil
+}
@@ -66,15 +66,15 @@ func TestMockBreachRepository_FindExactMatches(t *testing.T) {
 	tests := []struct {
 		name           string
 		breachName     string
-		fieldHashes    map[string]string
+		fieldHashes    map[string][]string
 		expectedLen    int
 		expectedFields []string
 	}{
 		{
 			name:       "matching email",
 			breachName: "breach_linkedin_2021",
-			fieldHashes: map[string]string{
-				"email": "a1b2c3d4e5f6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
+			fieldHashes: map[string][]string{
+				"email": {"a1b2c3d4e5f6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890"},
 			},
 			expectedLen:    1,
 			expectedFields: []string{"email"},
@@ -82,9 +82,9 @@ func TestMockBreachRepository_FindExactMatches(t *testing.T) {
 		{
 			name:       "multiple matching fields",
 			breachName: "breach_linkedin_2021",
-			fieldHashes: map[string]string{
-				"email":     "a1b2c3d4e5f6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
-				"firstName": "f1e2d3c4b5a6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
+			fieldHashes: map[string][]string{
+				"email":     {"a1b2c3d4e5f6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890"},
+				"firstName": {"f1e2d3c4b5a6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890"},
 			},
 			expectedLen:    2,
 			expectedFields: []string{"email", "firstName"},
@@ -92,8 +92,8 @@ func TestMockBreachRepository_FindExactMatches(t *testing.T) {
 		{
 			name:       "non-matching hash",
 			breachName: "breach_linkedin_2021",
-			fieldHashes: map[string]string{
-				"email": "wronghash123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
+			fieldHashes: map[string][]string{
+				"email": {"wronghash123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890"},
 			},
 			expectedLen:    0,
 			expectedFields: []string{},
@@ -101,8 +101,8 @@ func TestMockBreachRepository_FindExactMatches(t *testing.T) {
 		{
 			name:       "nonexistent breach",
 			breachName: "nonexistent_breach",
-			fieldHashes: map[string]string{
-				"email": "a1b2c3d4e5f6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
+			fieldHashes: map[string][]string{
+				"email": {"a1b2c3d4e5f6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890"},
 			},
 			expectedLen:    0,
 			expectedFields: []string{},
@@ -258,9 +258,9 @@ func BenchmarkMockRepository_GetBreachesWithFields(b *testing.B) {
 func BenchmarkMockRepository_FindExactMatches(b *testing.B) {
 	repo := NewMockBreachRepository()
 	ctx := context.Background()
-	fieldHashes := map[string]string{
-		"email":     "a1b2c3d4e5f6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
-		"firstName": "f1e2d3c4b5a6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
+	fieldHashes := map[string][]string{
+		"email":     {"a1b2c3d4e5f6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890"},
+		"firstName": {"f1e2d3c4b5a6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890"},
 	}
 
 	b.ResetTimer()
@@ -304,9 +304,9 @@ func TestRepositoryIntegration(t *testing.T) {
 			t.Fatal("expected at least one breach")
 		}
 
-		fieldHashes := map[string]string{
-			"email":     "a1b2c3d4e5f6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
-			"firstName": "f1e2d3c4b5a6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
+		fieldHashes := map[string][]string{
+			"email":     {"a1b2c3d4e5f6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890"},
+			"firstName": {"f1e2d3c4b5a6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890"},
 		}
 
 		for _, breach := range breaches {
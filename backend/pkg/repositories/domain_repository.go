@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrDomainClaimNotFound = errors.New("domain claim not found")
+	ErrDomainClaimed       = errors.New("domain already claimed")
+	ErrDomainVerified      = errors.New("domain verified by another organization")
+)
+
+type DomainRepository interface {
+	CreateClaim(ctx context.Context, claim *models.DomainClaim) error
+	ListClaims(ctx context.Context, orgID uint64) ([]models.DomainClaim, error)
+	GetClaim(ctx context.Context, orgID, claimID uint64) (*models.DomainClaim, error)
+	// MarkVerified fails with ErrDomainVerified when another organization
+	// holds the domain verified already.
+	MarkVerified(ctx context.Context, claimID uint64, method string, at time.Time) error
+	DeleteClaim(ctx context.Context, orgID, claimID uint64) error
+}
+
+type SQLDomainRepository struct {
+	db *sql.DB
+}
+
+func NewSQLDomainRepository(db *sql.DB) *SQLDomainRepository {
+	return &SQLDomainRepository{db: db}
+}
+
+const domainClaimColumns = `id, org_id, domain, token, COALESCE(verification_method, ''), verified_at, created_at`
+
+func scanDomainClaim(row interface{ Scan(...any) error }) (*models.DomainClaim, error) {
+	var claim models.DomainClaim
+	err := row.Scan(
+		&claim.ID,
+		&claim.OrgID,
+		&claim.Domain,
+		&claim.Token,
+		&claim.VerificationMethod,
+		&claim.VerifiedAt,
+		&claim.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+func (r *SQLDomainRepository) CreateClaim(ctx context.Context, claim *models.DomainClaim) error {
+	query := `
+		INSERT INTO domain_claims (org_id, domain, token)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRowContext(ctx, query, claim.OrgID, claim.Domain, claim.Token).Scan(&claim.ID, &claim.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrDomainClaimed
+		}
+		return fmt.Errorf("error creating domain claim: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLDomainRepository) ListClaims(ctx context.Context, orgID uint64) ([]models.DomainClaim, error) {
+	query := `SELECT ` + domainClaimColumns + ` FROM domain_claims WHERE org_id = $1 ORDER BY domain`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing domain claims: %w", err)
+	}
+	defer rows.Close()
+
+	claims := []models.DomainClaim{}
+	for rows.Next() {
+		claim, err := scanDomainClaim(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning domain claim: %w", err)
+		}
+		claims = append(claims, *claim)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing domain claims: %w", err)
+	}
+
+	return claims, nil
+}
+
+func (r *SQLDomainRepository) GetClaim(ctx context.Context, orgID, claimID uint64) (*models.DomainClaim, error) {
+	query := `SELECT ` + domainClaimColumns + ` FROM domain_claims WHERE id = $1 AND org_id = $2`
+
+	claim, err := scanDomainClaim(r.db.QueryRowContext(ctx, query, claimID, orgID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDomainClaimNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting domain claim %d: %w", claimID, err)
+	}
+
+	return claim, nil
+}
+
+func (r *SQLDomainRepository) MarkVerified(ctx context.Context, claimID uint64, method string, at time.Time) error {
+	query := `UPDATE domain_claims SET verification_method = $2, verified_at = $3 WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, claimID, method, at)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrDomainVerified
+		}
+		return fmt.Errorf("error verifying domain claim %d: %w", claimID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verifying domain claim %d: %w", claimID, err)
+	}
+	if affected == 0 {
+		return ErrDomainClaimNotFound
+	}
+
+	return nil
+}
+
+func (r *SQLDomainRepository) DeleteClaim(ctx context.Context, orgID, claimID uint64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM domain_claims WHERE id = $1 AND org_id = $2`, claimID, orgID)
+	if err != nil {
+		return fmt.Errorf("error deleting domain claim %d: %w", claimID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting domain claim %d: %w", claimID, err)
+	}
+	if affected == 0 {
+		return ErrDomainClaimNotFound
+	}
+
+	return nil
+}
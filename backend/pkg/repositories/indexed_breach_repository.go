@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/lib/pq"
+)
+
+// BreachHashIndex is implemented by repositories that can find every breach
+// containing a set of hashes in one lookup, instead of querying each breach
+// table in turn.
+type BreachHashIndex interface {
+	FindBreachesByHashes(ctx context.Context, fieldHashes map[string][]string) ([]models.BreachFieldMatch, error)
+	// UnindexedBreaches returns the breaches holding any of fieldTypes that
+	// are not in the index, so searches can report them as unchecked.
+	UnindexedBreaches(ctx context.Context, fieldTypes []string) ([]models.BreachMetadata, error)
+}
+
+// IndexedBreachRepository answers personal searches from the global
+// breach_index table. Everything else is served by the per-breach tables.
+type IndexedBreachRepository struct {
+	*SQLBreachRepository
+}
+
+func NewIndexedBreachRepository(db *sql.DB) *IndexedBreachRepository {
+	return &IndexedBreachRepository{SQLBreachRepository: NewSQLBreachRepository(db)}
+}
+
+func (r *IndexedBreachRepository) FindBreachesByHashes(ctx context.Context, fieldHashes map[string][]string) ([]models.BreachFieldMatch, error) {
+	var fieldTypes, hashes []string
+	for fieldType, candidates := range fieldHashes {
+		if r.getColumnName(fieldType) == "" {
+			continue
+		}
+		for _, hash := range candidates {
+			fieldTypes = append(fieldTypes, fieldType)
+			hashes = append(hashes, hash)
+		}
+	}
+	if len(fieldTypes) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT bm.name, bm.display_name, bm.breach_date, bm.affected_records, bm.fields, bm.password_hash_algorithm,
+		       array_agg(DISTINCT bi.field_type)
+		FROM breach_index bi
+		JOIN breach_metadata bm ON bm.id = bi.breach_id
+		WHERE (bi.field_type, bi.hash) IN (SELECT * FROM unnest($1::text[], $2::text[]))
+		GROUP BY bm.id
+		ORDER BY bm.breach_date DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(fieldTypes), pq.Array(hashes))
+	if err != nil {
+		return nil, fmt.Errorf("error querying breach index: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.BreachFieldMatch
+	for rows.Next() {
+		var match models.BreachFieldMatch
+		err := rows.Scan(
+			&match.Breach.Name,
+			&match.Breach.DisplayName,
+			&match.Breach.Date,
+			&match.Breach.AffectedRecords,
+			pq.Array(&match.Breach.Fields),
+			&match.Breach.PasswordHashAlgorithm,
+			pq.Array(&match.MatchedFields),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning breach index match: %w", err)
+		}
+		matches = append(matches, match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying breach index: %w", err)
+	}
+
+	return matches, nil
+}
+
+func (r *IndexedBreachRepository) UnindexedBreaches(ctx context.Context, fieldTypes []string) ([]models.BreachMetadata, error) {
+	query := `
+		SELECT name, display_name, breach_date, affected_records, fields, password_hash_algorithm
+		FROM breach_metadata
+		WHERE fields && $1 AND indexed_at IS NULL
+		ORDER BY breach_date DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(fieldTypes))
+	if err != nil {
+		return nil, fmt.Errorf("error querying unindexed breaches: %w", err)
+	}
+	defer rows.Close()
+
+	var breaches []models.BreachMetadata
+	for rows.Next() {
+		var breach models.BreachMetadata
+		if err := rows.Scan(&breach.Name, &breach.DisplayName, &breach.Date, &breach.AffectedRecords, pq.Array(&breach.Fields), &breach.PasswordHashAlgorithm); err != nil {
+			return nil, fmt.Errorf("error scanning breach metadata: %w", err)
+		}
+		breaches = append(breaches, breach)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying unindexed breaches: %w", err)
+	}
+
+	return breaches, nil
+}
+
+// RebuildIndex replaces the index entries of one breach with the distinct
+// hashes currently in its table. It runs in a single transaction so searches
+// never see a half-built breach.
+func (r *IndexedBreachRepository) RebuildIndex(ctx context.Context, breachName string) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting index rebuild for %s: %w", breachName, err)
+	}
+	defer tx.Rollback()
+
+	var breachID uint64
+	var fields []string
+	err = tx.QueryRowContext(ctx, `SELECT id, fields FROM breach_metadata WHERE name = $1 FOR UPDATE`, breachName).
+		Scan(&breachID, pq.Array(&fields))
+	if err != nil {
+		return 0, fmt.Errorf("error getting breach metadata for %s: %w", breachName, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM breach_index WHERE breach_id = $1`, breachID); err != nil {
+		return 0, fmt.Errorf("error clearing index for %s: %w", breachName, err)
+	}
+
+	// Tables loaded before hash versions that cmd/rehash has not prepared
+	// yet hold v1 hashes only.
+	versionColumn := "1"
+	versioned, err := hasColumn(ctx, tx, breachName, "hash_version")
+	if err != nil {
+		return 0, fmt.Errorf("error checking hash versions of %s: %w", breachName, err)
+	}
+	if versioned {
+		versionColumn = "hash_version"
+	}
+
+	var total int64
+	for _, fieldType := range fields {
+		columnName := r.getColumnName(fieldType)
+		if columnName == "" {
+			continue
+		}
+
+		query := fmt.Sprintf(`
+			INSERT INTO breach_index (field_type, hash, breach_id, hash_version)
+			SELECT DISTINCT $1, %s, $2::bigint, %s
+			FROM %s
+			WHERE %s IS NOT NULL
+			ON CONFLICT DO NOTHING`,
+			pq.QuoteIdentifier(columnName),
+			versionColumn,
+			pq.QuoteIdentifier(breachName),
+			pq.QuoteIdentifier(columnName),
+		)
+		result, err := tx.ExecContext(ctx, query, fieldType, breachID)
+		if err != nil {
+			return 0, fmt.Errorf("error indexing %s.%s: %w", breachName, columnName, err)
+		}
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("error indexing %s.%s: %w", breachName, columnName, err)
+		}
+		total += inserted
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE breach_metadata SET indexed_at = now() WHERE id = $1`, breachID); err != nil {
+		return 0, fmt.Errorf("error marking %s indexed: %w", breachName, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing index rebuild for %s: %w", breachName, err)
+	}
+
+	return total, nil
+}
@@ -0,0 +1,423 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/lib/pq"
+)
+
+const ingestStagingTable = "ingest_staging"
+
+// EmailDomainField is the record key of the email domain hash. Breaches
+// with emails store it in EmailDomainColumn so verified domains can be
+// searched. It shares the row's hash_version and is rehashed with it.
+const (
+	EmailDomainField  = "emailDomain"
+	EmailDomainColumn = "email_domain"
+)
+
+// Breach names double as table names, so keep them to plain identifiers.
+var breachNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+type IngestRepository struct {
+	db *sql.DB
+}
+
+func NewIngestRepository(db *sql.DB) *IngestRepository {
+	return &IngestRepository{db: db}
+}
+
+// LoadBreach creates the per-breach table for metadata.Name, fills it and
+// the shared sensitive tables from next, and registers the breach in
+// breach_metadata, all in one transaction. next returns one record of hashed
+// values keyed by field type (missing or "" values are stored as NULL) and
+// io.EOF at the end. Personal fields must be hashed with hashVersion,
+// sensitive fields always with v1. When emails are loaded, records should
+// also carry EmailDomainField. metadata.Fields lists the field types to
+// load; when metadata.AffectedRecords is 0 it is set to the number of
+// records read.
+func (r *IngestRepository) LoadBreach(ctx context.Context, metadata *models.BreachMetadata, hashVersion int, next func() (map[string]string, error)) error {
+	if !breachNamePattern.MatchString(metadata.Name) {
+		return fmt.Errorf("invalid breach name %q: use lowercase letters, digits and underscores", metadata.Name)
+	}
+
+	var personal, sensitive []string
+	for _, fieldType := range metadata.Fields {
+		switch {
+		case PersonalColumn(fieldType) != "":
+			personal = append(personal, fieldType)
+		case SensitiveTable(fieldType) != "":
+			sensitive = append(sensitive, fieldType)
+		default:
+			return fmt.Errorf("unsupported field type: %s", fieldType)
+		}
+	}
+	if len(personal)+len(sensitive) == 0 {
+		return errors.New("no fields to load")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting ingest transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Stage every record once, then fan it out to the breach table and the
+	// sensitive tables. Only one COPY can be active per connection.
+	fields := append(append([]string{}, personal...), sensitive...)
+	if containsString(personal, "email") {
+		fields = append(fields, EmailDomainField)
+	}
+	stagingColumns := make([]string, len(fields))
+	for i, fieldType := range fields {
+		stagingColumns[i] = pq.QuoteIdentifier(fieldType) + " TEXT"
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`CREATE TEMP TABLE %s (%s) ON COMMIT DROP`,
+		ingestStagingTable, strings.Join(stagingColumns, ", ")))
+	if err != nil {
+		return fmt.Errorf("error creating staging table: %w", err)
+	}
+
+	count, err := copyRecords(ctx, tx, fields, next)
+	if err != nil {
+		return err
+	}
+
+	if err := createBreachTable(ctx, tx, metadata.Name, personal, hashVersion); err != nil {
+		return err
+	}
+
+	for _, fieldType := range sensitive {
+		// One row per distinct hash; repeats within the breach are counted
+		query := fmt.Sprintf(`
+			INSERT INTO %s (breach_source, %s, occurrences)
+			SELECT $1, %s, COUNT(*) FROM %s WHERE %s IS NOT NULL GROUP BY %s`,
+			pq.QuoteIdentifier(SensitiveTable(fieldType)),
+			pq.QuoteIdentifier(SensitiveHashColumn(fieldType)),
+			pq.QuoteIdentifier(fieldType),
+			ingestStagingTable,
+			pq.QuoteIdentifier(fieldType),
+			pq.QuoteIdentifier(fieldType),
+		)
+		if _, err := tx.ExecContext(ctx, query, metadata.Name); err != nil {
+			return fmt.Errorf("error loading %s hashes: %w", fieldType, err)
+		}
+	}
+
+	if metadata.AffectedRecords == 0 {
+		metadata.AffectedRecords = count
+	}
+
+	query := `
+		INSERT INTO breach_metadata (name, display_name, breach_date, affected_records, fields, source_url, industry, region, password_hash_algorithm)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+	err = tx.QueryRowContext(ctx, query,
+		metadata.Name,
+		metadata.DisplayName,
+		metadata.Date,
+		metadata.AffectedRecords,
+		pq.Array(metadata.Fields),
+		metadata.SourceURL,
+		metadata.Industry,
+		metadata.Region,
+		metadata.PasswordHashAlgorithm,
+	).Scan(&metadata.ID)
+	if err != nil {
+		return fmt.Errorf("error registering breach metadata: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing ingest of %s: %w", metadata.Name, err)
+	}
+
+	return nil
+}
+
+func copyRecords(ctx context.Context, tx *sql.Tx, fields []string, next func() (map[string]string, error)) (int64, error) {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(ingestStagingTable, fields...))
+	if err != nil {
+		return 0, fmt.Errorf("error starting copy: %w", err)
+	}
+	defer stmt.Close()
+
+	var count int64
+	values := make([]interface{}, len(fields))
+	for {
+		record, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error reading record %d: %w", count+1, err)
+		}
+
+		for i, fieldType := range fields {
+			if value := record[fieldType]; value != "" {
+				values[i] = value
+			} else {
+				values[i] = nil
+			}
+		}
+		if _, err := stmt.ExecContext(ctx, values...); err != nil {
+			return 0, fmt.Errorf("error copying record %d: %w", count+1, err)
+		}
+		count++
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return 0, fmt.Errorf("error finishing copy: %w", err)
+	}
+
+	return count, nil
+}
+
+func createBreachTable(ctx context.Context, tx *sql.Tx, breachName string, personal []string, hashVersion int) error {
+	columns := []string{"id BIGSERIAL PRIMARY KEY", "hash_version SMALLINT NOT NULL"}
+	var targets, sources, indexed []string
+	for _, fieldType := range personal {
+		column := pq.QuoteIdentifier(PersonalColumn(fieldType))
+		columns = append(columns, column+" TEXT")
+		targets = append(targets, column)
+		sources = append(sources, pq.QuoteIdentifier(fieldType))
+		indexed = append(indexed, PersonalColumn(fieldType))
+	}
+	if containsString(personal, "email") {
+		columns = append(columns, pq.QuoteIdentifier(EmailDomainColumn)+" TEXT")
+		targets = append(targets, pq.QuoteIdentifier(EmailDomainColumn))
+		sources = append(sources, pq.QuoteIdentifier(EmailDomainField))
+		indexed = append(indexed, EmailDomainColumn)
+	}
+
+	table := pq.QuoteIdentifier(breachName)
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE %s (%s)`, table, strings.Join(columns, ", "))); err != nil {
+		return fmt.Errorf("error creating breach table %s: %w", breachName, err)
+	}
+	if len(personal) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (hash_version, %s) SELECT $1, %s FROM %s`,
+		table, strings.Join(targets, ", "), strings.Join(sources, ", "), ingestStagingTable)
+	if _, err := tx.ExecContext(ctx, query, hashVersion); err != nil {
+		return fmt.Errorf("error loading breach table %s: %w", breachName, err)
+	}
+
+	for _, column := range indexed {
+		query := fmt.Sprintf(`CREATE INDEX %s ON %s (%s)`,
+			pq.QuoteIdentifier(breachName+"_"+column+"_idx"), table, pq.QuoteIdentifier(column))
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("error indexing %s.%s: %w", breachName, column, err)
+		}
+	}
+
+	return nil
+}
+
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// hasColumn reports whether the breach table has the given column.
+func hasColumn(ctx context.Context, q rowQuerier, table, column string) (bool, error) {
+	var exists bool
+	err := q.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT FROM information_schema.columns
+			WHERE table_schema = 'public' AND table_name = $1 AND column_name = $2
+		)`, table, column).Scan(&exists)
+	return exists, err
+}
+
+// PrepareBreachTable gives a breach table loaded before hash versions the
+// hash_version and id columns RehashBreach walks it by. Adding the id
+// rewrites the table under an exclusive lock, so it is done here, one table
+// at a time, rather than in a startup migration.
+func (r *IngestRepository) PrepareBreachTable(ctx context.Context, breachName string) error {
+	present := make(map[string]bool)
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT column_name FROM information_schema.columns
+		WHERE table_schema = 'public' AND table_name = $1 AND column_name IN ('id', 'hash_version')`, breachName)
+	if err != nil {
+		return fmt.Errorf("error getting columns of %s: %w", breachName, err)
+	}
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			rows.Close()
+			return fmt.Errorf("error getting columns of %s: %w", breachName, err)
+		}
+		present[column] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error getting columns of %s: %w", breachName, err)
+	}
+
+	table := pq.QuoteIdentifier(breachName)
+	if !present["hash_version"] {
+		query := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN hash_version SMALLINT NOT NULL DEFAULT 1`, table)
+		if _, err := r.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("error adding hash_version to %s: %w", breachName, err)
+		}
+	}
+	if !present["id"] {
+		query := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN id BIGSERIAL`, table)
+		if _, err := r.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("error adding id to %s: %w", breachName, err)
+		}
+		query = fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s (id)`, pq.QuoteIdentifier(breachName+"_id_idx"), table)
+		if _, err := r.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("error indexing %s.id: %w", breachName, err)
+		}
+	}
+	return nil
+}
+
+// RehashBreach moves every row of breachName that is stored with a hash
+// scheme older than to up to to, batchSize rows per transaction so that
+// searches keep running meanwhile. derive turns a stored hash of version from
+// into one of version to. Sensitive tables are left alone; they always hold
+// v1 hashes. A breach with rows to move loses its indexed_at first, so the
+// index strategy reports it as unchecked until RebuildIndex has run again.
+func (r *IngestRepository) RehashBreach(ctx context.Context, breachName string, to, batchSize int, derive func(hash string, from, to int) (string, error)) (int64, error) {
+	var fields []string
+	err := r.db.QueryRowContext(ctx, `SELECT fields FROM breach_metadata WHERE name = $1`, breachName).Scan(pq.Array(&fields))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: %s", ErrBreachNotFound, breachName)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error getting breach metadata for %s: %w", breachName, err)
+	}
+
+	var columns []string
+	for _, fieldType := range fields {
+		if column := PersonalColumn(fieldType); column != "" {
+			columns = append(columns, pq.QuoteIdentifier(column))
+		}
+	}
+	if len(columns) == 0 {
+		return 0, nil
+	}
+	if containsString(fields, "email") {
+		hasDomains, err := hasColumn(ctx, r.db, breachName, EmailDomainColumn)
+		if err != nil {
+			return 0, fmt.Errorf("error checking email domains of %s: %w", breachName, err)
+		}
+		if hasDomains {
+			columns = append(columns, pq.QuoteIdentifier(EmailDomainColumn))
+		}
+	}
+
+	table := pq.QuoteIdentifier(breachName)
+	unindex := fmt.Sprintf(`
+		UPDATE breach_metadata SET indexed_at = NULL
+		WHERE name = $1 AND EXISTS (SELECT 1 FROM %s WHERE hash_version < $2)`, table)
+	if _, err := r.db.ExecContext(ctx, unindex, breachName, to); err != nil {
+		return 0, fmt.Errorf("error clearing index state of %s: %w", breachName, err)
+	}
+
+	selectQuery := fmt.Sprintf(`
+		SELECT id, hash_version, %s
+		FROM %s
+		WHERE id > $1 AND hash_version < $2
+		ORDER BY id
+		LIMIT $3
+		FOR UPDATE`,
+		strings.Join(columns, ", "), table)
+
+	assignments := make([]string, len(columns))
+	for i, column := range columns {
+		assignments[i] = fmt.Sprintf("%s = $%d", column, i+3)
+	}
+	updateQuery := fmt.Sprintf(`UPDATE %s SET hash_version = $2, %s WHERE id = $1`, table, strings.Join(assignments, ", "))
+
+	var total, lastID int64
+	for {
+		rehashed, last, err := r.rehashBatch(ctx, selectQuery, updateQuery, len(columns), lastID, to, batchSize, derive)
+		if err != nil {
+			return total, fmt.Errorf("error rehashing %s after id %d: %w", breachName, lastID, err)
+		}
+		if rehashed == 0 {
+			return total, nil
+		}
+		total += rehashed
+		lastID = last
+	}
+}
+
+func (r *IngestRepository) rehashBatch(ctx context.Context, selectQuery, updateQuery string, columnCount int, afterID int64, to, batchSize int, derive func(hash string, from, to int) (string, error)) (int64, int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	type storedRow struct {
+		id      int64
+		version int
+		hashes  []sql.NullString
+	}
+
+	rows, err := tx.QueryContext(ctx, selectQuery, afterID, to, batchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	var batch []storedRow
+	for rows.Next() {
+		row := storedRow{hashes: make([]sql.NullString, columnCount)}
+		dest := []interface{}{&row.id, &row.version}
+		for i := range row.hashes {
+			dest = append(dest, &row.hashes[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		batch = append(batch, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+	if len(batch) == 0 {
+		return 0, afterID, nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, updateQuery)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer stmt.Close()
+
+	args := make([]interface{}, columnCount+2)
+	for _, row := range batch {
+		args[0], args[1] = row.id, to
+		for i, hash := range row.hashes {
+			if !hash.Valid {
+				args[i+2] = nil
+				continue
+			}
+			derived, err := derive(hash.String, row.version, to)
+			if err != nil {
+				return 0, 0, err
+			}
+			args[i+2] = derived
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return int64(len(batch)), batch[len(batch)-1].id, nil
+}
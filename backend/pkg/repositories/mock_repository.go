@@ -3,7 +3,9 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Rikjimue/breach-radar/backend/pkg/models"
@@ -15,19 +17,20 @@ import (
 
 type MockBreachRepository struct {
 	breaches      map[string]models.BreachMetadata
-	personalData  map[string]map[string]string
+	personalData  map[string][]map[string]string // rows per breach
 	sensitiveData map[string][]SensitiveEntry
 }
 
 type SensitiveEntry struct {
 	BreachSource string
 	Hash         string
+	Occurrences  int // 0 counts as 1
 }
 
 func NewMockBreachRepository() *MockBreachRepository {
 	mock := &MockBreachRepository{
 		breaches:      make(map[string]models.BreachMetadata),
-		personalData:  make(map[string]map[string]string),
+		personalData:  make(map[string][]map[string]string),
 		sensitiveData: make(map[string][]SensitiveEntry),
 	}
 	mock.loadMockData()
@@ -40,38 +43,45 @@ func (m *MockBreachRepository) loadMockData() {
 	passwordDate, _ := time.Parse("2006-01-02", "2020-03-15")
 
 	m.breaches["breach_linkedin_2021"] = models.BreachMetadata{
+		ID:              1,
 		Name:            "breach_linkedin_2021",
 		Date:            linkedinDate,
 		AffectedRecords: 700000000,
 		Fields:          []string{"email", "firstName", "lastName", "username"},
+		Industry:        "Technology",
 	}
 
 	m.breaches["breach_facebook_2019"] = models.BreachMetadata{
+		ID:              2,
 		Name:            "breach_facebook_2019",
 		Date:            facebookDate,
 		AffectedRecords: 419000000,
 		Fields:          []string{"phone", "firstName", "username"},
+		Industry:        "Social Media",
 	}
 
 	m.breaches["breach_passwords_2020"] = models.BreachMetadata{
-		Name:            "breach_passwords_2020",
-		Date:            passwordDate,
-		AffectedRecords: 500000000,
-		Fields:          []string{"password"},
+		ID:                    3,
+		Name:                  "breach_passwords_2020",
+		Date:                  passwordDate,
+		AffectedRecords:       500000000,
+		Fields:                []string{"password"},
+		Industry:              "Technology",
+		PasswordHashAlgorithm: "md5",
 	}
 
-	m.personalData["breach_linkedin_2021"] = map[string]string{
+	m.personalData["breach_linkedin_2021"] = []map[string]string{{
 		"email":     "a1b2c3d4e5f6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
 		"firstName": "f1e2d3c4b5a6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
 		"lastName":  "b2c3d4e5f6a7789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
 		"username":  "u1v2w3x4y5z6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
-	}
+	}}
 
-	m.personalData["breach_facebook_2019"] = map[string]string{
+	m.personalData["breach_facebook_2019"] = []map[string]string{{
 		"phone":     "c3d4e5f6a7b8789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
 		"firstName": "f1e2d3c4b5a6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
 		"username":  "u9v8w7x6y5z4789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
-	}
+	}}
 
 	m.sensitiveData["password"] = []SensitiveEntry{
 		{BreachSource: "breach_passwords_2020", Hash: "a1b2c3d4e5f6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890"},
@@ -81,6 +91,24 @@ func (m *MockBreachRepository) loadMockData() {
 	}
 }
 
+// AddBreach registers an extra breach, for tests that need more than the
+// default data.
+func (m *MockBreachRepository) AddBreach(breach models.BreachMetadata) {
+	m.breaches[breach.Name] = breach
+}
+
+// AddPersonalRecord stores an extra row in a breach table, keyed by field
+// type, for tests that need more than the default data.
+func (m *MockBreachRepository) AddPersonalRecord(breachName string, record map[string]string) {
+	m.personalData[breachName] = append(m.personalData[breachName], record)
+}
+
+// AddSensitiveEntry stores an extra sensitive hash, for tests that need more
+// than the default data.
+func (m *MockBreachRepository) AddSensitiveEntry(fieldType string, entry SensitiveEntry) {
+	m.sensitiveData[fieldType] = append(m.sensitiveData[fieldType], entry)
+}
+
 func (m *MockBreachRepository) GetBreachesWithFields(ctx context.Context, fieldNames []string) ([]models.BreachMetadata, error) {
 	var result []models.BreachMetadata
 
@@ -105,42 +133,1191 @@ func (m *MockBreachRepository) GetBreachesWithFields(ctx context.Context, fieldN
 	return result, nil
 }
 
-func (m *MockBreachRepository) FindExactMatches(ctx context.Context, breachName string, fieldHashes map[string]string) ([]string, error) {
-	personalData, exists := m.personalData[breachName]
-	if !exists {
-		return []string{}, nil
-	}
-
+func (m *MockBreachRepository) FindExactMatches(ctx context.Context, breachName string, fieldHashes map[string][]string) ([]string, error) {
 	var matchedFields []string
-	for fieldType, hash := range fieldHashes {
-		if storedHash, exists := personalData[fieldType]; exists && storedHash == hash {
-			matchedFields = append(matchedFields, fieldType)
+	for fieldType, hashes := range fieldHashes {
+		for _, record := range m.personalData[breachName] {
+			if storedHash, exists := record[fieldType]; exists && containsString(hashes, storedHash) {
+				matchedFields = append(matchedFields, fieldType)
+				break
+			}
 		}
 	}
 
 	return matchedFields, nil
 }
 
-func (m *MockBreachRepository) FindSensitiveMatches(ctx context.Context, fieldType, partialHash string) (map[string][]string, error) {
+func (m *MockBreachRepository) FindRecordMatches(ctx context.Context, breachName string, fieldHashes map[string][]string) ([][]string, error) {
+	var records [][]string
+	seen := make(map[string]bool)
+	for _, record := range m.personalData[breachName] {
+		var matched []string
+		for fieldType, hashes := range fieldHashes {
+			if storedHash, exists := record[fieldType]; exists && containsString(hashes, storedHash) {
+				matched = append(matched, fieldType)
+			}
+		}
+		sort.Strings(matched)
+		if key := strings.Join(matched, ","); len(matched) >= 2 && !seen[key] {
+			seen[key] = true
+			records = append(records, matched)
+		}
+	}
+
+	return records, nil
+}
+
+func (m *MockBreachRepository) FindPresentHashes(ctx context.Context, breachName, fieldType string, hashes []string) ([]string, error) {
+	var present []string
+	for _, record := range m.personalData[breachName] {
+		if storedHash, exists := record[fieldType]; exists && containsString(hashes, storedHash) && !containsString(present, storedHash) {
+			present = append(present, storedHash)
+		}
+	}
+	return present, nil
+}
+
+func (m *MockBreachRepository) FindSensitiveMatches(ctx context.Context, fieldType, partialHash string) (map[string]map[string]int, error) {
 	entries, exists := m.sensitiveData[fieldType]
 	if !exists {
-		return map[string][]string{}, nil
+		return map[string]map[string]int{}, nil
 	}
 
-	result := make(map[string][]string)
+	result := make(map[string]map[string]int)
 	for _, entry := range entries {
-		if strings.HasPrefix(entry.Hash, partialHash) {
-			result[entry.BreachSource] = append(result[entry.BreachSource], entry.Hash)
+		if !strings.HasPrefix(entry.Hash, partialHash) {
+			continue
+		}
+		if result[entry.BreachSource] == nil {
+			result[entry.BreachSource] = make(map[string]int)
 		}
+		result[entry.BreachSource][entry.Hash] += max(entry.Occurrences, 1)
 	}
 
 	return result, nil
 }
 
+// BreachesWithEmailDomain treats every breach with a row carrying an
+// EmailDomainField as having the column.
+func (m *MockBreachRepository) BreachesWithEmailDomain(ctx context.Context) ([]string, error) {
+	var names []string
+	for name, records := range m.personalData {
+		for _, record := range records {
+			if _, exists := record[EmailDomainField]; exists {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *MockBreachRepository) FindDomainAccounts(ctx context.Context, breachName string, domainHashes, emailHashes, fields []string, limit int) ([]models.DomainAccount, int64, error) {
+	var total int64
+	accounts := []models.DomainAccount{}
+	for _, record := range m.personalData[breachName] {
+		if !containsString(domainHashes, record[EmailDomainField]) || record["email"] == "" {
+			continue
+		}
+		if emailHashes != nil && !containsString(emailHashes, record["email"]) {
+			continue
+		}
+		total++
+		if len(accounts) >= limit {
+			continue
+		}
+		exposed := []string{}
+		for _, fieldType := range fields {
+			if record[fieldType] != "" {
+				exposed = append(exposed, fieldType)
+			}
+		}
+		accounts = append(accounts, models.DomainAccount{EmailHash: record["email"], HashVersion: 1, ExposedFields: exposed})
+	}
+	return accounts, total, nil
+}
+
 func (m *MockBreachRepository) GetBreachMetadata(ctx context.Context, breachName string) (*models.BreachMetadata, error) {
 	breach, exists := m.breaches[breachName]
 	if !exists {
-		return nil, fmt.Errorf("breach not found: %s", breachName)
+		return nil, fmt.Errorf("%w: %s", ErrBreachNotFound, breachName)
 	}
 	return &breach, nil
 }
+
+func (m *MockBreachRepository) ListBreaches(ctx context.Context, filter models.BreachListFilter) ([]models.BreachMetadata, int, error) {
+	var result []models.BreachMetadata
+	for _, breach := range m.breaches {
+		if filter.Industry != "" && !strings.EqualFold(breach.Industry, filter.Industry) {
+			continue
+		}
+		if filter.FieldType != "" && !containsString(breach.Fields, filter.FieldType) {
+			continue
+		}
+		if filter.From != nil && breach.Date.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && breach.Date.After(*filter.To) {
+			continue
+		}
+		result = append(result, breach)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		less := result[i].Date.Before(result[j].Date)
+		if filter.SortBy == "size" {
+			less = result[i].AffectedRecords < result[j].AffectedRecords
+		}
+		if filter.Ascending {
+			return less
+		}
+		return !less
+	})
+
+	total := len(result)
+	start := min(filter.Offset, total)
+	end := total
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, total)
+	}
+	return append([]models.BreachMetadata{}, result[start:end]...), total, nil
+}
+
+func (m *MockBreachRepository) GetFieldCoverage(ctx context.Context) ([]models.FieldCoverage, error) {
+	counts := make(map[string]int)
+	for _, breach := range m.breaches {
+		for _, field := range breach.Fields {
+			counts[field]++
+		}
+	}
+
+	coverage := []models.FieldCoverage{}
+	for field, count := range counts {
+		coverage = append(coverage, models.FieldCoverage{Field: field, Breaches: count})
+	}
+	sort.Slice(coverage, func(i, j int) bool { return coverage[i].Field < coverage[j].Field })
+	return coverage, nil
+}
+
+func (m *MockBreachRepository) GetCatalogStatistics(ctx context.Context, periods StatisticsPeriods) (*CatalogStatistics, error) {
+	stats := &CatalogStatistics{
+		DailyBreaches: make(map[string]int),
+		FieldCounts:   make(map[string]int),
+		RegionRecords: make(map[string]int64),
+	}
+	firstSeen := make(map[string]time.Time)
+	industries := make(map[string]*IndustryCount)
+
+	for _, breach := range m.breaches {
+		stats.Breaches++
+		stats.TotalRecords += breach.AffectedRecords
+		stats.RegionRecords[breach.Region] += breach.AffectedRecords
+		if !breach.Date.Before(periods.MonthStart) {
+			stats.MonthBreaches++
+			stats.MonthRecords += breach.AffectedRecords
+		}
+		if !breach.Date.Before(periods.ActivityStart) {
+			stats.DailyBreaches[breach.Date.Format("2006-01-02")]++
+		}
+		if breach.SourceURL != "" {
+			if first, ok := firstSeen[breach.SourceURL]; !ok || breach.Date.Before(first) {
+				firstSeen[breach.SourceURL] = breach.Date
+			}
+		}
+		for _, field := range breach.Fields {
+			stats.FieldCounts[field]++
+		}
+		if breach.Industry != "" {
+			industry, ok := industries[breach.Industry]
+			if !ok {
+				industry = &IndustryCount{Industry: breach.Industry}
+				industries[breach.Industry] = industry
+			}
+			industry.Breaches++
+			if breach.Date.After(periods.YearAgo) {
+				industry.LastYear++
+			} else if breach.Date.After(periods.TwoYearsAgo) {
+				industry.YearBefore++
+			}
+		}
+	}
+
+	stats.Sources = len(firstSeen)
+	for _, first := range firstSeen {
+		if !first.Before(periods.MonthStart) {
+			stats.NewSources++
+		}
+	}
+	for _, industry := range industries {
+		stats.Industries = append(stats.Industries, *industry)
+	}
+	return stats, nil
+}
+
+func (m *MockBreachRepository) ForEachBreach(ctx context.Context, fn func(*models.BreachMetadata) error) error {
+	for _, breach := range m.breaches {
+		if err := fn(&breach); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+// MockIndexedBreachRepository serves personal searches through
+// FindBreachesByHashes, like IndexedBreachRepository.
+type MockIndexedBreachRepository struct {
+	*MockBreachRepository
+	unindexed map[string]bool
+}
+
+func NewMockIndexedBreachRepository() *MockIndexedBreachRepository {
+	return &MockIndexedBreachRepository{MockBreachRepository: NewMockBreachRepository(), unindexed: make(map[string]bool)}
+}
+
+// MarkUnindexed leaves breachName out of the index, like a breach loaded
+// without reindexing.
+func (m *MockIndexedBreachRepository) MarkUnindexed(breachName string) {
+	m.unindexed[breachName] = true
+}
+
+func (m *MockIndexedBreachRepository) FindBreachesByHashes(ctx context.Context, fieldHashes map[string][]string) ([]models.BreachFieldMatch, error) {
+	var matches []models.BreachFieldMatch
+	for name, breach := range m.breaches {
+		if m.unindexed[name] {
+			continue
+		}
+		matchedFields, _ := m.FindExactMatches(ctx, name, fieldHashes)
+		if len(matchedFields) > 0 {
+			matches = append(matches, models.BreachFieldMatch{Breach: breach, MatchedFields: matchedFields})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Breach.Date.After(matches[j].Breach.Date) })
+	return matches, nil
+}
+
+func (m *MockIndexedBreachRepository) UnindexedBreaches(ctx context.Context, fieldTypes []string) ([]models.BreachMetadata, error) {
+	breaches, err := m.GetBreachesWithFields(ctx, fieldTypes)
+	if err != nil {
+		return nil, err
+	}
+	var unindexed []models.BreachMetadata
+	for _, breach := range breaches {
+		if m.unindexed[breach.Name] {
+			unindexed = append(unindexed, breach)
+		}
+	}
+	return unindexed, nil
+}
+
+// ======================================
+// MOCK USER REPOSITORY IMPLEMENTATION
+// ======================================
+
+type MockUserRepository struct {
+	mu       sync.Mutex
+	nextID   uint64
+	users    map[uint64]*models.User
+	sessions map[string]*models.Session
+	apiKeys  map[uint64]*models.APIKey
+}
+
+func NewMockUserRepository() *MockUserRepository {
+	return &MockUserRepository{
+		users:    make(map[uint64]*models.User),
+		sessions: make(map[string]*models.Session),
+		apiKeys:  make(map[uint64]*models.APIKey),
+	}
+}
+
+func (m *MockUserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.users {
+		if existing.Email == user.Email {
+			return ErrEmailTaken
+		}
+	}
+
+	m.nextID++
+	now := time.Now()
+	user.ID = m.nextID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	stored := *user
+	m.users[user.ID] = &stored
+	return nil
+}
+
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, user := range m.users {
+		if user.Email == email {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (m *MockUserRepository) GetUserByID(ctx context.Context, id uint64) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, exists := m.users[id]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func (m *MockUserRepository) UpdateUser(ctx context.Context, user *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.users[user.ID]; !exists {
+		return ErrUserNotFound
+	}
+	user.UpdatedAt = time.Now()
+	stored := *user
+	m.users[user.ID] = &stored
+	return nil
+}
+
+func (m *MockUserRepository) DeleteUser(ctx context.Context, id uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.users[id]; !exists {
+		return ErrUserNotFound
+	}
+	delete(m.users, id)
+	for tokenHash, session := range m.sessions {
+		if session.UserID == id {
+			delete(m.sessions, tokenHash)
+		}
+	}
+	return nil
+}
+
+func (m *MockUserRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	session.ID = m.nextID
+	session.CreatedAt = time.Now()
+	stored := *session
+	m.sessions[session.TokenHash] = &stored
+	return nil
+}
+
+func (m *MockUserRepository) GetSessionByTokenHash(ctx context.Context, tokenHash string) (*models.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, exists := m.sessions[tokenHash]
+	if !exists {
+		return nil, ErrSessionNotFound
+	}
+	found := *session
+	return &found, nil
+}
+
+func (m *MockUserRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, tokenHash)
+	return nil
+}
+
+func (m *MockUserRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	key.ID = m.nextID
+	key.CreatedAt = time.Now()
+	stored := *key
+	m.apiKeys[key.ID] = &stored
+	return nil
+}
+
+func (m *MockUserRepository) ListAPIKeys(ctx context.Context, userID uint64) ([]models.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := []models.APIKey{}
+	for _, key := range m.apiKeys {
+		if key.UserID == userID {
+			keys = append(keys, *key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *MockUserRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range m.apiKeys {
+		if key.KeyHash == keyHash {
+			found := *key
+			return &found, nil
+		}
+	}
+	return nil, ErrAPIKeyNotFound
+}
+
+func (m *MockUserRepository) UpdateAPIKey(ctx context.Context, key *models.APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, exists := m.apiKeys[key.ID]
+	if !exists || stored.UserID != key.UserID || stored.RevokedAt != nil {
+		return ErrAPIKeyNotFound
+	}
+	stored.Name = key.Name
+	stored.Scopes = key.Scopes
+	return nil
+}
+
+func (m *MockUserRepository) RevokeAPIKey(ctx context.Context, userID, keyID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, exists := m.apiKeys[keyID]
+	if !exists || stored.UserID != userID || stored.RevokedAt != nil {
+		return ErrAPIKeyNotFound
+	}
+	now := time.Now()
+	stored.RevokedAt = &now
+	return nil
+}
+
+func (m *MockUserRepository) TouchAPIKey(ctx context.Context, keyID uint64, usedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if stored, exists := m.apiKeys[keyID]; exists {
+		stored.LastUsedAt = &usedAt
+	}
+	return nil
+}
+
+// ======================================
+// MOCK REMEDIATION REPOSITORY IMPLEMENTATION
+// ======================================
+
+type MockRemediationRepository struct {
+	remediations []models.Remediation
+}
+
+func NewMockRemediationRepository() *MockRemediationRepository {
+	return &MockRemediationRepository{
+		remediations: []models.Remediation{
+			{FieldType: "password", Urgency: "Critical", Actions: []string{"Change this password everywhere it is used"}, Links: []models.RemediationLink{}},
+			{FieldType: "email", Urgency: "High", Actions: []string{"Expect targeted phishing"}, Links: []models.RemediationLink{}},
+			{FieldType: "firstName", Urgency: "Low", Actions: []string{"Be wary of messages that use your name"}, Links: []models.RemediationLink{}},
+			{FieldType: "email", Breach: "breach_linkedin_2021", Urgency: "High", Actions: []string{"Check your LinkedIn security settings"}, Links: []models.RemediationLink{
+				{Title: "Account security", URL: "https://example.com/security"},
+			}},
+		},
+	}
+}
+
+func (m *MockRemediationRepository) FindRemediations(ctx context.Context, fieldTypes, breachNames []string) ([]models.Remediation, error) {
+	result := []models.Remediation{}
+	for _, remediation := range m.remediations {
+		if len(fieldTypes) > 0 && !containsString(fieldTypes, remediation.FieldType) {
+			continue
+		}
+		if remediation.Breach != "" && !containsString(breachNames, remediation.Breach) {
+			continue
+		}
+		result = append(result, remediation)
+	}
+	return result, nil
+}
+
+// ======================================
+// MOCK WATCHLIST REPOSITORY IMPLEMENTATION
+// ======================================
+
+type MockWatchlistRepository struct {
+	mu         sync.Mutex
+	nextID     uint64
+	breachRepo *MockBreachRepository
+	entries    map[uint64]*models.WatchlistEntry
+	alerts     map[uint64]*models.WatchlistAlert
+	scanned    map[uint64]bool
+	emailed    map[uint64]bool
+}
+
+// NewMockWatchlistRepository treats every breach already in breachRepo as
+// scanned, like the migration does.
+func NewMockWatchlistRepository(breachRepo *MockBreachRepository) *MockWatchlistRepository {
+	m := &MockWatchlistRepository{
+		breachRepo: breachRepo,
+		entries:    make(map[uint64]*models.WatchlistEntry),
+		alerts:     make(map[uint64]*models.WatchlistAlert),
+		scanned:    make(map[uint64]bool),
+		emailed:    make(map[uint64]bool),
+	}
+	for _, breach := range breachRepo.breaches {
+		m.scanned[breach.ID] = true
+	}
+	return m
+}
+
+func (m *MockWatchlistRepository) CreateEntry(ctx context.Context, entry *models.WatchlistEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	entry.ID = m.nextID
+	entry.CreatedAt = time.Now()
+	entry.Fields = watchedFields(entry.Hashes)
+	stored := *entry
+	m.entries[entry.ID] = &stored
+	return nil
+}
+
+func (m *MockWatchlistRepository) ListEntries(ctx context.Context, userID uint64) ([]models.WatchlistEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := []models.WatchlistEntry{}
+	for _, entry := range m.entries {
+		if entry.UserID == userID {
+			entries = append(entries, *entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID > entries[j].ID })
+	return entries, nil
+}
+
+func (m *MockWatchlistRepository) DeleteEntry(ctx context.Context, userID, entryID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.entries[entryID]
+	if !exists || entry.UserID != userID {
+		return ErrWatchlistEntryNotFound
+	}
+	delete(m.entries, entryID)
+	for id, alert := range m.alerts {
+		if alert.EntryID == entryID {
+			delete(m.alerts, id)
+		}
+	}
+	return nil
+}
+
+func (m *MockWatchlistRepository) ListAllEntries(ctx context.Context, afterID uint64, limit int) ([]models.WatchlistEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := []models.WatchlistEntry{}
+	for _, entry := range m.entries {
+		if entry.ID > afterID {
+			entries = append(entries, *entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+func (m *MockWatchlistRepository) FindUnscannedBreaches(ctx context.Context) ([]models.BreachMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var breaches []models.BreachMetadata
+	for _, breach := range m.breachRepo.breaches {
+		if !m.scanned[breach.ID] {
+			breaches = append(breaches, breach)
+		}
+	}
+	sort.Slice(breaches, func(i, j int) bool { return breaches[i].ID < breaches[j].ID })
+	return breaches, nil
+}
+
+func (m *MockWatchlistRepository) CompleteBreachScan(ctx context.Context, breachID uint64, alerts []models.WatchlistAlert) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.scanned[breachID] {
+		return false, nil
+	}
+	for _, alert := range alerts {
+		duplicate := false
+		for _, existing := range m.alerts {
+			if existing.EntryID == alert.EntryID && existing.BreachID == breachID {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		m.nextID++
+		stored := alert
+		stored.ID = m.nextID
+		stored.BreachID = breachID
+		stored.CreatedAt = time.Now()
+		m.alerts[stored.ID] = &stored
+	}
+	m.scanned[breachID] = true
+	return true, nil
+}
+
+func (m *MockWatchlistRepository) ListAlerts(ctx context.Context, userID uint64, unacknowledgedOnly bool) ([]models.WatchlistAlert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts := []models.WatchlistAlert{}
+	for _, alert := range m.alerts {
+		entry := m.entries[alert.EntryID]
+		if entry == nil || entry.UserID != userID || (unacknowledgedOnly && alert.AcknowledgedAt != nil) {
+			continue
+		}
+		alerts = append(alerts, m.alertView(alert))
+	}
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].ID > alerts[j].ID })
+	return alerts, nil
+}
+
+func (m *MockWatchlistRepository) ListUnemailedAlerts(ctx context.Context) ([]models.WatchlistAlert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts := []models.WatchlistAlert{}
+	for _, alert := range m.alerts {
+		if !m.emailed[alert.ID] && m.entries[alert.EntryID] != nil {
+			alerts = append(alerts, m.alertView(alert))
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].ID < alerts[j].ID })
+	return alerts, nil
+}
+
+func (m *MockWatchlistRepository) MarkAlertsEmailed(ctx context.Context, alertIDs []uint64, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, id := range alertIDs {
+		m.emailed[id] = true
+	}
+	return nil
+}
+
+func (m *MockWatchlistRepository) alertView(alert *models.WatchlistAlert) models.WatchlistAlert {
+	entry := m.entries[alert.EntryID]
+	result := *alert
+	result.EntryLabel = entry.Label
+	result.UserID = entry.UserID
+	for _, breach := range m.breachRepo.breaches {
+		if breach.ID == alert.BreachID {
+			result.Breach = breach.Name
+			result.BreachDate = breach.Date
+		}
+	}
+	return result
+}
+
+func (m *MockWatchlistRepository) AcknowledgeAlert(ctx context.Context, userID, alertID uint64, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alert, exists := m.alerts[alertID]
+	if !exists || m.entries[alert.EntryID] == nil || m.entries[alert.EntryID].UserID != userID {
+		return ErrWatchlistAlertNotFound
+	}
+	if alert.AcknowledgedAt == nil {
+		alert.AcknowledgedAt = &at
+	}
+	return nil
+}
+
+// ======================================
+// MOCK WEBHOOK REPOSITORY IMPLEMENTATION
+// ======================================
+
+type MockWebhookRepository struct {
+	mu         sync.Mutex
+	nextID     uint64
+	webhooks   map[uint64]*models.Webhook
+	deliveries map[uint64]*models.WebhookDelivery
+}
+
+func NewMockWebhookRepository() *MockWebhookRepository {
+	return &MockWebhookRepository{
+		webhooks:   make(map[uint64]*models.Webhook),
+		deliveries: make(map[uint64]*models.WebhookDelivery),
+	}
+}
+
+func (m *MockWebhookRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	webhook.ID = m.nextID
+	webhook.CreatedAt = time.Now()
+	stored := *webhook
+	m.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+func (m *MockWebhookRepository) ListWebhooks(ctx context.Context, userID uint64) ([]models.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	webhooks := []models.Webhook{}
+	for _, webhook := range m.webhooks {
+		if webhook.UserID == userID {
+			webhooks = append(webhooks, *webhook)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID > webhooks[j].ID })
+	return webhooks, nil
+}
+
+func (m *MockWebhookRepository) DeleteWebhook(ctx context.Context, userID, webhookID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	webhook, exists := m.webhooks[webhookID]
+	if !exists || webhook.UserID != userID {
+		return ErrWebhookNotFound
+	}
+	delete(m.webhooks, webhookID)
+	for id, delivery := range m.deliveries {
+		if delivery.WebhookID == webhookID {
+			delete(m.deliveries, id)
+		}
+	}
+	return nil
+}
+
+func (m *MockWebhookRepository) EnqueueEvent(ctx context.Context, event models.WebhookEvent, payload []byte, at time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	created := 0
+	for _, webhook := range m.webhooks {
+		if !containsString(webhook.EventTypes, event.Type) || (event.UserID != 0 && webhook.UserID != event.UserID) {
+			continue
+		}
+		m.nextID++
+		due := at
+		m.deliveries[m.nextID] = &models.WebhookDelivery{
+			ID:            m.nextID,
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       append([]byte(nil), payload...),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: &due,
+			CreatedAt:     at,
+			Log:           []models.WebhookAttempt{},
+		}
+		created++
+	}
+	return created, nil
+}
+
+func (m *MockWebhookRepository) ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var due []*models.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.Status == models.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	var claimed []models.WebhookDelivery
+	for _, delivery := range due {
+		lease := leaseUntil
+		delivery.NextAttemptAt = &lease
+		result := *delivery
+		result.URL = m.webhooks[delivery.WebhookID].URL
+		result.Secret = m.webhooks[delivery.WebhookID].Secret
+		claimed = append(claimed, result)
+	}
+	return claimed, nil
+}
+
+func (m *MockWebhookRepository) RecordAttempt(ctx context.Context, deliveryID uint64, attempt models.WebhookAttempt, status string, nextAttemptAt *time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delivery, exists := m.deliveries[deliveryID]
+	if !exists {
+		return nil
+	}
+	delivery.Log = append(delivery.Log, attempt)
+	delivery.Attempts++
+	delivery.Status = status
+	delivery.NextAttemptAt = nextAttemptAt
+	if status == models.DeliverySucceeded {
+		at := attempt.AttemptedAt
+		delivery.DeliveredAt = &at
+	}
+	return nil
+}
+
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, userID, webhookID uint64, status string, limit int) ([]models.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deliveries := []models.WebhookDelivery{}
+	webhook, exists := m.webhooks[webhookID]
+	if !exists || webhook.UserID != userID {
+		return deliveries, nil
+	}
+	for _, delivery := range m.deliveries {
+		if delivery.WebhookID == webhookID && (status == "" || delivery.Status == status) {
+			result := *delivery
+			result.Log = append([]models.WebhookAttempt{}, delivery.Log...)
+			deliveries = append(deliveries, result)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (m *MockWebhookRepository) RetryDelivery(ctx context.Context, userID, deliveryID uint64, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delivery, exists := m.deliveries[deliveryID]
+	if !exists || delivery.Status != models.DeliveryDead || m.webhooks[delivery.WebhookID].UserID != userID {
+		return ErrWebhookDeliveryNotFound
+	}
+	delivery.Status = models.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = &at
+	return nil
+}
+
+// ======================================
+// MOCK DOMAIN REPOSITORY IMPLEMENTATION
+// ======================================
+
+type MockDomainRepository struct {
+	mu     sync.Mutex
+	nextID uint64
+	claims map[uint64]*models.DomainClaim
+}
+
+func NewMockDomainRepository() *MockDomainRepository {
+	return &MockDomainRepository{claims: make(map[uint64]*models.DomainClaim)}
+}
+
+func (m *MockDomainRepository) CreateClaim(ctx context.Context, claim *models.DomainClaim) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.claims {
+		if existing.OrgID == claim.OrgID && existing.Domain == claim.Domain {
+			return ErrDomainClaimed
+		}
+	}
+	m.nextID++
+	claim.ID = m.nextID
+	claim.CreatedAt = time.Now()
+	stored := *claim
+	m.claims[claim.ID] = &stored
+	return nil
+}
+
+func (m *MockDomainRepository) ListClaims(ctx context.Context, orgID uint64) ([]models.DomainClaim, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	claims := []models.DomainClaim{}
+	for _, claim := range m.claims {
+		if claim.OrgID == orgID {
+			claims = append(claims, *claim)
+		}
+	}
+	sort.Slice(claims, func(i, j int) bool { return claims[i].Domain < claims[j].Domain })
+	return claims, nil
+}
+
+func (m *MockDomainRepository) GetClaim(ctx context.Context, orgID, claimID uint64) (*models.DomainClaim, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	claim, exists := m.claims[claimID]
+	if !exists || claim.OrgID != orgID {
+		return nil, ErrDomainClaimNotFound
+	}
+	found := *claim
+	return &found, nil
+}
+
+func (m *MockDomainRepository) MarkVerified(ctx context.Context, claimID uint64, method string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	claim, exists := m.claims[claimID]
+	if !exists {
+		return ErrDomainClaimNotFound
+	}
+	for _, other := range m.claims {
+		if other.ID != claimID && other.Domain == claim.Domain && other.VerifiedAt != nil {
+			return ErrDomainVerified
+		}
+	}
+	claim.VerificationMethod = method
+	claim.VerifiedAt = &at
+	return nil
+}
+
+func (m *MockDomainRepository) DeleteClaim(ctx context.Context, orgID, claimID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	claim, exists := m.claims[claimID]
+	if !exists || claim.OrgID != orgID {
+		return ErrDomainClaimNotFound
+	}
+	delete(m.claims, claimID)
+	return nil
+}
+
+// ======================================
+// MOCK ORGANIZATION REPOSITORY IMPLEMENTATION
+// ======================================
+
+type MockOrganizationRepository struct {
+	mu          sync.Mutex
+	nextID      uint64
+	userRepo    *MockUserRepository
+	orgs        map[uint64]*models.Organization
+	members     map[uint64]*models.Member // by user ID
+	memberOrgs  map[uint64]uint64         // user ID to org ID
+	invitations map[uint64]*models.Invitation
+}
+
+// NewMockOrganizationRepository looks up member accounts in userRepo.
+func NewMockOrganizationRepository(userRepo *MockUserRepository) *MockOrganizationRepository {
+	return &MockOrganizationRepository{
+		userRepo:    userRepo,
+		orgs:        make(map[uint64]*models.Organization),
+		members:     make(map[uint64]*models.Member),
+		memberOrgs:  make(map[uint64]uint64),
+		invitations: make(map[uint64]*models.Invitation),
+	}
+}
+
+func (m *MockOrganizationRepository) addMember(ctx context.Context, orgID, userID uint64, role string) error {
+	if _, exists := m.memberOrgs[userID]; exists {
+		return ErrAlreadyMember
+	}
+	member := &models.Member{UserID: userID, Role: role, JoinedAt: time.Now()}
+	if user, err := m.userRepo.GetUserByID(ctx, userID); err == nil {
+		member.Email, member.FirstName, member.LastName = user.Email, user.FirstName, user.LastName
+	}
+	m.members[userID] = member
+	m.memberOrgs[userID] = orgID
+	return nil
+}
+
+func (m *MockOrganizationRepository) CreateOrganization(ctx context.Context, org *models.Organization, ownerID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.memberOrgs[ownerID]; exists {
+		return ErrAlreadyMember
+	}
+	m.nextID++
+	org.ID = m.nextID
+	org.CreatedAt = time.Now()
+	stored := *org
+	m.orgs[org.ID] = &stored
+	return m.addMember(ctx, org.ID, ownerID, models.RoleOwner)
+}
+
+func (m *MockOrganizationRepository) UpdateOrganization(ctx context.Context, org *models.Organization) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, exists := m.orgs[org.ID]
+	if !exists {
+		return ErrOrganizationNotFound
+	}
+	stored.Name = org.Name
+	return nil
+}
+
+func (m *MockOrganizationRepository) DeleteOrganization(ctx context.Context, orgID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.orgs[orgID]; !exists {
+		return ErrOrganizationNotFound
+	}
+	delete(m.orgs, orgID)
+	for userID, memberOrg := range m.memberOrgs {
+		if memberOrg == orgID {
+			delete(m.memberOrgs, userID)
+			delete(m.members, userID)
+		}
+	}
+	for id, invitation := range m.invitations {
+		if invitation.OrgID == orgID {
+			delete(m.invitations, id)
+		}
+	}
+	return nil
+}
+
+func (m *MockOrganizationRepository) GetMembership(ctx context.Context, userID uint64) (*models.Membership, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	orgID, exists := m.memberOrgs[userID]
+	if !exists {
+		return nil, ErrMembershipNotFound
+	}
+	return &models.Membership{Organization: *m.orgs[orgID], Role: m.members[userID].Role}, nil
+}
+
+func (m *MockOrganizationRepository) ListMembers(ctx context.Context, orgID uint64) ([]models.Member, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	members := []models.Member{}
+	for userID, memberOrg := range m.memberOrgs {
+		if memberOrg == orgID {
+			members = append(members, *m.members[userID])
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+	return members, nil
+}
+
+func (m *MockOrganizationRepository) UpdateMemberRole(ctx context.Context, orgID, userID uint64, role string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if memberOrg, exists := m.memberOrgs[userID]; !exists || memberOrg != orgID {
+		return ErrMembershipNotFound
+	}
+	m.members[userID].Role = role
+	return nil
+}
+
+func (m *MockOrganizationRepository) RemoveMember(ctx context.Context, orgID, userID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if memberOrg, exists := m.memberOrgs[userID]; !exists || memberOrg != orgID {
+		return ErrMembershipNotFound
+	}
+	delete(m.memberOrgs, userID)
+	delete(m.members, userID)
+	return nil
+}
+
+func (m *MockOrganizationRepository) CreateInvitation(ctx context.Context, invitation *models.Invitation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.invitations {
+		if existing.OrgID == invitation.OrgID && existing.Email == invitation.Email {
+			return ErrInvitationExists
+		}
+	}
+	m.nextID++
+	invitation.ID = m.nextID
+	invitation.CreatedAt = time.Now()
+	stored := *invitation
+	m.invitations[invitation.ID] = &stored
+	return nil
+}
+
+func (m *MockOrganizationRepository) ListInvitations(ctx context.Context, orgID uint64) ([]models.Invitation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	invitations := []models.Invitation{}
+	for _, invitation := range m.invitations {
+		if invitation.OrgID == orgID {
+			invitations = append(invitations, *invitation)
+		}
+	}
+	sort.Slice(invitations, func(i, j int) bool { return invitations[i].ID > invitations[j].ID })
+	return invitations, nil
+}
+
+func (m *MockOrganizationRepository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, invitation := range m.invitations {
+		if invitation.TokenHash == tokenHash {
+			found := *invitation
+			return &found, nil
+		}
+	}
+	return nil, ErrInvitationNotFound
+}
+
+func (m *MockOrganizationRepository) DeleteInvitation(ctx context.Context, orgID, invitationID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	invitation, exists := m.invitations[invitationID]
+	if !exists || invitation.OrgID != orgID {
+		return ErrInvitationNotFound
+	}
+	delete(m.invitations, invitationID)
+	return nil
+}
+
+// AcceptInvitation drops accepted invitations, which only the pending list
+// would show.
+func (m *MockOrganizationRepository) AcceptInvitation(ctx context.Context, invitation *models.Invitation, userID uint64, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.invitations[invitation.ID]; !exists {
+		return ErrInvitationNotFound
+	}
+	if err := m.addMember(ctx, invitation.OrgID, userID, invitation.Role); err != nil {
+		return err
+	}
+	delete(m.invitations, invitation.ID)
+	return nil
+}
@@ -0,0 +1,304 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrMembershipNotFound   = errors.New("membership not found")
+	ErrAlreadyMember        = errors.New("account already belongs to an organization")
+	ErrInvitationNotFound   = errors.New("invitation not found")
+	ErrInvitationExists     = errors.New("invitation already pending")
+)
+
+type OrganizationRepository interface {
+	// CreateOrganization stores org with ownerID as its owner. It fails
+	// with ErrAlreadyMember when the owner belongs to an organization.
+	CreateOrganization(ctx context.Context, org *models.Organization, ownerID uint64) error
+	UpdateOrganization(ctx context.Context, org *models.Organization) error
+	DeleteOrganization(ctx context.Context, orgID uint64) error
+
+	// GetMembership returns the organization userID belongs to, or
+	// ErrMembershipNotFound.
+	GetMembership(ctx context.Context, userID uint64) (*models.Membership, error)
+	ListMembers(ctx context.Context, orgID uint64) ([]models.Member, error)
+	UpdateMemberRole(ctx context.Context, orgID, userID uint64, role string) error
+	RemoveMember(ctx context.Context, orgID, userID uint64) error
+
+	CreateInvitation(ctx context.Context, invitation *models.Invitation) error
+	// ListInvitations returns the invitations of orgID not accepted yet.
+	ListInvitations(ctx context.Context, orgID uint64) ([]models.Invitation, error)
+	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error)
+	DeleteInvitation(ctx context.Context, orgID, invitationID uint64) error
+	// AcceptInvitation adds userID to the invitation's organization with
+	// its role and marks it accepted, failing with ErrAlreadyMember when
+	// the account belongs to an organization.
+	AcceptInvitation(ctx context.Context, invitation *models.Invitation, userID uint64, at time.Time) error
+}
+
+type SQLOrganizationRepository struct {
+	db *sql.DB
+}
+
+func NewSQLOrganizationRepository(db *sql.DB) *SQLOrganizationRepository {
+	return &SQLOrganizationRepository{db: db}
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func (r *SQLOrganizationRepository) CreateOrganization(ctx context.Context, org *models.Organization, ownerID uint64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error creating organization: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO organizations (name, plan, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+	if err := tx.QueryRowContext(ctx, query, org.Name, org.Plan, ownerID).Scan(&org.ID, &org.CreatedAt); err != nil {
+		return fmt.Errorf("error creating organization: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO organization_members (org_id, user_id, role) VALUES ($1, $2, $3)`, org.ID, ownerID, models.RoleOwner)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyMember
+		}
+		return fmt.Errorf("error adding organization owner: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error creating organization: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLOrganizationRepository) UpdateOrganization(ctx context.Context, org *models.Organization) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE organizations SET name = $2 WHERE id = $1`, org.ID, org.Name)
+	if err != nil {
+		return fmt.Errorf("error updating organization %d: %w", org.ID, err)
+	}
+	return expectAffected(result, ErrOrganizationNotFound)
+}
+
+func (r *SQLOrganizationRepository) DeleteOrganization(ctx context.Context, orgID uint64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM organizations WHERE id = $1`, orgID)
+	if err != nil {
+		return fmt.Errorf("error deleting organization %d: %w", orgID, err)
+	}
+	return expectAffected(result, ErrOrganizationNotFound)
+}
+
+func (r *SQLOrganizationRepository) GetMembership(ctx context.Context, userID uint64) (*models.Membership, error) {
+	query := `
+		SELECT o.id, o.name, o.plan, o.created_at, m.role
+		FROM organization_members m
+		JOIN organizations o ON o.id = m.org_id
+		WHERE m.user_id = $1`
+
+	var membership models.Membership
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&membership.ID,
+		&membership.Name,
+		&membership.Plan,
+		&membership.CreatedAt,
+		&membership.Role,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMembershipNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting membership of user %d: %w", userID, err)
+	}
+
+	return &membership, nil
+}
+
+func (r *SQLOrganizationRepository) ListMembers(ctx context.Context, orgID uint64) ([]models.Member, error) {
+	query := `
+		SELECT u.id, u.email, u.first_name, u.last_name, m.role, m.created_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.org_id = $1
+		ORDER BY m.created_at, u.id`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing members of organization %d: %w", orgID, err)
+	}
+	defer rows.Close()
+
+	members := []models.Member{}
+	for rows.Next() {
+		var member models.Member
+		if err := rows.Scan(&member.UserID, &member.Email, &member.FirstName, &member.LastName, &member.Role, &member.JoinedAt); err != nil {
+			return nil, fmt.Errorf("error scanning member: %w", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing members of organization %d: %w", orgID, err)
+	}
+
+	return members, nil
+}
+
+func (r *SQLOrganizationRepository) UpdateMemberRole(ctx context.Context, orgID, userID uint64, role string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE organization_members SET role = $3 WHERE org_id = $1 AND user_id = $2`, orgID, userID, role)
+	if err != nil {
+		return fmt.Errorf("error updating member %d: %w", userID, err)
+	}
+	return expectAffected(result, ErrMembershipNotFound)
+}
+
+func (r *SQLOrganizationRepository) RemoveMember(ctx context.Context, orgID, userID uint64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM organization_members WHERE org_id = $1 AND user_id = $2`, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("error removing member %d: %w", userID, err)
+	}
+	return expectAffected(result, ErrMembershipNotFound)
+}
+
+const invitationColumns = `id, org_id, email, role, token_hash, COALESCE(invited_by, 0), expires_at, created_at`
+
+func scanInvitation(row interface{ Scan(...any) error }) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.OrgID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.TokenHash,
+		&invitation.InvitedBy,
+		&invitation.ExpiresAt,
+		&invitation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *SQLOrganizationRepository) CreateInvitation(ctx context.Context, invitation *models.Invitation) error {
+	query := `
+		INSERT INTO organization_invitations (org_id, email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		invitation.OrgID,
+		invitation.Email,
+		invitation.Role,
+		invitation.TokenHash,
+		invitation.InvitedBy,
+		invitation.ExpiresAt,
+	).Scan(&invitation.ID, &invitation.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrInvitationExists
+		}
+		return fmt.Errorf("error creating invitation: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLOrganizationRepository) ListInvitations(ctx context.Context, orgID uint64) ([]models.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM organization_invitations WHERE org_id = $1 AND accepted_at IS NULL ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []models.Invitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning invitation: %w", err)
+		}
+		invitations = append(invitations, *invitation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing invitations: %w", err)
+	}
+
+	return invitations, nil
+}
+
+func (r *SQLOrganizationRepository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM organization_invitations WHERE token_hash = $1 AND accepted_at IS NULL`
+
+	invitation, err := scanInvitation(r.db.QueryRowContext(ctx, query, tokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+func (r *SQLOrganizationRepository) DeleteInvitation(ctx context.Context, orgID, invitationID uint64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM organization_invitations WHERE id = $1 AND org_id = $2 AND accepted_at IS NULL`, invitationID, orgID)
+	if err != nil {
+		return fmt.Errorf("error deleting invitation %d: %w", invitationID, err)
+	}
+	return expectAffected(result, ErrInvitationNotFound)
+}
+
+func (r *SQLOrganizationRepository) AcceptInvitation(ctx context.Context, invitation *models.Invitation, userID uint64, at time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error accepting invitation: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE organization_invitations SET accepted_at = $2 WHERE id = $1 AND accepted_at IS NULL`, invitation.ID, at)
+	if err != nil {
+		return fmt.Errorf("error accepting invitation %d: %w", invitation.ID, err)
+	}
+	if err := expectAffected(result, ErrInvitationNotFound); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO organization_members (org_id, user_id, role) VALUES ($1, $2, $3)`, invitation.OrgID, userID, invitation.Role)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyMember
+		}
+		return fmt.Errorf("error adding member %d: %w", userID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error accepting invitation %d: %w", invitation.ID, err)
+	}
+	return nil
+}
+
+// expectAffected returns notFound when result changed no rows.
+func expectAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error reading affected rows: %w", err)
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
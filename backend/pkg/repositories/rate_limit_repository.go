@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+type RateLimitRepository interface {
+	// TakeToken refills the token bucket for key up to capacity at
+	// refillPerSecond and consumes one token if available. It returns the
+	// tokens left after the attempt.
+	TakeToken(ctx context.Context, key string, capacity int, refillPerSecond float64, now time.Time) (allowed bool, tokens float64, err error)
+	// IncrementDailyUsage counts one call against key's quota for day unless
+	// the count has already reached limit. It returns the resulting count.
+	IncrementDailyUsage(ctx context.Context, key string, day time.Time, limit int) (allowed bool, count int, err error)
+	// Prune deletes buckets last touched before idleBefore and usage counts
+	// of days before day. Callers choose idleBefore so that every deleted
+	// bucket had refilled completely, which makes dropping it the same as
+	// keeping it.
+	Prune(ctx context.Context, idleBefore, day time.Time) (deleted int64, err error)
+}
+
+// ======================================
+// POSTGRES RATE LIMIT STORE
+// ======================================
+
+type SQLRateLimitRepository struct {
+	db *sql.DB
+}
+
+func NewSQLRateLimitRepository(db *sql.DB) *SQLRateLimitRepository {
+	return &SQLRateLimitRepository{db: db}
+}
+
+func (r *SQLRateLimitRepository) TakeToken(ctx context.Context, key string, capacity int, refillPerSecond float64, now time.Time) (bool, float64, error) {
+	// The refill and the take happen in a single statement so concurrent
+	// replicas cannot both spend the last token.
+	query := `
+		INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at)
+		VALUES ($1, $2 - 1, $4)
+		ON CONFLICT (key) DO UPDATE
+		SET tokens = LEAST($2, b.tokens + GREATEST(EXTRACT(EPOCH FROM ($4 - b.updated_at)), 0) * $3) - 1,
+		    updated_at = $4
+		WHERE LEAST($2, b.tokens + GREATEST(EXTRACT(EPOCH FROM ($4 - b.updated_at)), 0) * $3) >= 1
+		RETURNING tokens`
+
+	var tokens float64
+	err := r.db.QueryRowContext(ctx, query, key, float64(capacity), refillPerSecond, now).Scan(&tokens)
+	if err == nil {
+		return true, tokens, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, 0, fmt.Errorf("error taking rate limit token for %s: %w", key, err)
+	}
+
+	query = `
+		SELECT LEAST($2, tokens + GREATEST(EXTRACT(EPOCH FROM ($4 - updated_at)), 0) * $3)
+		FROM rate_limit_buckets
+		WHERE key = $1`
+	if err := r.db.QueryRowContext(ctx, query, key, float64(capacity), refillPerSecond, now).Scan(&tokens); err != nil {
+		return false, 0, fmt.Errorf("error reading rate limit bucket for %s: %w", key, err)
+	}
+
+	return false, tokens, nil
+}
+
+func (r *SQLRateLimitRepository) IncrementDailyUsage(ctx context.Context, key string, day time.Time, limit int) (bool, int, error) {
+	query := `
+		INSERT INTO daily_usage AS u (key, day, count)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (key, day) DO UPDATE
+		SET count = u.count + 1
+		WHERE u.count < $3
+		RETURNING count`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, key, day.Format("2006-01-02"), limit).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, limit, nil
+	}
+	if err != nil {
+		return false, 0, fmt.Errorf("error incrementing daily usage for %s: %w", key, err)
+	}
+
+	return true, count, nil
+}
+
+func (r *SQLRateLimitRepository) Prune(ctx context.Context, idleBefore, day time.Time) (int64, error) {
+	buckets, err := r.db.ExecContext(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < $1`, idleBefore)
+	if err != nil {
+		return 0, fmt.Errorf("error pruning rate limit buckets: %w", err)
+	}
+	usage, err := r.db.ExecContext(ctx, `DELETE FROM daily_usage WHERE day < $1`, day.Format("2006-01-02"))
+	if err != nil {
+		return 0, fmt.Errorf("error pruning daily usage: %w", err)
+	}
+
+	deletedBuckets, _ := buckets.RowsAffected()
+	deletedUsage, _ := usage.RowsAffected()
+	return deletedBuckets + deletedUsage, nil
+}
+
+// ======================================
+// IN-MEMORY RATE LIMIT STORE
+// ======================================
+
+// MemoryRateLimitRepository keeps counters in process memory. It is only
+// correct when a single API instance is serving traffic.
+type MemoryRateLimitRepository struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	usage   map[string]map[string]int // day -> key -> count
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func NewMemoryRateLimitRepository() *MemoryRateLimitRepository {
+	return &MemoryRateLimitRepository{
+		buckets: make(map[string]*memoryBucket),
+		usage:   make(map[string]map[string]int),
+	}
+}
+
+func (m *MemoryRateLimitRepository) TakeToken(ctx context.Context, key string, capacity int, refillPerSecond float64, now time.Time) (bool, float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bucket, exists := m.buckets[key]
+	if !exists {
+		bucket = &memoryBucket{tokens: float64(capacity), updatedAt: now}
+		m.buckets[key] = bucket
+	}
+
+	elapsed := math.Max(now.Sub(bucket.updatedAt).Seconds(), 0)
+	bucket.tokens = math.Min(float64(capacity), bucket.tokens+elapsed*refillPerSecond)
+	bucket.updatedAt = now
+
+	if bucket.tokens < 1 {
+		return false, bucket.tokens, nil
+	}
+	bucket.tokens--
+	return true, bucket.tokens, nil
+}
+
+func (m *MemoryRateLimitRepository) IncrementDailyUsage(ctx context.Context, key string, day time.Time, limit int) (bool, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dayKey := day.Format("2006-01-02")
+	counts, exists := m.usage[dayKey]
+	if !exists {
+		// A new day started; earlier counters can never be read again
+		for d := range m.usage {
+			if d < dayKey {
+				delete(m.usage, d)
+			}
+		}
+		counts = make(map[string]int)
+		m.usage[dayKey] = counts
+	}
+
+	if counts[key] >= limit {
+		return false, counts[key], nil
+	}
+	counts[key]++
+	return true, counts[key], nil
+}
+
+func (m *MemoryRateLimitRepository) Prune(ctx context.Context, idleBefore, day time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted int64
+	for key, bucket := range m.buckets {
+		if bucket.updatedAt.Before(idleBefore) {
+			delete(m.buckets, key)
+			deleted++
+		}
+	}
+
+	dayKey := day.Format("2006-01-02")
+	for d, counts := range m.usage {
+		if d < dayKey {
+			deleted += int64(len(counts))
+			delete(m.usage, d)
+		}
+	}
+
+	return deleted, nil
+}
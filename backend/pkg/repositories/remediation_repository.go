@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/lib/pq"
+)
+
+type RemediationRepository interface {
+	// FindRemediations returns the general advice for fieldTypes and the
+	// advice specific to any of breachNames. No fieldTypes means every
+	// field type.
+	FindRemediations(ctx context.Context, fieldTypes, breachNames []string) ([]models.Remediation, error)
+}
+
+type SQLRemediationRepository struct {
+	db *sql.DB
+}
+
+func NewSQLRemediationRepository(db *sql.DB) *SQLRemediationRepository {
+	return &SQLRemediationRepository{db: db}
+}
+
+func (r *SQLRemediationRepository) FindRemediations(ctx context.Context, fieldTypes, breachNames []string) ([]models.Remediation, error) {
+	query := `
+		SELECT field_type, COALESCE(breach_name, ''), urgency, actions, links
+		FROM remediation
+		WHERE (cardinality($1::text[]) = 0 OR field_type = ANY($1))
+		  AND (breach_name IS NULL OR breach_name = ANY($2))
+		ORDER BY field_type, breach_name NULLS LAST`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(fieldTypes), pq.Array(breachNames))
+	if err != nil {
+		return nil, fmt.Errorf("error querying remediation: %w", err)
+	}
+	defer rows.Close()
+
+	remediations := []models.Remediation{}
+	for rows.Next() {
+		var remediation models.Remediation
+		var links []byte
+		if err := rows.Scan(&remediation.FieldType, &remediation.Breach, &remediation.Urgency, pq.Array(&remediation.Actions), &links); err != nil {
+			return nil, fmt.Errorf("error scanning remediation: %w", err)
+		}
+		if err := json.Unmarshal(links, &remediation.Links); err != nil {
+			return nil, fmt.Errorf("error decoding remediation links for %s: %w", remediation.FieldType, err)
+		}
+		remediations = append(remediations, remediation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying remediation: %w", err)
+	}
+
+	return remediations, nil
+}
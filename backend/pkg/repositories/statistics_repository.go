@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/lib/pq"
+)
+
+// StatisticsPeriods are the dates catalog statistics are split at.
+type StatisticsPeriods struct {
+	MonthStart    time.Time // breaches on or after count as this month's
+	ActivityStart time.Time // first day of the daily activity histogram
+	YearAgo       time.Time // industry trends compare the year after
+	TwoYearsAgo   time.Time // with the year after this
+}
+
+// CatalogStatistics are catalog-wide aggregates of breach_metadata.
+type CatalogStatistics struct {
+	Breaches      int
+	MonthBreaches int
+	TotalRecords  int64
+	MonthRecords  int64
+	Sources       int
+	// NewSources counts sources whose earliest breach is from this month.
+	NewSources    int
+	DailyBreaches map[string]int // "2006-01-02" -> breaches, from ActivityStart
+	FieldCounts   map[string]int // field type -> breaches holding it
+	Industries    []IndustryCount
+	RegionRecords map[string]int64
+}
+
+type IndustryCount struct {
+	Industry   string
+	Breaches   int
+	LastYear   int
+	YearBefore int
+}
+
+// GetCatalogStatistics aggregates breach_metadata in the database, so
+// refreshing statistics never loads the catalog.
+func (r *SQLBreachRepository) GetCatalogStatistics(ctx context.Context, periods StatisticsPeriods) (*CatalogStatistics, error) {
+	stats := &CatalogStatistics{
+		DailyBreaches: make(map[string]int),
+		FieldCounts:   make(map[string]int),
+		RegionRecords: make(map[string]int64),
+	}
+
+	query := `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE breach_date >= $1),
+		       COALESCE(SUM(affected_records), 0),
+		       COALESCE(SUM(affected_records) FILTER (WHERE breach_date >= $1), 0),
+		       COUNT(DISTINCT NULLIF(source_url, '')),
+		       (SELECT COUNT(*) FROM (
+		            SELECT source_url FROM breach_metadata
+		            WHERE source_url <> ''
+		            GROUP BY source_url
+		            HAVING MIN(breach_date) >= $1) AS new_sources)
+		FROM breach_metadata`
+	err := r.db.QueryRowContext(ctx, query, periods.MonthStart).Scan(
+		&stats.Breaches,
+		&stats.MonthBreaches,
+		&stats.TotalRecords,
+		&stats.MonthRecords,
+		&stats.Sources,
+		&stats.NewSources,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating breach totals: %w", err)
+	}
+
+	err = r.scanGroups(ctx, `
+		SELECT to_char(breach_date, 'YYYY-MM-DD'), COUNT(*)
+		FROM breach_metadata
+		WHERE breach_date >= $1
+		GROUP BY breach_date`,
+		[]any{periods.ActivityStart},
+		func(scan func(...any) error) error {
+			var day string
+			var count int
+			if err := scan(&day, &count); err != nil {
+				return err
+			}
+			stats.DailyBreaches[day] = count
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating breach activity: %w", err)
+	}
+
+	err = r.scanGroups(ctx, `
+		SELECT field, COUNT(*)
+		FROM breach_metadata, unnest(fields) AS field
+		GROUP BY field`,
+		nil,
+		func(scan func(...any) error) error {
+			var field string
+			var count int
+			if err := scan(&field, &count); err != nil {
+				return err
+			}
+			stats.FieldCounts[field] = count
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating breach fields: %w", err)
+	}
+
+	err = r.scanGroups(ctx, `
+		SELECT industry,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE breach_date > $1),
+		       COUNT(*) FILTER (WHERE breach_date > $2 AND breach_date <= $1)
+		FROM breach_metadata
+		WHERE industry <> ''
+		GROUP BY industry`,
+		[]any{periods.YearAgo, periods.TwoYearsAgo},
+		func(scan func(...any) error) error {
+			var industry IndustryCount
+			if err := scan(&industry.Industry, &industry.Breaches, &industry.LastYear, &industry.YearBefore); err != nil {
+				return err
+			}
+			stats.Industries = append(stats.Industries, industry)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating breach industries: %w", err)
+	}
+
+	err = r.scanGroups(ctx, `
+		SELECT region, COALESCE(SUM(affected_records), 0)
+		FROM breach_metadata
+		GROUP BY region`,
+		nil,
+		func(scan func(...any) error) error {
+			var region string
+			var records int64
+			if err := scan(&region, &records); err != nil {
+				return err
+			}
+			stats.RegionRecords[region] = records
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating breach regions: %w", err)
+	}
+
+	return stats, nil
+}
+
+// ForEachBreach streams the columns breach scoring needs, one breach at a
+// time, so scoring the catalog takes constant memory.
+func (r *SQLBreachRepository) ForEachBreach(ctx context.Context, fn func(*models.BreachMetadata) error) error {
+	rows, err := r.db.QueryContext(ctx, `SELECT name, breach_date, affected_records, fields, password_hash_algorithm FROM breach_metadata`)
+	if err != nil {
+		return fmt.Errorf("error streaming breaches: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var breach models.BreachMetadata
+		if err := rows.Scan(&breach.Name, &breach.Date, &breach.AffectedRecords, pq.Array(&breach.Fields), &breach.PasswordHashAlgorithm); err != nil {
+			return fmt.Errorf("error scanning breach: %w", err)
+		}
+		if err := fn(&breach); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *SQLBreachRepository) scanGroups(ctx context.Context, query string, args []any, scanRow func(scan func(...any) error) error) error {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scanRow(rows.Scan); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -1,16 +1,40 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrEmailTaken      = errors.New("email already registered")
+	ErrSessionNotFound = errors.New("session not found")
+	ErrAPIKeyNotFound  = errors.New("api key not found")
 )
 
 type UserRepository interface {
-	CreateUser(user *models.User) error
-	GetUserByEmail(email string) (user *models.User, err error)
-	UpdateUser() error
-	DeleteUser() error
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUserByID(ctx context.Context, id uint64) (*models.User, error)
+	UpdateUser(ctx context.Context, user *models.User) error
+	DeleteUser(ctx context.Context, id uint64) error
+
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSessionByTokenHash(ctx context.Context, tokenHash string) (*models.Session, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	ListAPIKeys(ctx context.Context, userID uint64) ([]models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	UpdateAPIKey(ctx context.Context, key *models.APIKey) error
+	RevokeAPIKey(ctx context.Context, userID, keyID uint64) error
+	TouchAPIKey(ctx context.Context, keyID uint64, usedAt time.Time) error
 }
 
 type SQLUserRepository struct {
@@ -20,3 +44,290 @@ type SQLUserRepository struct {
 func NewSQLUserRepository(db *sql.DB) *SQLUserRepository {
 	return &SQLUserRepository{db: db}
 }
+
+const userColumns = `id, email, password_hash, first_name, last_name, organization, plan, alert_emails, created_at, updated_at`
+
+func scanUser(row interface{ Scan(...any) error }) (*models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.FirstName,
+		&user.LastName,
+		&user.Organization,
+		&user.Plan,
+		&user.AlertEmails,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *SQLUserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	query := `
+		INSERT INTO users (email, password_hash, first_name, last_name, organization, plan, alert_emails)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		user.Email,
+		user.PasswordHash,
+		user.FirstName,
+		user.LastName,
+		user.Organization,
+		user.Plan,
+		user.AlertEmails,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrEmailTaken
+		}
+		return fmt.Errorf("error creating user: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLUserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting user by email: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *SQLUserRepository) GetUserByID(ctx context.Context, id uint64) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting user %d: %w", id, err)
+	}
+
+	return user, nil
+}
+
+func (r *SQLUserRepository) UpdateUser(ctx context.Context, user *models.User) error {
+	query := `
+		UPDATE users
+		SET email = $2, password_hash = $3, first_name = $4, last_name = $5,
+		    organization = $6, plan = $7, alert_emails = $8, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		user.ID,
+		user.Email,
+		user.PasswordHash,
+		user.FirstName,
+		user.LastName,
+		user.Organization,
+		user.Plan,
+		user.AlertEmails,
+	).Scan(&user.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrEmailTaken
+		}
+		return fmt.Errorf("error updating user %d: %w", user.ID, err)
+	}
+
+	return nil
+}
+
+func (r *SQLUserRepository) DeleteUser(ctx context.Context, id uint64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting user %d: %w", id, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting user %d: %w", id, err)
+	}
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *SQLUserRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	query := `
+		INSERT INTO sessions (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRowContext(ctx, query, session.UserID, session.TokenHash, session.ExpiresAt).
+		Scan(&session.ID, &session.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLUserRepository) GetSessionByTokenHash(ctx context.Context, tokenHash string) (*models.Session, error) {
+	query := `SELECT id, user_id, token_hash, expires_at, created_at FROM sessions WHERE token_hash = $1`
+
+	var session models.Session
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.TokenHash,
+		&session.ExpiresAt,
+		&session.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting session: %w", err)
+	}
+
+	return &session, nil
+}
+
+func (r *SQLUserRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE token_hash = $1`, tokenHash); err != nil {
+		return fmt.Errorf("error deleting session: %w", err)
+	}
+	return nil
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at`
+
+func scanAPIKey(row interface{ Scan(...any) error }) (*models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		pq.Array(&key.Scopes),
+		&key.CreatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *SQLUserRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRowContext(ctx, query, key.UserID, key.Name, key.Prefix, key.KeyHash, pq.Array(key.Scopes)).
+		Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating api key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLUserRepository) ListAPIKeys(ctx context.Context, userID uint64) ([]models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning api key: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (r *SQLUserRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, keyHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting api key: %w", err)
+	}
+
+	return key, nil
+}
+
+func (r *SQLUserRepository) UpdateAPIKey(ctx context.Context, key *models.APIKey) error {
+	query := `UPDATE api_keys SET name = $3, scopes = $4 WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, key.ID, key.UserID, key.Name, pq.Array(key.Scopes))
+	if err != nil {
+		return fmt.Errorf("error updating api key %d: %w", key.ID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error updating api key %d: %w", key.ID, err)
+	}
+	if affected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *SQLUserRepository) RevokeAPIKey(ctx context.Context, userID, keyID uint64) error {
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, keyID, userID)
+	if err != nil {
+		return fmt.Errorf("error revoking api key %d: %w", keyID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error revoking api key %d: %w", keyID, err)
+	}
+	if affected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *SQLUserRepository) TouchAPIKey(ctx context.Context, keyID uint64, usedAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, keyID, usedAt); err != nil {
+		return fmt.Errorf("error updating api key %d last use: %w", keyID, err)
+	}
+	return nil
+}
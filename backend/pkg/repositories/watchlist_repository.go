@@ -0,0 +1,291 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrWatchlistEntryNotFound = errors.New("watchlist entry not found")
+	ErrWatchlistAlertNotFound = errors.New("watchlist alert not found")
+)
+
+type WatchlistRepository interface {
+	CreateEntry(ctx context.Context, entry *models.WatchlistEntry) error
+	ListEntries(ctx context.Context, userID uint64) ([]models.WatchlistEntry, error)
+	DeleteEntry(ctx context.Context, userID, entryID uint64) error
+	// ListAllEntries pages through the entries of every user by id.
+	ListAllEntries(ctx context.Context, afterID uint64, limit int) ([]models.WatchlistEntry, error)
+
+	// FindUnscannedBreaches returns the breaches the watchlists have not
+	// been checked against yet, oldest registration first.
+	FindUnscannedBreaches(ctx context.Context) ([]models.BreachMetadata, error)
+	// CompleteBreachScan stores the alerts found in a breach and marks it
+	// scanned, atomically. It stores nothing and returns false when another
+	// scan completed the breach first.
+	CompleteBreachScan(ctx context.Context, breachID uint64, alerts []models.WatchlistAlert) (bool, error)
+
+	ListAlerts(ctx context.Context, userID uint64, unacknowledgedOnly bool) ([]models.WatchlistAlert, error)
+	AcknowledgeAlert(ctx context.Context, userID, alertID uint64, at time.Time) error
+
+	// ListUnemailedAlerts returns the alerts of every user that have not
+	// been emailed yet, oldest first.
+	ListUnemailedAlerts(ctx context.Context) ([]models.WatchlistAlert, error)
+	MarkAlertsEmailed(ctx context.Context, alertIDs []uint64, at time.Time) error
+}
+
+type SQLWatchlistRepository struct {
+	db *sql.DB
+}
+
+func NewSQLWatchlistRepository(db *sql.DB) *SQLWatchlistRepository {
+	return &SQLWatchlistRepository{db: db}
+}
+
+const watchlistEntryColumns = `id, user_id, label, fields, hash_version, created_at`
+
+func scanWatchlistEntry(row interface{ Scan(...any) error }) (*models.WatchlistEntry, error) {
+	var entry models.WatchlistEntry
+	var fields []byte
+	if err := row.Scan(&entry.ID, &entry.UserID, &entry.Label, &fields, &entry.HashVersion, &entry.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(fields, &entry.Hashes); err != nil {
+		return nil, fmt.Errorf("error decoding watchlist entry %d: %w", entry.ID, err)
+	}
+	entry.Fields = watchedFields(entry.Hashes)
+	return &entry, nil
+}
+
+func watchedFields(hashes map[string]string) []string {
+	fields := make([]string, 0, len(hashes))
+	for fieldType := range hashes {
+		fields = append(fields, fieldType)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func (r *SQLWatchlistRepository) CreateEntry(ctx context.Context, entry *models.WatchlistEntry) error {
+	fields, err := json.Marshal(entry.Hashes)
+	if err != nil {
+		return fmt.Errorf("error encoding watchlist entry: %w", err)
+	}
+
+	query := `
+		INSERT INTO watchlist_entries (user_id, label, fields, hash_version)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+	if err := r.db.QueryRowContext(ctx, query, entry.UserID, entry.Label, fields, entry.HashVersion).Scan(&entry.ID, &entry.CreatedAt); err != nil {
+		return fmt.Errorf("error creating watchlist entry: %w", err)
+	}
+	entry.Fields = watchedFields(entry.Hashes)
+	return nil
+}
+
+func (r *SQLWatchlistRepository) ListEntries(ctx context.Context, userID uint64) ([]models.WatchlistEntry, error) {
+	query := `SELECT ` + watchlistEntryColumns + ` FROM watchlist_entries WHERE user_id = $1 ORDER BY created_at DESC`
+	return r.queryEntries(ctx, query, userID)
+}
+
+func (r *SQLWatchlistRepository) ListAllEntries(ctx context.Context, afterID uint64, limit int) ([]models.WatchlistEntry, error) {
+	query := `SELECT ` + watchlistEntryColumns + ` FROM watchlist_entries WHERE id > $1 ORDER BY id LIMIT $2`
+	return r.queryEntries(ctx, query, afterID, limit)
+}
+
+func (r *SQLWatchlistRepository) queryEntries(ctx context.Context, query string, args ...interface{}) ([]models.WatchlistEntry, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing watchlist entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.WatchlistEntry{}
+	for rows.Next() {
+		entry, err := scanWatchlistEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning watchlist entry: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing watchlist entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *SQLWatchlistRepository) DeleteEntry(ctx context.Context, userID, entryID uint64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM watchlist_entries WHERE id = $1 AND user_id = $2`, entryID, userID)
+	if err != nil {
+		return fmt.Errorf("error deleting watchlist entry: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("error deleting watchlist entry: %w", err)
+	} else if n == 0 {
+		return ErrWatchlistEntryNotFound
+	}
+	return nil
+}
+
+func (r *SQLWatchlistRepository) FindUnscannedBreaches(ctx context.Context) ([]models.BreachMetadata, error) {
+	query := `
+		SELECT ` + breachMetadataColumns + `
+		FROM breach_metadata bm
+		WHERE NOT EXISTS (SELECT 1 FROM watchlist_scans ws WHERE ws.breach_id = bm.id)
+		ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error finding unscanned breaches: %w", err)
+	}
+	defer rows.Close()
+
+	var breaches []models.BreachMetadata
+	for rows.Next() {
+		metadata, err := scanBreachMetadata(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning breach metadata: %w", err)
+		}
+		breaches = append(breaches, *metadata)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error finding unscanned breaches: %w", err)
+	}
+
+	return breaches, nil
+}
+
+func (r *SQLWatchlistRepository) CompleteBreachScan(ctx context.Context, breachID uint64, alerts []models.WatchlistAlert) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("error starting watchlist scan of breach %d: %w", breachID, err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `INSERT INTO watchlist_scans (breach_id) VALUES ($1) ON CONFLICT DO NOTHING`, breachID)
+	if err != nil {
+		return false, fmt.Errorf("error marking breach %d scanned: %w", breachID, err)
+	}
+	marked, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error marking breach %d scanned: %w", breachID, err)
+	}
+	if marked == 0 {
+		return false, nil
+	}
+
+	for i := range alerts {
+		alert := &alerts[i]
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO watchlist_alerts (entry_id, breach_id, matched_fields)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (entry_id, breach_id) DO NOTHING`,
+			alert.EntryID, breachID, pq.Array(alert.MatchedFields))
+		if err != nil {
+			return false, fmt.Errorf("error storing watchlist alert: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("error committing watchlist scan of breach %d: %w", breachID, err)
+	}
+	return true, nil
+}
+
+const watchlistAlertQuery = `
+		SELECT a.id, a.entry_id, e.label, e.user_id, a.breach_id, COALESCE(NULLIF(bm.display_name, ''), bm.name), bm.breach_date,
+		       a.matched_fields, a.created_at, a.acknowledged_at
+		FROM watchlist_alerts a
+		JOIN watchlist_entries e ON e.id = a.entry_id
+		JOIN breach_metadata bm ON bm.id = a.breach_id`
+
+func (r *SQLWatchlistRepository) ListAlerts(ctx context.Context, userID uint64, unacknowledgedOnly bool) ([]models.WatchlistAlert, error) {
+	query := watchlistAlertQuery + `
+		WHERE e.user_id = $1 AND (NOT $2 OR a.acknowledged_at IS NULL)
+		ORDER BY a.created_at DESC, a.id DESC`
+
+	return r.queryAlerts(ctx, query, userID, unacknowledgedOnly)
+}
+
+func (r *SQLWatchlistRepository) ListUnemailedAlerts(ctx context.Context) ([]models.WatchlistAlert, error) {
+	query := watchlistAlertQuery + `
+		WHERE a.emailed_at IS NULL
+		ORDER BY a.created_at, a.id`
+
+	return r.queryAlerts(ctx, query)
+}
+
+func (r *SQLWatchlistRepository) MarkAlertsEmailed(ctx context.Context, alertIDs []uint64, at time.Time) error {
+	ids := make([]int64, len(alertIDs))
+	for i, id := range alertIDs {
+		ids[i] = int64(id)
+	}
+
+	if _, err := r.db.ExecContext(ctx, `UPDATE watchlist_alerts SET emailed_at = $2 WHERE id = ANY($1)`, pq.Array(ids), at); err != nil {
+		return fmt.Errorf("error marking watchlist alerts emailed: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLWatchlistRepository) queryAlerts(ctx context.Context, query string, args ...any) ([]models.WatchlistAlert, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing watchlist alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []models.WatchlistAlert{}
+	for rows.Next() {
+		var alert models.WatchlistAlert
+		err := rows.Scan(
+			&alert.ID,
+			&alert.EntryID,
+			&alert.EntryLabel,
+			&alert.UserID,
+			&alert.BreachID,
+			&alert.Breach,
+			&alert.BreachDate,
+			pq.Array(&alert.MatchedFields),
+			&alert.CreatedAt,
+			&alert.AcknowledgedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning watchlist alert: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing watchlist alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// AcknowledgeAlert keeps the first acknowledgement time when called twice.
+func (r *SQLWatchlistRepository) AcknowledgeAlert(ctx context.Context, userID, alertID uint64, at time.Time) error {
+	query := `
+		UPDATE watchlist_alerts a
+		SET acknowledged_at = COALESCE(a.acknowledged_at, $3)
+		FROM watchlist_entries e
+		WHERE a.id = $1 AND e.id = a.entry_id AND e.user_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, alertID, userID, at)
+	if err != nil {
+		return fmt.Errorf("error acknowledging watchlist alert: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("error acknowledging watchlist alert: %w", err)
+	} else if n == 0 {
+		return ErrWatchlistAlertNotFound
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	ListWebhooks(ctx context.Context, userID uint64) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, webhookID uint64) error
+
+	// EnqueueEvent creates a pending delivery of payload for every webhook
+	// subscribed to the event, due at. It returns how many were created.
+	EnqueueEvent(ctx context.Context, event models.WebhookEvent, payload []byte, at time.Time) (int, error)
+	// ClaimDueDeliveries returns up to limit pending deliveries due at now
+	// and pushes them back to leaseUntil, so other workers skip them while
+	// they are being sent. URL and Secret are filled in.
+	ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error)
+	// RecordAttempt logs an attempt and moves the delivery to status. A
+	// pending delivery is due again at nextAttemptAt.
+	RecordAttempt(ctx context.Context, deliveryID uint64, attempt models.WebhookAttempt, status string, nextAttemptAt *time.Time) error
+
+	// ListDeliveries returns the newest deliveries of a webhook with their
+	// attempts; an empty status means every status.
+	ListDeliveries(ctx context.Context, userID, webhookID uint64, status string, limit int) ([]models.WebhookDelivery, error)
+	// RetryDelivery makes a dead delivery pending again, due at.
+	RetryDelivery(ctx context.Context, userID, deliveryID uint64, at time.Time) error
+}
+
+type SQLWebhookRepository struct {
+	db *sql.DB
+}
+
+func NewSQLWebhookRepository(db *sql.DB) *SQLWebhookRepository {
+	return &SQLWebhookRepository{db: db}
+}
+
+func (r *SQLWebhookRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	query := `
+		INSERT INTO webhooks (user_id, url, secret, event_types)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRowContext(ctx, query, webhook.UserID, webhook.URL, webhook.Secret, pq.Array(webhook.EventTypes)).
+		Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating webhook: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLWebhookRepository) ListWebhooks(ctx context.Context, userID uint64) ([]models.Webhook, error) {
+	query := `
+		SELECT id, user_id, url, secret, event_types, created_at
+		FROM webhooks
+		WHERE user_id = $1
+		ORDER BY id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		var webhook models.Webhook
+		err := rows.Scan(&webhook.ID, &webhook.UserID, &webhook.URL, &webhook.Secret, pq.Array(&webhook.EventTypes), &webhook.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *SQLWebhookRepository) DeleteWebhook(ctx context.Context, userID, webhookID uint64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`, webhookID, userID)
+	if err != nil {
+		return fmt.Errorf("error deleting webhook %d: %w", webhookID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting webhook %d: %w", webhookID, err)
+	}
+	if affected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *SQLWebhookRepository) EnqueueEvent(ctx context.Context, event models.WebhookEvent, payload []byte, at time.Time) (int, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, next_attempt_at)
+		SELECT id, $1, $2, $3, $4
+		FROM webhooks
+		WHERE $2 = ANY(event_types) AND ($5 = 0 OR user_id = $5)`
+
+	result, err := r.db.ExecContext(ctx, query, event.ID, event.Type, payload, at, event.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("error enqueueing %s event: %w", event.Type, err)
+	}
+
+	created, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error enqueueing %s event: %w", event.Type, err)
+	}
+
+	return int(created), nil
+}
+
+func (r *SQLWebhookRepository) ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $2
+		FROM webhooks w
+		WHERE w.id = d.webhook_id AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.created_at, w.url, w.secret`
+
+	rows, err := r.db.QueryContext(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error claiming webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.CreatedAt,
+			&delivery.URL,
+			&delivery.Secret,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook delivery: %w", err)
+		}
+		delivery.NextAttemptAt = &leaseUntil
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error claiming webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *SQLWebhookRepository) RecordAttempt(ctx context.Context, deliveryID uint64, attempt models.WebhookAttempt, status string, nextAttemptAt *time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting webhook attempt of delivery %d: %w", deliveryID, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO webhook_attempts (delivery_id, attempted_at, status_code, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5)`,
+		deliveryID, attempt.AttemptedAt, attempt.StatusCode, attempt.Error, attempt.DurationMs)
+	if err != nil {
+		return fmt.Errorf("error logging webhook attempt of delivery %d: %w", deliveryID, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1,
+		    status = $2,
+		    next_attempt_at = $3,
+		    delivered_at = CASE WHEN $2 = 'succeeded' THEN $4 ELSE delivered_at END
+		WHERE id = $1`,
+		deliveryID, status, nextAttemptAt, attempt.AttemptedAt)
+	if err != nil {
+		return fmt.Errorf("error updating webhook delivery %d: %w", deliveryID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing webhook attempt of delivery %d: %w", deliveryID, err)
+	}
+	return nil
+}
+
+func (r *SQLWebhookRepository) ListDeliveries(ctx context.Context, userID, webhookID uint64, status string, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+		       d.next_attempt_at, d.created_at, d.delivered_at
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.webhook_id = $1 AND w.user_id = $2 AND ($3 = '' OR d.status = $3)
+		ORDER BY d.id DESC
+		LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, query, webhookID, userID, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	byID := make(map[uint64]int)
+	ids := []int64{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&delivery.CreatedAt,
+			&delivery.DeliveredAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook delivery: %w", err)
+		}
+		delivery.Log = []models.WebhookAttempt{}
+		byID[delivery.ID] = len(deliveries)
+		ids = append(ids, int64(delivery.ID))
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing webhook deliveries: %w", err)
+	}
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	attempts, err := r.db.QueryContext(ctx, `
+		SELECT delivery_id, attempted_at, status_code, error, duration_ms
+		FROM webhook_attempts
+		WHERE delivery_id = ANY($1)
+		ORDER BY id`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhook attempts: %w", err)
+	}
+	defer attempts.Close()
+
+	for attempts.Next() {
+		var deliveryID uint64
+		var attempt models.WebhookAttempt
+		if err := attempts.Scan(&deliveryID, &attempt.AttemptedAt, &attempt.StatusCode, &attempt.Error, &attempt.DurationMs); err != nil {
+			return nil, fmt.Errorf("error scanning webhook attempt: %w", err)
+		}
+		delivery := &deliveries[byID[deliveryID]]
+		delivery.Log = append(delivery.Log, attempt)
+	}
+	if err := attempts.Err(); err != nil {
+		return nil, fmt.Errorf("error listing webhook attempts: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *SQLWebhookRepository) RetryDelivery(ctx context.Context, userID, deliveryID uint64, at time.Time) error {
+	query := `
+		UPDATE webhook_deliveries d
+		SET status = 'pending', attempts = 0, next_attempt_at = $3
+		FROM webhooks w
+		WHERE d.id = $1 AND w.id = d.webhook_id AND w.user_id = $2 AND d.status = 'dead'`
+
+	result, err := r.db.ExecContext(ctx, query, deliveryID, userID, at)
+	if err != nil {
+		return fmt.Errorf("error retrying webhook delivery %d: %w", deliveryID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error retrying webhook delivery %d: %w", deliveryID, err)
+	}
+	if affected == 0 {
+		return ErrWebhookDeliveryNotFound
+	}
+
+	return nil
+}
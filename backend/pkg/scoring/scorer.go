@@ -0,0 +1,153 @@
+package scoring
+
+import (
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+)
+
+const (
+	SeverityCritical = "Critical"
+	SeverityHigh     = "High"
+	SeverityMedium   = "Medium"
+	SeverityLow      = "Low"
+)
+
+const yearDuration = 365.25 * 24 * float64(time.Hour)
+
+// Scorer applies Weights. The weights can be swapped while it is in use.
+type Scorer struct {
+	weights atomic.Pointer[Weights]
+}
+
+// NewScorer scores with weights; nil means DefaultWeights.
+func NewScorer(weights *Weights) *Scorer {
+	if weights == nil {
+		weights = DefaultWeights()
+	}
+	s := &Scorer{}
+	s.weights.Store(weights)
+	return s
+}
+
+// SetWeights replaces the weights for every later score.
+func (s *Scorer) SetWeights(weights *Weights) {
+	s.weights.Store(weights)
+}
+
+// ScoreBreach rates a breach by the fields it exposed, its size, its age at
+// now and how well the breached service hashed its passwords.
+func (s *Scorer) ScoreBreach(breach *models.BreachMetadata, now time.Time) models.RiskScore {
+	w := s.weights.Load()
+
+	var points []float64
+	for _, field := range breach.Fields {
+		points = append(points, w.fieldPoints(field, breach))
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(points)))
+
+	var score float64
+	for i, p := range points {
+		if i > 0 {
+			p *= w.SecondaryFieldFactor
+		}
+		score += p
+	}
+	for _, tier := range w.Records {
+		if breach.AffectedRecords >= tier.Min {
+			score += tier.Points
+			break
+		}
+	}
+	score *= w.ageFactor(breach, now)
+
+	return w.riskScore(score)
+}
+
+// MatchedBreach is a breach a search found and which of the searched fields
+// it holds.
+type MatchedBreach struct {
+	Breach        *models.BreachMetadata
+	MatchedFields []string
+	// IndependentFields are matched fields never found in a record together
+	// with another matched field.
+	IndependentFields []string
+}
+
+// Exposure rates a whole search result. Each matched field counts once, at
+// its most damaging breach: recent breaches and fields found together in one
+// record weigh more. Every breach adds a few points on top.
+func (s *Scorer) Exposure(matches []MatchedBreach, now time.Time) models.RiskScore {
+	w := s.weights.Load()
+
+	strongest := make(map[string]float64)
+	for _, match := range matches {
+		age := w.ageFactor(match.Breach, now)
+		for _, field := range match.MatchedFields {
+			points := w.fieldPoints(field, match.Breach) * age
+			for _, independent := range match.IndependentFields {
+				if independent == field {
+					points *= w.IndependentFactor
+					break
+				}
+			}
+			strongest[field] = max(strongest[field], points)
+		}
+	}
+
+	var score float64
+	for _, points := range strongest {
+		score += points
+	}
+	score += min(w.BreachPoints*float64(len(matches)), w.MaxBreachPoints)
+
+	return w.riskScore(score)
+}
+
+func (w *Weights) fieldPoints(field string, breach *models.BreachMetadata) float64 {
+	points := w.Fields[field]
+	for _, hashed := range w.HashedFields {
+		if hashed == field {
+			factor, ok := w.HashAlgorithms[breach.PasswordHashAlgorithm]
+			if !ok {
+				factor = w.HashAlgorithms[""]
+			}
+			points *= factor
+			break
+		}
+	}
+	return points
+}
+
+func (w *Weights) ageFactor(breach *models.BreachMetadata, now time.Time) float64 {
+	years := float64(now.Sub(breach.Date)) / yearDuration
+	for _, tier := range w.Age {
+		if tier.MaxYears == 0 || years < tier.MaxYears {
+			return tier.Factor
+		}
+	}
+	if len(w.Age) > 0 {
+		return w.Age[len(w.Age)-1].Factor
+	}
+	return 1
+}
+
+func (w *Weights) riskScore(score float64) models.RiskScore {
+	score = math.Round(math.Min(score, 100))
+	return models.RiskScore{Score: int(score), Severity: w.severity(score)}
+}
+
+func (w *Weights) severity(score float64) string {
+	switch {
+	case score >= w.Severity.Critical:
+		return SeverityCritical
+	case score >= w.Severity.High:
+		return SeverityHigh
+	case score >= w.Severity.Medium:
+		return SeverityMedium
+	}
+	return SeverityLow
+}
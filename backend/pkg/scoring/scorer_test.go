@@ -0,0 +1,148 @@
+package scoring
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+)
+
+var now = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func day(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+func TestScoreBreach(t *testing.T) {
+	tests := []struct {
+		name   string
+		breach models.BreachMetadata
+		want   models.RiskScore
+	}{
+		{
+			name:   "fresh plaintext password dump",
+			breach: models.BreachMetadata{Date: day(2025, 1, 1), AffectedRecords: 5000000, Fields: []string{"email", "password"}, PasswordHashAlgorithm: "plaintext"},
+			want:   models.RiskScore{Score: 67, Severity: SeverityCritical},
+		},
+		{
+			name:   "bcrypt passwords",
+			breach: models.BreachMetadata{Date: day(2025, 1, 1), AffectedRecords: 5000000, Fields: []string{"email", "password"}, PasswordHashAlgorithm: "bcrypt"},
+			want:   models.RiskScore{Score: 37, Severity: SeverityMedium},
+		},
+		{
+			name:   "old plaintext password dump",
+			breach: models.BreachMetadata{Date: day(2012, 1, 1), AffectedRecords: 5000000, Fields: []string{"email", "password"}, PasswordHashAlgorithm: "plaintext"},
+			want:   models.RiskScore{Score: 40, Severity: SeverityHigh},
+		},
+		{
+			name:   "unlisted algorithm counts as unknown",
+			breach: models.BreachMetadata{Date: day(2025, 1, 1), Fields: []string{"password"}, PasswordHashAlgorithm: "rot13"},
+			want:   models.RiskScore{Score: 40, Severity: SeverityHigh},
+		},
+		{
+			name:   "names only",
+			breach: models.BreachMetadata{Date: day(2025, 1, 1), AffectedRecords: 500, Fields: []string{"firstName", "lastName"}},
+			want:   models.RiskScore{Score: 6, Severity: SeverityLow},
+		},
+		{
+			name:   "capped at 100",
+			breach: models.BreachMetadata{Date: day(2024, 12, 1), AffectedRecords: 200000000, Fields: []string{"email", "ssn", "creditCard"}},
+			want:   models.RiskScore{Score: 100, Severity: SeverityCritical},
+		},
+	}
+
+	scorer := NewScorer(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scorer.ScoreBreach(&tt.breach, now); got != tt.want {
+				t.Errorf("ScoreBreach() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExposure(t *testing.T) {
+	breach := &models.BreachMetadata{Date: day(2024, 9, 1), Fields: []string{"email", "firstName"}}
+	old := &models.BreachMetadata{Date: day(2010, 1, 1), Fields: []string{"email"}}
+
+	manyBreaches := make([]MatchedBreach, 6)
+	for i := range manyBreaches {
+		manyBreaches[i] = MatchedBreach{Breach: breach, MatchedFields: []string{"email"}}
+	}
+
+	tests := []struct {
+		name    string
+		matches []MatchedBreach
+		want    models.RiskScore
+	}{
+		{name: "no matches", want: models.RiskScore{Score: 0, Severity: SeverityLow}},
+		{
+			name:    "profile in one record",
+			matches: []MatchedBreach{{Breach: breach, MatchedFields: []string{"email", "firstName"}}},
+			want:    models.RiskScore{Score: 28, Severity: SeverityMedium},
+		},
+		{
+			name:    "independent name weighs less",
+			matches: []MatchedBreach{{Breach: breach, MatchedFields: []string{"email", "firstName"}, IndependentFields: []string{"firstName"}}},
+			want:    models.RiskScore{Score: 26, Severity: SeverityMedium},
+		},
+		{
+			name:    "field counts once at its most recent breach",
+			matches: []MatchedBreach{{Breach: old, MatchedFields: []string{"email"}}, {Breach: breach, MatchedFields: []string{"email"}}},
+			want:    models.RiskScore{Score: 26, Severity: SeverityMedium},
+		},
+		{name: "breach points are capped", matches: manyBreaches, want: models.RiskScore{Score: 35, Severity: SeverityMedium}},
+	}
+
+	scorer := NewScorer(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scorer.Exposure(tt.matches, now); got != tt.want {
+				t.Errorf("Exposure() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	valid := string(defaultWeights)
+
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{name: "defaults", json: valid},
+		{name: "unknown key", json: strings.Replace(valid, `"independentFactor"`, `"independentFactr"`, 1), wantErr: "unknown field"},
+		{name: "thresholds out of order", json: strings.Replace(valid, `"critical": 60`, `"critical": 30`, 1), wantErr: "severity thresholds"},
+		{name: "record tiers out of order", json: strings.Replace(valid, `"min": 100000000`, `"min": 1`, 1), wantErr: "record tiers"},
+		{name: "factor above one", json: strings.Replace(valid, `"md5": 0.9`, `"md5": 1.5`, 1), wantErr: "md5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWeights([]byte(tt.json))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ParseWeights() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseWeights() error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScorerSetWeights(t *testing.T) {
+	scorer := NewScorer(nil)
+	breach := &models.BreachMetadata{Date: day(2025, 1, 1), Fields: []string{"email"}}
+
+	weights := DefaultWeights()
+	weights.Fields["email"] = 80
+	scorer.SetWeights(weights)
+
+	if got := scorer.ScoreBreach(breach, now); got.Score != 80 || got.Severity != SeverityCritical {
+		t.Errorf("ScoreBreach() after SetWeights = %+v, want 80 Critical", got)
+	}
+}
@@ -0,0 +1,141 @@
+// Package scoring rates breaches and search results. Every weight lives in a
+// JSON file so analysts can tune them without a code change; the defaults
+// are embedded from default_weights.json.
+package scoring
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+//go:embed default_weights.json
+var defaultWeights []byte
+
+// Weights configures how breaches and searches are scored. Scores run from
+// 0 to 100.
+type Weights struct {
+	// Fields are the points a breach exposing a field type is worth.
+	Fields map[string]float64 `json:"fields"`
+	// SecondaryFieldFactor scales every field but the heaviest, so a breach
+	// of many minor fields does not outrank one of a single critical field.
+	SecondaryFieldFactor float64 `json:"secondaryFieldFactor"`
+	// Records adds the points of the first tier the record count reaches.
+	Records []RecordTier `json:"records"`
+	// Age scales scores by the first tier the breach is younger than.
+	Age []AgeTier `json:"age"`
+	// HashedFields are scaled by the breached service's password hash
+	// algorithm, found in HashAlgorithms ("" is an unknown algorithm).
+	HashedFields   []string           `json:"hashedFields"`
+	HashAlgorithms map[string]float64 `json:"hashAlgorithms"`
+	// IndependentFactor scales fields that only matched outside a record
+	// with other matched fields, e.g. a common surname.
+	IndependentFactor float64 `json:"independentFactor"`
+	// BreachPoints are added to the exposure score per matched breach, up
+	// to MaxBreachPoints.
+	BreachPoints    float64 `json:"breachPoints"`
+	MaxBreachPoints float64 `json:"maxBreachPoints"`
+	// Severity holds the lowest score of each severity; anything below
+	// Medium is Low.
+	Severity SeverityThresholds `json:"severity"`
+}
+
+type RecordTier struct {
+	Min    int64   `json:"min"`
+	Points float64 `json:"points"`
+}
+
+// AgeTier applies to breaches younger than MaxYears; 0 matches any age.
+type AgeTier struct {
+	MaxYears float64 `json:"maxYears"`
+	Factor   float64 `json:"factor"`
+}
+
+type SeverityThresholds struct {
+	Critical float64 `json:"critical"`
+	High     float64 `json:"high"`
+	Medium   float64 `json:"medium"`
+}
+
+// DefaultWeights returns the embedded default weights.
+func DefaultWeights() *Weights {
+	weights, err := ParseWeights(defaultWeights)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default scoring weights: %v", err))
+	}
+	return weights
+}
+
+// LoadWeights reads weights from a JSON file.
+func LoadWeights(path string) (*Weights, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading scoring weights: %w", err)
+	}
+	weights, err := ParseWeights(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scoring weights in %s: %w", path, err)
+	}
+	return weights, nil
+}
+
+// ParseWeights decodes and validates weights. Unknown keys are rejected so a
+// typo does not silently fall back to zero.
+func ParseWeights(data []byte) (*Weights, error) {
+	var weights Weights
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&weights); err != nil {
+		return nil, err
+	}
+	if err := weights.validate(); err != nil {
+		return nil, err
+	}
+	return &weights, nil
+}
+
+func (w *Weights) validate() error {
+	for field, points := range w.Fields {
+		if points < 0 {
+			return fmt.Errorf("field %s has negative points", field)
+		}
+	}
+	for i, tier := range w.Records {
+		if tier.Points < 0 {
+			return fmt.Errorf("record tier %d has negative points", tier.Min)
+		}
+		if i > 0 && tier.Min >= w.Records[i-1].Min {
+			return fmt.Errorf("record tiers must be ordered largest first")
+		}
+	}
+	for i, tier := range w.Age {
+		if tier.Factor < 0 || tier.Factor > 1 {
+			return fmt.Errorf("age factor %g is not between 0 and 1", tier.Factor)
+		}
+		if tier.MaxYears == 0 && i != len(w.Age)-1 {
+			return fmt.Errorf("only the last age tier can match any age")
+		}
+		if i > 0 && tier.MaxYears != 0 && tier.MaxYears <= w.Age[i-1].MaxYears {
+			return fmt.Errorf("age tiers must be ordered youngest first")
+		}
+	}
+	for algorithm, factor := range w.HashAlgorithms {
+		if factor < 0 || factor > 1 {
+			return fmt.Errorf("hash algorithm %q factor %g is not between 0 and 1", algorithm, factor)
+		}
+	}
+	for name, factor := range map[string]float64{"secondaryFieldFactor": w.SecondaryFieldFactor, "independentFactor": w.IndependentFactor} {
+		if factor < 0 || factor > 1 {
+			return fmt.Errorf("%s %g is not between 0 and 1", name, factor)
+		}
+	}
+	if w.BreachPoints < 0 || w.MaxBreachPoints < 0 {
+		return fmt.Errorf("breach points must not be negative")
+	}
+	if !(w.Severity.Critical > w.Severity.High && w.Severity.High > w.Severity.Medium && w.Severity.Medium > 0) {
+		return fmt.Errorf("severity thresholds must satisfy critical > high > medium > 0")
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+const (
+	apiKeyPrefix        = "br_"
+	apiKeyDisplayLength = 11 // "br_" plus eight characters of the secret
+	maxAPIKeyNameLength = 100
+	maxAPIKeysPerUser   = 25
+)
+
+var (
+	errInvalidAPIKey  = utils.NewAppError(http.StatusUnauthorized, "Invalid or revoked API key")
+	errAPIKeyNotFound = utils.NewAppError(http.StatusNotFound, "API key not found")
+)
+
+type APIKeyService struct {
+	userRepo repositories.UserRepository
+}
+
+func NewAPIKeyService(userRepo repositories.UserRepository) *APIKeyService {
+	return &APIKeyService{userRepo: userRepo}
+}
+
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, user *models.User, req *models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error) {
+	name, err := validateAPIKeyName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	// A new key without scopes gets every scope
+	scopes := append([]string(nil), models.APIKeyScopes...)
+	if len(req.Scopes) > 0 {
+		if scopes, err = validateAPIKeyScopes(req.Scopes); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := s.userRepo.ListAPIKeys(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	active := 0
+	for _, key := range existing {
+		if key.RevokedAt == nil {
+			active++
+		}
+	}
+	if active >= maxAPIKeysPerUser {
+		return nil, utils.NewAppError(http.StatusConflict, fmt.Sprintf("At most %d active API keys are allowed", maxAPIKeysPerUser))
+	}
+
+	secret, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	plaintext := apiKeyPrefix + secret
+
+	key := &models.APIKey{
+		UserID:  user.ID,
+		Name:    name,
+		Prefix:  plaintext[:apiKeyDisplayLength],
+		KeyHash: hashToken(plaintext),
+		Scopes:  scopes,
+	}
+	if err := s.userRepo.CreateAPIKey(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return &models.CreateAPIKeyResponse{Key: plaintext, APIKey: key}, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, user *models.User) ([]models.APIKey, error) {
+	keys, err := s.userRepo.ListAPIKeys(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *APIKeyService) UpdateAPIKey(ctx context.Context, user *models.User, keyID uint64, req *models.UpdateAPIKeyRequest) (*models.APIKey, error) {
+	keys, err := s.userRepo.ListAPIKeys(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	var key *models.APIKey
+	for i := range keys {
+		if keys[i].ID == keyID && keys[i].RevokedAt == nil {
+			key = &keys[i]
+			break
+		}
+	}
+	if key == nil {
+		return nil, errAPIKeyNotFound
+	}
+
+	if req.Name != nil {
+		if key.Name, err = validateAPIKeyName(*req.Name); err != nil {
+			return nil, err
+		}
+	}
+	if req.Scopes != nil {
+		if key.Scopes, err = validateAPIKeyScopes(req.Scopes); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.userRepo.UpdateAPIKey(ctx, key); err != nil {
+		if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+			return nil, errAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to update api key: %w", err)
+	}
+
+	return key, nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, user *models.User, keyID uint64) error {
+	if err := s.userRepo.RevokeAPIKey(ctx, user.ID, keyID); err != nil {
+		if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+			return errAPIKeyNotFound
+		}
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	return nil
+}
+
+// Authenticate resolves a plaintext API key to its owner and records the use.
+func (s *APIKeyService) Authenticate(ctx context.Context, plaintext string) (*models.User, *models.APIKey, error) {
+	if !strings.HasPrefix(plaintext, apiKeyPrefix) {
+		return nil, nil, errInvalidAPIKey
+	}
+
+	key, err := s.userRepo.GetAPIKeyByHash(ctx, hashToken(plaintext))
+	if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		return nil, nil, errInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	if key.RevokedAt != nil {
+		return nil, nil, errInvalidAPIKey
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, key.UserID)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return nil, nil, errInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// A failed last-used update should not fail the request it describes
+	now := time.Now().UTC()
+	if err := s.userRepo.TouchAPIKey(ctx, key.ID, now); err != nil {
+		RequestLogger(ctx).ErrorContext(ctx, "Failed to record api key use", "api_key_id", key.ID, "error", err)
+	} else {
+		key.LastUsedAt = &now
+	}
+
+	return user, key, nil
+}
+
+func validateAPIKeyName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", utils.NewAppError(http.StatusBadRequest, "API key name is required")
+	}
+	if len(name) > maxAPIKeyNameLength {
+		return "", utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("API key name must be at most %d characters", maxAPIKeyNameLength))
+	}
+	return name, nil
+}
+
+// validateAPIKeyScopes rejects an empty list and unknown scopes. Only
+// CreateAPIKey treats no scopes as every scope; taking every scope away from
+// an existing key is not a way to grant it all of them.
+func validateAPIKeyScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, utils.NewAppError(http.StatusBadRequest, "At least one scope is required")
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	var validated []string
+	for _, scope := range scopes {
+		known := false
+		for _, s := range models.APIKeyScopes {
+			if s == scope {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unknown API key scope: %s", scope))
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			validated = append(validated, scope)
+		}
+	}
+	return validated, nil
+}
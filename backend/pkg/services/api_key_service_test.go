@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+)
+
+func TestAPIKeyService_Lifecycle(t *testing.T) {
+	repo := repositories.NewMockUserRepository()
+	ctx := context.Background()
+
+	user := &models.User{Email: "ops@example.com", PasswordHash: "x", Plan: "professional"}
+	if err := repo.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewAPIKeyService(repo)
+
+	created, err := service.CreateAPIKey(ctx, user, &models.CreateAPIKeyRequest{Name: "SIEM integration"})
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+	if created.APIKey.KeyHash == created.Key {
+		t.Error("CreateAPIKey() stored plaintext key")
+	}
+	if !created.APIKey.HasScope(models.ScopeBreachSearch) {
+		t.Error("CreateAPIKey() did not default to all scopes")
+	}
+
+	authUser, key, err := service.Authenticate(ctx, created.Key)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if authUser.ID != user.ID || key.LastUsedAt == nil {
+		t.Errorf("Authenticate() user = %d, lastUsedAt = %v", authUser.ID, key.LastUsedAt)
+	}
+
+	newName := "Renamed"
+	updated, err := service.UpdateAPIKey(ctx, user, key.ID, &models.UpdateAPIKeyRequest{Name: &newName})
+	if err != nil {
+		t.Fatalf("UpdateAPIKey() error = %v", err)
+	}
+	if updated.Name != newName {
+		t.Errorf("UpdateAPIKey() name = %q, want %q", updated.Name, newName)
+	}
+
+	// An empty list on update must not widen the key to every scope
+	if _, err := service.UpdateAPIKey(ctx, user, key.ID, &models.UpdateAPIKeyRequest{Scopes: []string{}}); err == nil {
+		t.Error("UpdateAPIKey() accepted an empty scope list")
+	}
+	narrowed, err := service.UpdateAPIKey(ctx, user, key.ID, &models.UpdateAPIKeyRequest{Scopes: []string{models.ScopeBreachSearch}})
+	if err != nil {
+		t.Fatalf("UpdateAPIKey() scopes error = %v", err)
+	}
+	if len(narrowed.Scopes) != 1 || narrowed.Scopes[0] != models.ScopeBreachSearch {
+		t.Errorf("UpdateAPIKey() scopes = %v, want [%s]", narrowed.Scopes, models.ScopeBreachSearch)
+	}
+
+	if _, err := service.CreateAPIKey(ctx, user, &models.CreateAPIKeyRequest{Name: "bad", Scopes: []string{"admin"}}); err == nil {
+		t.Error("CreateAPIKey() accepted unknown scope")
+	}
+
+	if err := service.RevokeAPIKey(ctx, user, key.ID); err != nil {
+		t.Fatalf("RevokeAPIKey() error = %v", err)
+	}
+	if _, _, err := service.Authenticate(ctx, created.Key); err == nil {
+		t.Error("Authenticate() accepted revoked key")
+	}
+}
@@ -1,6 +1,37 @@
 package services
 
-import "github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+const (
+	minPasswordLength = 8
+	// bcrypt only looks at the first 72 bytes of a password.
+	maxPasswordLength = 72
+	sessionTTL        = 7 * 24 * time.Hour
+	defaultPlan       = "free"
+)
+
+var (
+	errInvalidCredentials = utils.NewAppError(http.StatusUnauthorized, "Invalid email or password")
+	errInvalidToken       = utils.NewAppError(http.StatusUnauthorized, "Invalid or expired token")
+)
 
 type AuthService struct {
 	userRepo repositories.UserRepository
@@ -9,3 +40,145 @@ type AuthService struct {
 func NewAuthService(userRepo repositories.UserRepository) *AuthService {
 	return &AuthService{userRepo: userRepo}
 }
+
+func (s *AuthService) Signup(ctx context.Context, req *models.SignupRequest) (*models.AuthResponse, error) {
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Password) < minPasswordLength {
+		return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Password must be at least %d characters", minPasswordLength))
+	}
+	if len(req.Password) > maxPasswordLength {
+		return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Password must be at most %d bytes", maxPasswordLength))
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &models.User{
+		Email:        email,
+		PasswordHash: string(passwordHash),
+		FirstName:    strings.TrimSpace(req.FirstName),
+		LastName:     strings.TrimSpace(req.LastName),
+		Organization: strings.TrimSpace(req.Organization),
+		Plan:         defaultPlan,
+		AlertEmails:  models.AlertEmailsDaily,
+	}
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		if errors.Is(err, repositories.ErrEmailTaken) {
+			return nil, utils.NewAppError(http.StatusConflict, "Email already registered")
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return s.createSession(ctx, user)
+}
+
+func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*models.AuthResponse, error) {
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return nil, errInvalidCredentials
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return nil, errInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, errInvalidCredentials
+	}
+
+	return s.createSession(ctx, user)
+}
+
+// Authenticate resolves a bearer session token to its user. Unknown and
+// expired tokens are reported as a 401 AppError.
+func (s *AuthService) Authenticate(ctx context.Context, token string) (*models.User, error) {
+	if token == "" {
+		return nil, errInvalidToken
+	}
+
+	session, err := s.userRepo.GetSessionByTokenHash(ctx, hashToken(token))
+	if errors.Is(err, repositories.ErrSessionNotFound) {
+		return nil, errInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if time.Now().After(session.ExpiresAt) {
+		if err := s.userRepo.DeleteSession(ctx, session.TokenHash); err != nil {
+			return nil, fmt.Errorf("failed to delete expired session: %w", err)
+		}
+		return nil, errInvalidToken
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, session.UserID)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return nil, errInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+func (s *AuthService) Logout(ctx context.Context, token string) error {
+	if err := s.userRepo.DeleteSession(ctx, hashToken(token)); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+func (s *AuthService) createSession(ctx context.Context, user *models.User) (*models.AuthResponse, error) {
+	token, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate session token: %w", err)
+	}
+
+	session := &models.Session{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(sessionTTL).UTC(),
+	}
+	if err := s.userRepo.CreateSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return &models.AuthResponse{
+		Token:     token,
+		ExpiresAt: session.ExpiresAt,
+		User:      user,
+	}, nil
+}
+
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", utils.NewAppError(http.StatusBadRequest, "Invalid email address")
+	}
+	return email, nil
+}
+
+// generateToken returns a random, URL-safe opaque token. Only its SHA-256 is
+// ever persisted.
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+func TestAuthService_SignupAndLogin(t *testing.T) {
+	repo := repositories.NewMockUserRepository()
+	service := NewAuthService(repo)
+	ctx := context.Background()
+
+	signup, err := service.Signup(ctx, &models.SignupRequest{
+		Email:    "  Alice@Example.com ",
+		Password: "correct horse battery",
+	})
+	if err != nil {
+		t.Fatalf("Signup() error = %v", err)
+	}
+	if signup.Token == "" {
+		t.Fatal("Signup() returned empty token")
+	}
+	if signup.User.Email != "alice@example.com" {
+		t.Errorf("Signup() email = %q, want normalized address", signup.User.Email)
+	}
+	if signup.User.PasswordHash == "correct horse battery" {
+		t.Error("Signup() stored plaintext password")
+	}
+
+	if _, err := repo.GetSessionByTokenHash(ctx, hashToken(signup.Token)); err != nil {
+		t.Errorf("session for signup token not stored: %v", err)
+	}
+
+	login, err := service.Login(ctx, &models.LoginRequest{Email: "alice@example.com", Password: "correct horse battery"})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if login.User.ID != signup.User.ID {
+		t.Errorf("Login() user id = %d, want %d", login.User.ID, signup.User.ID)
+	}
+}
+
+func TestAuthService_SignupErrors(t *testing.T) {
+	service := NewAuthService(repositories.NewMockUserRepository())
+	ctx := context.Background()
+
+	if _, err := service.Signup(ctx, &models.SignupRequest{Email: "bob@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("Signup() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		req        models.SignupRequest
+		wantStatus int
+	}{
+		{"duplicate email", models.SignupRequest{Email: "BOB@example.com", Password: "password123"}, http.StatusConflict},
+		{"invalid email", models.SignupRequest{Email: "not-an-email", Password: "password123"}, http.StatusBadRequest},
+		{"short password", models.SignupRequest{Email: "carol@example.com", Password: "short"}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Signup(ctx, &tt.req)
+			var appErr *utils.AppError
+			if !errors.As(err, &appErr) {
+				t.Fatalf("Signup() error = %v, want AppError", err)
+			}
+			if appErr.Code != tt.wantStatus {
+				t.Errorf("Signup() status = %d, want %d", appErr.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAuthService_LoginWrongPassword(t *testing.T) {
+	service := NewAuthService(repositories.NewMockUserRepository())
+	ctx := context.Background()
+
+	if _, err := service.Signup(ctx, &models.SignupRequest{Email: "dave@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("Signup() error = %v", err)
+	}
+
+	for _, req := range []models.LoginRequest{
+		{Email: "dave@example.com", Password: "wrong-password"},
+		{Email: "nobody@example.com", Password: "password123"},
+	} {
+		_, err := service.Login(ctx, &req)
+		var appErr *utils.AppError
+		if !errors.As(err, &appErr) || appErr.Code != http.StatusUnauthorized {
+			t.Errorf("Login(%s) error = %v, want 401", req.Email, err)
+		}
+	}
+}
+
+func TestAuthService_Authenticate(t *testing.T) {
+	repo := repositories.NewMockUserRepository()
+	service := NewAuthService(repo)
+	ctx := context.Background()
+
+	signup, err := service.Signup(ctx, &models.SignupRequest{Email: "erin@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Signup() error = %v", err)
+	}
+
+	user, err := service.Authenticate(ctx, signup.Token)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if user.ID != signup.User.ID {
+		t.Errorf("Authenticate() user id = %d, want %d", user.ID, signup.User.ID)
+	}
+
+	if _, err := service.Authenticate(ctx, "not-a-token"); err == nil {
+		t.Error("Authenticate() accepted unknown token")
+	}
+
+	if err := service.Logout(ctx, signup.Token); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if _, err := service.Authenticate(ctx, signup.Token); err == nil {
+		t.Error("Authenticate() accepted token after logout")
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+var (
+	errAuthenticationRequired = utils.NewAppError(http.StatusUnauthorized, "Authentication required")
+	errOrganizationRequired   = utils.NewAppError(http.StatusForbidden, "This requires membership of an organization")
+)
+
+// rolePermissions lists what each organization role may do. Watchlists,
+// webhooks and API keys belong to the member who made them; the role limits
+// which of them a member may set up on the organization's plan. Viewers only
+// look: they cannot add watched identities or webhooks that fire on every
+// new breach.
+var rolePermissions = map[string][]string{
+	models.RoleOwner: {
+		models.PermOrgManage, models.PermMembersRead, models.PermMembersManage, models.PermAPIKeysManage, models.PermBulkSearch,
+		models.PermWatchlistRead, models.PermWatchlistWrite, models.PermWebhooksManage, models.PermDomainsRead, models.PermDomainsManage,
+	},
+	models.RoleAdmin: {
+		models.PermMembersRead, models.PermMembersManage, models.PermAPIKeysManage, models.PermBulkSearch,
+		models.PermWatchlistRead, models.PermWatchlistWrite, models.PermWebhooksManage, models.PermDomainsRead, models.PermDomainsManage,
+	},
+	models.RoleAnalyst: {
+		models.PermMembersRead, models.PermAPIKeysManage, models.PermBulkSearch,
+		models.PermWatchlistRead, models.PermWatchlistWrite, models.PermWebhooksManage, models.PermDomainsRead,
+	},
+	models.RoleViewer: {
+		models.PermMembersRead, models.PermWatchlistRead, models.PermDomainsRead,
+	},
+}
+
+// orgPermissions only make sense inside an organization. Accounts without
+// one hold every other permission for their own resources.
+var orgPermissions = []string{models.PermOrgManage, models.PermMembersRead, models.PermMembersManage, models.PermDomainsRead, models.PermDomainsManage}
+
+// RoleHas reports whether role grants permission.
+func RoleHas(role, permission string) bool {
+	return containsField(rolePermissions[role], permission)
+}
+
+// Authorizer decides what the caller of a request may do. Handlers ask it
+// before calling into other services, so role checks live in one place.
+type Authorizer struct {
+	orgRepo repositories.OrganizationRepository
+}
+
+func NewAuthorizer(orgRepo repositories.OrganizationRepository) *Authorizer {
+	return &Authorizer{orgRepo: orgRepo}
+}
+
+// Membership returns the organization userID belongs to, or nil.
+func (a *Authorizer) Membership(ctx context.Context, userID uint64) (*models.Membership, error) {
+	membership, err := a.orgRepo.GetMembership(ctx, userID)
+	if errors.Is(err, repositories.ErrMembershipNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+	return membership, nil
+}
+
+// Authorize returns the caller in ctx and their membership if the caller
+// holds permission. The membership is nil for accounts outside an
+// organization.
+func (a *Authorizer) Authorize(ctx context.Context, permission string) (*models.User, *models.Membership, error) {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		return nil, nil, errAuthenticationRequired
+	}
+
+	membership, loaded := MembershipFromContext(ctx)
+	if !loaded {
+		var err error
+		if membership, err = a.Membership(ctx, user.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if membership == nil {
+		if containsField(orgPermissions, permission) {
+			return nil, nil, errOrganizationRequired
+		}
+		return user, nil, nil
+	}
+	if !RoleHas(membership.Role, permission) {
+		return nil, nil, utils.NewAppError(http.StatusForbidden, fmt.Sprintf("The %s role does not allow this", membership.Role))
+	}
+	return user, membership, nil
+}
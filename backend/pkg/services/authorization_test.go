@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string // "" for an account outside any organization
+		permission string
+		wantStatus int
+	}{
+		{name: "personal account manages own api keys", permission: models.PermAPIKeysManage},
+		{name: "personal account bulk searches", permission: models.PermBulkSearch},
+		{name: "personal account has no members", permission: models.PermMembersRead, wantStatus: http.StatusForbidden},
+		{name: "personal account has no domains", permission: models.PermDomainsRead, wantStatus: http.StatusForbidden},
+		{name: "owner manages organization", role: models.RoleOwner, permission: models.PermOrgManage},
+		{name: "admin cannot manage organization", role: models.RoleAdmin, permission: models.PermOrgManage, wantStatus: http.StatusForbidden},
+		{name: "admin manages members", role: models.RoleAdmin, permission: models.PermMembersManage},
+		{name: "admin manages domains", role: models.RoleAdmin, permission: models.PermDomainsManage},
+		{name: "analyst bulk searches", role: models.RoleAnalyst, permission: models.PermBulkSearch},
+		{name: "analyst writes watchlists", role: models.RoleAnalyst, permission: models.PermWatchlistWrite},
+		{name: "analyst reads domains", role: models.RoleAnalyst, permission: models.PermDomainsRead},
+		{name: "analyst cannot manage domains", role: models.RoleAnalyst, permission: models.PermDomainsManage, wantStatus: http.StatusForbidden},
+		{name: "analyst cannot manage members", role: models.RoleAnalyst, permission: models.PermMembersManage, wantStatus: http.StatusForbidden},
+		{name: "viewer reads watchlists", role: models.RoleViewer, permission: models.PermWatchlistRead},
+		{name: "viewer cannot write watchlists", role: models.RoleViewer, permission: models.PermWatchlistWrite, wantStatus: http.StatusForbidden},
+		{name: "viewer cannot bulk search", role: models.RoleViewer, permission: models.PermBulkSearch, wantStatus: http.StatusForbidden},
+		{name: "viewer cannot manage api keys", role: models.RoleViewer, permission: models.PermAPIKeysManage, wantStatus: http.StatusForbidden},
+		{name: "analyst manages webhooks", role: models.RoleAnalyst, permission: models.PermWebhooksManage},
+		{name: "viewer cannot manage webhooks", role: models.RoleViewer, permission: models.PermWebhooksManage, wantStatus: http.StatusForbidden},
+		{name: "personal account manages own webhooks", permission: models.PermWebhooksManage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authz := NewAuthorizer(repositories.NewMockOrganizationRepository(repositories.NewMockUserRepository()))
+			user := &models.User{ID: 1}
+			var membership *models.Membership
+			if tt.role != "" {
+				membership = &models.Membership{Organization: models.Organization{ID: 5, Plan: "enterprise"}, Role: tt.role}
+			}
+			ctx := ContextWithMembership(ContextWithUser(context.Background(), user), membership)
+
+			gotUser, gotMembership, err := authz.Authorize(ctx, tt.permission)
+			if tt.wantStatus != 0 {
+				var appErr *utils.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantStatus {
+					t.Fatalf("Authorize() error = %v, want status %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authorize() error = %v", err)
+			}
+			if gotUser != user || gotMembership != membership {
+				t.Errorf("Authorize() = %v, %v", gotUser, gotMembership)
+			}
+		})
+	}
+}
+
+func TestAuthorizer_LoadsMembership(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repositories.NewMockUserRepository()
+	orgRepo := repositories.NewMockOrganizationRepository(userRepo)
+	authz := NewAuthorizer(orgRepo)
+
+	user := &models.User{Email: "viewer@example.com"}
+	if err := userRepo.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := orgRepo.CreateOrganization(ctx, &models.Organization{Name: "Acme", Plan: "enterprise"}, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := orgRepo.UpdateMemberRole(ctx, 1, user.ID, models.RoleViewer); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := authz.Authorize(ctx, models.PermWatchlistRead); err == nil {
+		t.Fatal("Authorize() without a caller succeeded")
+	}
+
+	// Without a membership in the context it is looked up
+	ctx = ContextWithUser(ctx, user)
+	if _, _, err := authz.Authorize(ctx, models.PermBulkSearch); err == nil {
+		t.Error("viewer was allowed to bulk search")
+	}
+	_, membership, err := authz.Authorize(ctx, models.PermDomainsRead)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if membership == nil || membership.Name != "Acme" {
+		t.Errorf("Authorize() membership = %+v", membership)
+	}
+}
@@ -2,105 +2,553 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
 	"github.com/Rikjimue/breach-radar/backend/pkg/models"
 	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/scoring"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
 )
 
+// personalSearchConcurrency bounds how many breach tables are queried at once
+// for a single search. It stays well below the database pool size so that
+// concurrent searches do not starve each other.
+const personalSearchConcurrency = 8
+
 type BreachService struct {
-	breachRepo repositories.BreachRepository
+	breachRepo  repositories.BreachRepository
+	schemes     *hashing.Schemes
+	scorer      *scoring.Scorer
+	remediation *RemediationService
+	// bulkSlots is shared by every bulk search, see bulkSearchConcurrency.
+	bulkSlots chan struct{}
+	now       func() time.Time
 }
 
-func NewBreachService(breachRepo repositories.BreachRepository) *BreachService {
-	return &BreachService{breachRepo: breachRepo}
+// NewBreachService searches with the given hash schemes; nil means only v1
+// hashes are stored. A nil scorer scores with the default weights, a nil
+// remediation service attaches no advice.
+func NewBreachService(breachRepo repositories.BreachRepository, schemes *hashing.Schemes, scorer *scoring.Scorer, remediation *RemediationService) *BreachService {
+	if schemes == nil {
+		schemes = hashing.V1Schemes()
+	}
+	if scorer == nil {
+		scorer = scoring.NewScorer(nil)
+	}
+	return &BreachService{
+		breachRepo:  breachRepo,
+		schemes:     schemes,
+		scorer:      scorer,
+		remediation: remediation,
+		bulkSlots:   make(chan struct{}, bulkSearchConcurrency),
+		now:         time.Now,
+	}
 }
 
 func (s *BreachService) BreachSearch(ctx context.Context, req *models.BreachSearchRequest) (interface{}, error) {
+	return s.breachSearch(ctx, req, personalSearchConcurrency)
+}
+
+// breachSearch queries at most concurrency breach tables at once.
+func (s *BreachService) breachSearch(ctx context.Context, req *models.BreachSearchRequest, concurrency int) (interface{}, error) {
+	version := req.HashVersion
+	if version == 0 {
+		version = hashing.SchemeV1
+	}
+
 	if req.Mode == "sensitive" {
-		return s.searchSensitiveData(ctx, req.Fields)
+		// Sensitive rows are only stored as v1, see hashing.SchemeV1
+		if version != hashing.SchemeV1 {
+			return nil, utils.NewAppError(http.StatusBadRequest, "Sensitive searches only accept v1 hashes")
+		}
+		if req.MinOccurrences < 0 {
+			return nil, utils.NewAppError(http.StatusBadRequest, "minOccurrences must not be negative")
+		}
+		return s.searchSensitiveData(ctx, req.Fields, req.Strict, req.MinOccurrences)
+	}
+
+	fieldHashes, err := s.expandHashes(req.Fields, version)
+	if err != nil {
+		return nil, err
+	}
+	return s.searchPersonalData(ctx, fieldHashes, req.Strict, concurrency)
+}
+
+// expandHashes derives the hash of every active scheme from the submitted
+// ones, so rows that have not been rehashed to the latest scheme yet are
+// still found.
+func (s *BreachService) expandHashes(fields map[string]string, version int) (map[string][]string, error) {
+	if !s.schemes.IsKnown(version) {
+		return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unsupported hash version %d", version))
+	}
+
+	fieldHashes := make(map[string][]string, len(fields))
+	for fieldType, hash := range fields {
+		hashes, err := s.schemes.DeriveActive(hash, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive hashes for %s: %w", fieldType, err)
+		}
+		if len(hashes) == 0 {
+			return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Hash version %d is newer than every searchable version", version))
+		}
+		fieldHashes[fieldType] = hashes
 	}
-	return s.searchPersonalData(ctx, req.Fields)
+	return fieldHashes, nil
 }
 
-func (s *BreachService) searchPersonalData(ctx context.Context, fieldHashes map[string]string) (*models.PersonalSearchResponse, error) {
+func (s *BreachService) searchPersonalData(ctx context.Context, fieldHashes map[string][]string, strict bool, concurrency int) (*models.PersonalSearchResponse, error) {
 	fieldNames := make([]string, 0, len(fieldHashes))
 	for field := range fieldHashes {
 		fieldNames = append(fieldNames, field)
 	}
 
+	if index, ok := s.breachRepo.(repositories.BreachHashIndex); ok {
+		return s.searchPersonalDataIndexed(ctx, index, fieldHashes, fieldNames, strict)
+	}
+
 	breaches, err := s.breachRepo.GetBreachesWithFields(ctx, fieldNames)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get breaches: %w", err)
 	}
 
+	// Each slot is filled by whichever worker handles that breach, so the
+	// final order still follows GetBreachesWithFields.
+	matches := make([]*models.ExactMatch, len(breaches))
+	failures := make([]error, len(breaches))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(concurrency, len(breaches)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				matches[idx], failures[idx] = s.matchBreach(ctx, &breaches[idx], fieldHashes)
+			}
+		}()
+	}
+
+dispatch:
+	for idx := range breaches {
+		select {
+		case jobs <- idx:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("personal search interrupted: %w", err)
+	}
+
 	var exactMatches []models.ExactMatch
+	var scored []scoring.MatchedBreach
+	var unchecked []string
+	var errs []error
+	for idx, match := range matches {
+		if failures[idx] != nil {
+			unchecked = append(unchecked, breachLabel(&breaches[idx]))
+			errs = append(errs, failures[idx])
+			continue
+		}
+		if match != nil {
+			exactMatches = append(exactMatches, *match)
+			scored = append(scored, scoredMatch(&breaches[idx], match))
+		}
+	}
 
-	for _, breach := range breaches {
-		matchedFields, err := s.breachRepo.FindExactMatches(ctx, breach.Name, fieldHashes)
+	if err := s.checkComplete(ctx, strict, unchecked, errs); err != nil {
+		return nil, err
+	}
+
+	s.attachPersonalRemediation(ctx, exactMatches, scored)
+
+	return &models.PersonalSearchResponse{
+		ExactMatches:      exactMatches,
+		SearchFields:      fieldNames,
+		Exposure:          s.scorer.Exposure(scored, s.now()),
+		Incomplete:        len(unchecked) > 0,
+		UncheckedBreaches: unchecked,
+	}, nil
+}
+
+// searchPersonalDataIndexed answers a personal search with a single lookup in
+// the global hash index. The index does not know which hashes share a row, so
+// breaches matching several fields are still correlated against their own
+// tables. Breaches missing from the index are reported as unchecked.
+func (s *BreachService) searchPersonalDataIndexed(ctx context.Context, index repositories.BreachHashIndex, fieldHashes map[string][]string, fieldNames []string, strict bool) (*models.PersonalSearchResponse, error) {
+	found, err := index.FindBreachesByHashes(ctx, fieldHashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search breach index: %w", err)
+	}
+	unindexed, err := index.UnindexedBreaches(ctx, fieldNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unindexed breaches: %w", err)
+	}
+
+	var exactMatches []models.ExactMatch
+	var scored []scoring.MatchedBreach
+	var unchecked []string
+	var errs []error
+	for i := range unindexed {
+		unchecked = append(unchecked, breachLabel(&unindexed[i]))
+		errs = append(errs, fmt.Errorf("breach %s is not indexed", unindexed[i].Name))
+	}
+	for i := range found {
+		breach := &found[i].Breach
+		records, err := s.findRecordMatches(ctx, breach, found[i].MatchedFields, fieldHashes)
 		if err != nil {
+			unchecked = append(unchecked, breachLabel(breach))
+			errs = append(errs, err)
 			continue
 		}
+		match := s.newExactMatch(breach, found[i].MatchedFields, records, fieldHashes)
+		exactMatches = append(exactMatches, match)
+		scored = append(scored, scoredMatch(breach, &match))
+	}
 
-		if len(matchedFields) > 0 {
-			isPartialMatch := len(matchedFields) < len(fieldHashes)
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("personal search interrupted: %w", err)
+	}
+	if err := s.checkComplete(ctx, strict, unchecked, errs); err != nil {
+		return nil, err
+	}
 
-			exactMatch := models.ExactMatch{
-				Name:            breach.DisplayName, // Use display_name instead of name
-				Date:            breach.Date.Format("2006-01-02"),
-				AffectedRecords: s.formatRecordCount(int(breach.AffectedRecords)),
-				MatchedFields:   matchedFields,
-				PartialMatch:    isPartialMatch,
+	s.attachPersonalRemediation(ctx, exactMatches, scored)
+
+	return &models.PersonalSearchResponse{
+		ExactMatches:      exactMatches,
+		SearchFields:      fieldNames,
+		Exposure:          s.scorer.Exposure(scored, s.now()),
+		Incomplete:        len(unchecked) > 0,
+		UncheckedBreaches: unchecked,
+	}, nil
+}
+
+// matchBreach checks the fields that breach actually holds and returns nil
+// when none of them match.
+func (s *BreachService) matchBreach(ctx context.Context, breach *models.BreachMetadata, fieldHashes map[string][]string) (*models.ExactMatch, error) {
+	present := make(map[string][]string, len(fieldHashes))
+	for _, field := range breach.Fields {
+		if hashes, ok := fieldHashes[field]; ok {
+			present[field] = hashes
+		}
+	}
+
+	matchedFields, err := s.breachRepo.FindExactMatches(ctx, breach.Name, present)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check breach %s: %w", breach.Name, err)
+	}
+	if len(matchedFields) == 0 {
+		return nil, nil
+	}
+
+	records, err := s.findRecordMatches(ctx, breach, matchedFields, fieldHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	match := s.newExactMatch(breach, matchedFields, records, fieldHashes)
+	return &match, nil
+}
+
+// findRecordMatches looks up which of the matched fields occur in the same
+// rows. A single matched field cannot co-occur with anything, so the query
+// is skipped.
+func (s *BreachService) findRecordMatches(ctx context.Context, breach *models.BreachMetadata, matchedFields []string, fieldHashes map[string][]string) ([][]string, error) {
+	if len(matchedFields) < 2 {
+		return nil, nil
+	}
+
+	matched := make(map[string][]string, len(matchedFields))
+	for _, field := range matchedFields {
+		matched[field] = fieldHashes[field]
+	}
+
+	records, err := s.breachRepo.FindRecordMatches(ctx, breach.Name, matched)
+	if err != nil {
+		return nil, fmt.Errorf("failed to correlate records in breach %s: %w", breach.Name, err)
+	}
+	return records, nil
+}
+
+func (s *BreachService) newExactMatch(breach *models.BreachMetadata, matchedFields []string, records [][]string, fieldHashes map[string][]string) models.ExactMatch {
+	sameRecord, independent := groupMatchedFields(matchedFields, records)
+	score := s.scorer.ScoreBreach(breach, s.now())
+	return models.ExactMatch{
+		Name:              breach.DisplayName, // Use display_name instead of name
+		Date:              breach.Date.Format("2006-01-02"),
+		AffectedRecords:   formatRecordCount(int(breach.AffectedRecords)),
+		MatchedFields:     matchedFields,
+		PartialMatch:      len(matchedFields) < len(fieldHashes),
+		Severity:          score.Severity,
+		RiskScore:         score.Score,
+		SameRecordFields:  sameRecord,
+		IndependentFields: independent,
+	}
+}
+
+// attachPersonalRemediation adds advice to every match; scored describes the
+// matches in the same order.
+func (s *BreachService) attachPersonalRemediation(ctx context.Context, matches []models.ExactMatch, scored []scoring.MatchedBreach) {
+	var fieldTypes, breachNames []string
+	for _, match := range scored {
+		fieldTypes = append(fieldTypes, match.MatchedFields...)
+		breachNames = append(breachNames, match.Breach.Name)
+	}
+	guidance := s.loadGuidance(ctx, fieldTypes, breachNames)
+	for i := range matches {
+		matches[i].Remediation = guidance.For(scored[i].Breach.Name, scored[i].MatchedFields)
+	}
+}
+
+// loadGuidance returns nil when no remediation service is configured or the
+// advice cannot be loaded; advice is not worth failing a search over.
+func (s *BreachService) loadGuidance(ctx context.Context, fieldTypes, breachNames []string) *Guidance {
+	if s.remediation == nil || len(breachNames) == 0 {
+		return nil
+	}
+	guidance, err := s.remediation.Guidance(ctx, fieldTypes, breachNames)
+	if err != nil {
+		RequestLogger(ctx).WarnContext(ctx, "Search results sent without remediation", "error", err)
+		return nil
+	}
+	return guidance
+}
+
+func scoredMatch(breach *models.BreachMetadata, match *models.ExactMatch) scoring.MatchedBreach {
+	return scoring.MatchedBreach{
+		Breach:            breach,
+		MatchedFields:     match.MatchedFields,
+		IndependentFields: match.IndependentFields,
+	}
+}
+
+// groupMatchedFields splits the matched fields into groups found together in
+// one record and fields only found on their own. Groups contained in a larger
+// group add nothing and are dropped; the rest are sorted largest first.
+func groupMatchedFields(matchedFields []string, records [][]string) ([][]string, []string) {
+	sameRecord := [][]string{}
+	for i, record := range records {
+		redundant := false
+		for j, other := range records {
+			if i != j && len(other) >= len(record) && isSubset(record, other) && (len(other) > len(record) || j < i) {
+				redundant = true
+				break
 			}
-			exactMatches = append(exactMatches, exactMatch)
+		}
+		if !redundant {
+			group := append([]string(nil), record...)
+			sort.Strings(group)
+			sameRecord = append(sameRecord, group)
 		}
 	}
+	sort.Slice(sameRecord, func(i, j int) bool {
+		if len(sameRecord[i]) != len(sameRecord[j]) {
+			return len(sameRecord[i]) > len(sameRecord[j])
+		}
+		return strings.Join(sameRecord[i], ",") < strings.Join(sameRecord[j], ",")
+	})
 
-	return &models.PersonalSearchResponse{
-		ExactMatches: exactMatches,
-		SearchFields: fieldNames,
-	}, nil
+	correlated := make(map[string]bool)
+	for _, group := range sameRecord {
+		for _, field := range group {
+			correlated[field] = true
+		}
+	}
+	independent := []string{}
+	for _, field := range matchedFields {
+		if !correlated[field] {
+			independent = append(independent, field)
+		}
+	}
+	sort.Strings(independent)
+
+	return sameRecord, independent
+}
+
+func isSubset(subset, set []string) bool {
+	for _, field := range subset {
+		found := false
+		for _, other := range set {
+			if field == other {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
-func (s *BreachService) searchSensitiveData(ctx context.Context, fieldHashes map[string]string) (*models.SensitiveSearchResponse, error) {
+func (s *BreachService) searchSensitiveData(ctx context.Context, fieldHashes map[string]string, strict bool, minOccurrences int) (*models.SensitiveSearchResponse, error) {
 	var candidateBreaches []models.BreachCandidate
+	var candidateSources []string
+	var uncheckedBreaches, uncheckedFields []string
+	var errs []error
 
 	for fieldType, partialHash := range fieldHashes {
-		breachCandidates, err := s.breachRepo.FindSensitiveMatches(ctx, fieldType, partialHash)
+		breachHashes, err := s.breachRepo.FindSensitiveMatches(ctx, fieldType, partialHash)
 		if err != nil {
+			uncheckedFields = append(uncheckedFields, fieldType)
+			errs = append(errs, fmt.Errorf("failed to check %s: %w", fieldType, err))
 			continue
 		}
 
-		for breachSource, hashes := range breachCandidates {
+		breachSources := make([]string, 0, len(breachHashes))
+		for breachSource := range breachHashes {
+			breachSources = append(breachSources, breachSource)
+		}
+		sort.Strings(breachSources)
+
+		// Prevalence spans every breach, so load them all before building
+		// any candidate
+		breaches := make(map[string]*models.BreachMetadata, len(breachSources))
+		for _, breachSource := range breachSources {
 			metadata, err := s.breachRepo.GetBreachMetadata(ctx, breachSource)
 			if err != nil {
+				uncheckedBreaches = append(uncheckedBreaches, breachSource)
+				errs = append(errs, err)
+				continue
+			}
+			breaches[breachSource] = metadata
+		}
+		prevalence := hashPrevalence(breachHashes, breaches)
+
+		for _, breachSource := range breachSources {
+			metadata, ok := breaches[breachSource]
+			if !ok {
+				continue
+			}
+
+			var hashes []string
+			hashStats := make(map[string]models.HashPrevalence)
+			for hash, occurrences := range breachHashes[breachSource] {
+				stats := prevalence[hash]
+				if stats.TotalOccurrences < minOccurrences {
+					continue
+				}
+				stats.Occurrences = occurrences
+				hashes = append(hashes, hash)
+				hashStats[hash] = stats
+			}
+			if len(hashes) == 0 {
 				continue
 			}
+			sort.Strings(hashes)
 
+			score := s.scorer.ScoreBreach(metadata, s.now())
 			candidate := models.BreachCandidate{
 				Name:            metadata.DisplayName, // Use display_name instead of name
 				Date:            metadata.Date.Format("2006-01-02"),
-				AffectedRecords: s.formatRecordCount(int(metadata.AffectedRecords)),
+				AffectedRecords: formatRecordCount(int(metadata.AffectedRecords)),
 				HashCandidates:  map[string][]string{fieldType: hashes},
 				PartialMatch:    false,
+				Severity:        score.Severity,
+				RiskScore:       score.Score,
+				HashPrevalence:  hashStats,
 			}
 
 			candidateBreaches = append(candidateBreaches, candidate)
+			candidateSources = append(candidateSources, breachSource)
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("sensitive search interrupted: %w", err)
+	}
+	if err := s.checkComplete(ctx, strict, append(uncheckedFields, uncheckedBreaches...), errs); err != nil {
+		return nil, err
+	}
+
 	fieldNames := make([]string, 0, len(fieldHashes))
 	for field := range fieldHashes {
 		fieldNames = append(fieldNames, field)
 	}
 
+	guidance := s.loadGuidance(ctx, fieldNames, candidateSources)
+	for i := range candidateBreaches {
+		var fieldTypes []string
+		for fieldType := range candidateBreaches[i].HashCandidates {
+			fieldTypes = append(fieldTypes, fieldType)
+		}
+		candidateBreaches[i].Remediation = guidance.For(candidateSources[i], fieldTypes)
+	}
+
 	return &models.SensitiveSearchResponse{
 		CandidateBreaches: candidateBreaches,
 		SearchFields:      fieldNames,
+		Incomplete:        len(uncheckedBreaches) > 0 || len(uncheckedFields) > 0,
+		UncheckedBreaches: uncheckedBreaches,
+		UncheckedFields:   uncheckedFields,
 	}, nil
 }
 
-func (s *BreachService) formatRecordCount(count int) string {
+// hashPrevalence totals each hash's occurrences across breaches. Breaches
+// whose metadata could not be loaded still count, but cannot date the hash.
+func hashPrevalence(breachHashes map[string]map[string]int, breaches map[string]*models.BreachMetadata) map[string]models.HashPrevalence {
+	prevalence := make(map[string]models.HashPrevalence)
+	for breachSource, hashes := range breachHashes {
+		metadata := breaches[breachSource]
+		for hash, occurrences := range hashes {
+			stats := prevalence[hash]
+			stats.TotalOccurrences += occurrences
+			stats.Breaches++
+			if metadata != nil {
+				date := metadata.Date.Format("2006-01-02")
+				if stats.FirstSeen == "" || date < stats.FirstSeen {
+					stats.FirstSeen = date
+				}
+				if date > stats.LastSeen {
+					stats.LastSeen = date
+				}
+			}
+			prevalence[hash] = stats
+		}
+	}
+	return prevalence
+}
+
+// checkComplete decides what happens when parts of a search failed. In strict
+// mode the search fails; otherwise the failures are logged and the caller
+// marks its response incomplete.
+func (s *BreachService) checkComplete(ctx context.Context, strict bool, unchecked []string, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	cause := errors.Join(errs...)
+	if strict {
+		return &utils.PartialResultError{
+			AppError:  utils.WrapAppError(http.StatusServiceUnavailable, fmt.Sprintf("%d sources could not be checked", len(unchecked)), cause),
+			Unchecked: unchecked,
+		}
+	}
+
+	RequestLogger(ctx).WarnContext(ctx, "Search incomplete", "unchecked", len(unchecked), "error", cause)
+	return nil
+}
+
+// breachLabel names a breach the way users see it.
+func breachLabel(breach *models.BreachMetadata) string {
+	if breach.DisplayName != "" {
+		return breach.DisplayName
+	}
+	return breach.Name
+}
+
+func formatRecordCount(count int) string {
 	if count >= 1000000000 {
 		return fmt.Sprintf("%.1fB", float64(count)/1000000000)
 	} else if count >= 1000000 {
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+const linkedinFirstNameHash = "f1e2d3c4b5a6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890"
+
+func TestBreachService_PersonalSearch(t *testing.T) {
+	service := NewBreachService(repositories.NewMockBreachRepository(), nil, nil, nil)
+
+	result, err := service.BreachSearch(context.Background(), &models.BreachSearchRequest{
+		Mode: "personal",
+		Fields: map[string]string{
+			"email":     linkedinEmailHash,
+			"firstName": linkedinFirstNameHash,
+		},
+	})
+	if err != nil {
+		t.Fatalf("BreachSearch() error = %v", err)
+	}
+
+	resp := result.(*models.PersonalSearchResponse)
+	matched := make(map[string]models.ExactMatch)
+	for _, match := range resp.ExactMatches {
+		matched[match.Date] = match
+	}
+
+	// LinkedIn holds both fields; Facebook only holds firstName
+	if linkedin := matched["2021-06-18"]; len(linkedin.MatchedFields) != 2 || linkedin.PartialMatch {
+		t.Errorf("linkedin match = %+v, want both fields and no partial match", linkedin)
+	}
+	if facebook := matched["2019-09-04"]; len(facebook.MatchedFields) != 1 || !facebook.PartialMatch {
+		t.Errorf("facebook match = %+v, want a partial match on firstName", facebook)
+	}
+
+	for date, match := range matched {
+		if match.Severity == "" || match.RiskScore == 0 {
+			t.Errorf("match %s is not scored: %+v", date, match)
+		}
+	}
+	if resp.Exposure.Score == 0 || resp.Exposure.Severity == "" {
+		t.Errorf("Exposure = %+v, want a score", resp.Exposure)
+	}
+}
+
+func TestBreachService_PersonalSearchCancelled(t *testing.T) {
+	service := NewBreachService(repositories.NewMockBreachRepository(), nil, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := service.BreachSearch(ctx, &models.BreachSearchRequest{
+		Mode:   "personal",
+		Fields: map[string]string{"firstName": linkedinFirstNameHash},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("BreachSearch() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestBreachService_PersonalSearchIndexed(t *testing.T) {
+	tableService := NewBreachService(repositories.NewMockBreachRepository(), nil, nil, nil)
+	indexService := NewBreachService(repositories.NewMockIndexedBreachRepository(), nil, nil, nil)
+
+	req := &models.BreachSearchRequest{
+		Mode: "personal",
+		Fields: map[string]string{
+			"email":     linkedinEmailHash,
+			"firstName": linkedinFirstNameHash,
+		},
+	}
+
+	tableResult, err := tableService.BreachSearch(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexResult, err := indexService.BreachSearch(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byTable := tableResult.(*models.PersonalSearchResponse).ExactMatches
+	byIndex := indexResult.(*models.PersonalSearchResponse).ExactMatches
+	if len(byTable) != len(byIndex) {
+		t.Fatalf("index strategy found %d breaches, table strategy found %d", len(byIndex), len(byTable))
+	}
+	tableByDate := make(map[string]models.ExactMatch)
+	for _, match := range byTable {
+		tableByDate[match.Date] = match
+	}
+	for _, match := range byIndex {
+		if want, ok := tableByDate[match.Date]; !ok || want.PartialMatch != match.PartialMatch || len(want.MatchedFields) != len(match.MatchedFields) {
+			t.Errorf("index match %+v, table match %+v", match, want)
+		}
+	}
+}
+
+func TestBreachService_PersonalSearchIndexedReportsUnindexed(t *testing.T) {
+	repo := repositories.NewMockIndexedBreachRepository()
+	repo.MarkUnindexed("breach_linkedin_2021")
+	service := NewBreachService(repo, nil, nil, nil)
+
+	req := &models.BreachSearchRequest{Mode: "personal", Fields: map[string]string{"email": linkedinEmailHash}}
+	result, err := service.BreachSearch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("BreachSearch() error = %v", err)
+	}
+	response := result.(*models.PersonalSearchResponse)
+	if len(response.ExactMatches) != 0 || !response.Incomplete {
+		t.Fatalf("BreachSearch() = %+v, want no matches and an incomplete result", response)
+	}
+	if len(response.UncheckedBreaches) != 1 || response.UncheckedBreaches[0] != "breach_linkedin_2021" {
+		t.Errorf("UncheckedBreaches = %v, want [breach_linkedin_2021]", response.UncheckedBreaches)
+	}
+
+	req.Strict = true
+	_, err = service.BreachSearch(context.Background(), req)
+	var partialErr *utils.PartialResultError
+	if !errors.As(err, &partialErr) || partialErr.Code != http.StatusServiceUnavailable {
+		t.Errorf("strict BreachSearch() error = %v, want 503", err)
+	}
+}
+
+// failingBreachRepository fails every lookup against one breach table.
+type failingBreachRepository struct {
+	*repositories.MockBreachRepository
+	broken string
+}
+
+func (r *failingBreachRepository) FindExactMatches(ctx context.Context, breachName string, fieldHashes map[string][]string) ([]string, error) {
+	if breachName == r.broken {
+		return nil, errors.New("relation does not exist")
+	}
+	return r.MockBreachRepository.FindExactMatches(ctx, breachName, fieldHashes)
+}
+
+func (r *failingBreachRepository) FindPresentHashes(ctx context.Context, breachName, fieldType string, hashes []string) ([]string, error) {
+	if breachName == r.broken {
+		return nil, errors.New("relation does not exist")
+	}
+	return r.MockBreachRepository.FindPresentHashes(ctx, breachName, fieldType, hashes)
+}
+
+func TestBreachService_PersonalSearchReportsUncheckedBreaches(t *testing.T) {
+	repo := &failingBreachRepository{
+		MockBreachRepository: repositories.NewMockBreachRepository(),
+		broken:               "breach_facebook_2019",
+	}
+	service := NewBreachService(repo, nil, nil, nil)
+	req := &models.BreachSearchRequest{
+		Mode:   "personal",
+		Fields: map[string]string{"firstName": linkedinFirstNameHash},
+	}
+
+	result, err := service.BreachSearch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("BreachSearch() error = %v", err)
+	}
+	resp := result.(*models.PersonalSearchResponse)
+	if !resp.Incomplete {
+		t.Error("response with a failed breach is not marked incomplete")
+	}
+	if len(resp.UncheckedBreaches) != 1 || resp.UncheckedBreaches[0] != "breach_facebook_2019" {
+		t.Errorf("UncheckedBreaches = %v, want [breach_facebook_2019]", resp.UncheckedBreaches)
+	}
+	if len(resp.ExactMatches) != 1 {
+		t.Errorf("got %d matches from healthy breaches, want 1", len(resp.ExactMatches))
+	}
+
+	req.Strict = true
+	_, err = service.BreachSearch(context.Background(), req)
+	var partialErr *utils.PartialResultError
+	if !errors.As(err, &partialErr) {
+		t.Fatalf("strict BreachSearch() error = %v, want PartialResultError", err)
+	}
+	if partialErr.Code != http.StatusServiceUnavailable || len(partialErr.Unchecked) != 1 {
+		t.Errorf("strict error = %d %v", partialErr.Code, partialErr.Unchecked)
+	}
+}
+
+func TestBreachService_HashSchemes(t *testing.T) {
+	pepper := "00112233445566778899aabbccddeeff"
+	searchable, err := hashing.ParseSchemes("2:"+pepper, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	retired, err := hashing.ParseSchemes("2:"+pepper, "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2Hash, err := searchable.Derive(linkedinFirstNameHash, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		schemes     *hashing.Schemes
+		mode        string
+		hashVersion int
+		hash        string
+		wantMatches int
+		wantStatus  int
+	}{
+		// The mock stores v1 hashes
+		{name: "v1 rows still searched", schemes: searchable, mode: "personal", hash: linkedinFirstNameHash, wantMatches: 2},
+		{name: "v1 rows retired", schemes: retired, mode: "personal", hash: linkedinFirstNameHash, wantMatches: 0},
+		{name: "v2 request cannot reach v1 rows", schemes: searchable, mode: "personal", hashVersion: 2, hash: v2Hash, wantMatches: 0},
+		{name: "unknown version", schemes: searchable, mode: "personal", hashVersion: 3, hash: v2Hash, wantStatus: http.StatusBadRequest},
+		{name: "sensitive v2", schemes: searchable, mode: "sensitive", hashVersion: 2, hash: "a1b2c3d4", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewBreachService(repositories.NewMockBreachRepository(), tt.schemes, nil, nil)
+
+			field := "firstName"
+			if tt.mode == "sensitive" {
+				field = "password"
+			}
+			result, err := service.BreachSearch(context.Background(), &models.BreachSearchRequest{
+				Mode:        tt.mode,
+				Fields:      map[string]string{field: tt.hash},
+				HashVersion: tt.hashVersion,
+			})
+			if tt.wantStatus != 0 {
+				var appErr *utils.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantStatus {
+					t.Fatalf("BreachSearch() error = %v, want status %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BreachSearch() error = %v", err)
+			}
+			if got := len(result.(*models.PersonalSearchResponse).ExactMatches); got != tt.wantMatches {
+				t.Errorf("got %d matches, want %d", got, tt.wantMatches)
+			}
+		})
+	}
+}
+
+func TestBreachService_SensitivePrevalence(t *testing.T) {
+	const common = "a1b2c3d4e5f6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890"
+	repo := repositories.NewMockBreachRepository()
+	repo.AddSensitiveEntry("password", repositories.SensitiveEntry{BreachSource: "breach_passwords_2020", Hash: common, Occurrences: 41})
+	repo.AddSensitiveEntry("password", repositories.SensitiveEntry{BreachSource: "breach_linkedin_2021", Hash: common, Occurrences: 8})
+	service := NewBreachService(repo, nil, nil, nil)
+
+	search := func(minOccurrences int) *models.SensitiveSearchResponse {
+		t.Helper()
+		result, err := service.BreachSearch(context.Background(), &models.BreachSearchRequest{
+			Mode:           "sensitive",
+			Fields:         map[string]string{"password": "a1b2c3d4"},
+			MinOccurrences: minOccurrences,
+		})
+		if err != nil {
+			t.Fatalf("BreachSearch() error = %v", err)
+		}
+		return result.(*models.SensitiveSearchResponse)
+	}
+
+	resp := search(0)
+	if len(resp.CandidateBreaches) != 2 {
+		t.Fatalf("got %d candidate breaches, want 2", len(resp.CandidateBreaches))
+	}
+	for _, candidate := range resp.CandidateBreaches {
+		stats, ok := candidate.HashPrevalence[common]
+		if !ok {
+			t.Fatalf("candidate %s has no prevalence for the common hash", candidate.Date)
+		}
+		want := models.HashPrevalence{TotalOccurrences: 50, Breaches: 2, FirstSeen: "2020-03-15", LastSeen: "2021-06-18"}
+		want.Occurrences = map[string]int{"2020-03-15": 42, "2021-06-18": 8}[candidate.Date]
+		if stats != want {
+			t.Errorf("prevalence in %s = %+v, want %+v", candidate.Date, stats, want)
+		}
+	}
+
+	// Only the common hash survives a threshold, in both breaches
+	resp = search(50)
+	for _, candidate := range resp.CandidateBreaches {
+		if hashes := candidate.HashCandidates["password"]; len(hashes) != 1 || hashes[0] != common {
+			t.Errorf("candidate %s hashes = %v, want only the common hash", candidate.Date, hashes)
+		}
+	}
+	if len(resp.CandidateBreaches) != 2 {
+		t.Errorf("got %d candidate breaches above threshold, want 2", len(resp.CandidateBreaches))
+	}
+
+	if resp := search(51); len(resp.CandidateBreaches) != 0 {
+		t.Errorf("got %d candidate breaches above an unreached threshold, want 0", len(resp.CandidateBreaches))
+	}
+
+	_, err := service.BreachSearch(context.Background(), &models.BreachSearchRequest{
+		Mode:           "sensitive",
+		Fields:         map[string]string{"password": "a1b2c3d4"},
+		MinOccurrences: -1,
+	})
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusBadRequest {
+		t.Errorf("negative MinOccurrences error = %v, want status 400", err)
+	}
+}
+
+func TestBreachService_RecordCorrelation(t *testing.T) {
+	const smithHash = "5a1e5a1e5a1e789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890"
+
+	tests := []struct {
+		name            string
+		fields          map[string]string
+		wantSameRecord  [][]string
+		wantIndependent []string
+	}{
+		{
+			name:            "fields from different people",
+			fields:          map[string]string{"email": linkedinEmailHash, "lastName": smithHash},
+			wantSameRecord:  [][]string{},
+			wantIndependent: []string{"email", "lastName"},
+		},
+		{
+			name:            "profile plus a common surname",
+			fields:          map[string]string{"email": linkedinEmailHash, "firstName": linkedinFirstNameHash, "lastName": smithHash},
+			wantSameRecord:  [][]string{{"email", "firstName"}},
+			wantIndependent: []string{"lastName"},
+		},
+		{
+			name:            "single field",
+			fields:          map[string]string{"lastName": smithHash},
+			wantSameRecord:  [][]string{},
+			wantIndependent: []string{"lastName"},
+		},
+	}
+
+	table := repositories.NewMockBreachRepository()
+	table.AddPersonalRecord("breach_linkedin_2021", map[string]string{"lastName": smithHash})
+	index := repositories.NewMockIndexedBreachRepository()
+	index.AddPersonalRecord("breach_linkedin_2021", map[string]string{"lastName": smithHash})
+	strategies := map[string]repositories.BreachRepository{"table": table, "index": index}
+
+	for strategy, repo := range strategies {
+		service := NewBreachService(repo, nil, nil, nil)
+		for _, tt := range tests {
+			t.Run(strategy+"/"+tt.name, func(t *testing.T) {
+				result, err := service.BreachSearch(context.Background(), &models.BreachSearchRequest{Mode: "personal", Fields: tt.fields})
+				if err != nil {
+					t.Fatalf("BreachSearch() error = %v", err)
+				}
+
+				var linkedin *models.ExactMatch
+				for _, match := range result.(*models.PersonalSearchResponse).ExactMatches {
+					if match.Date == "2021-06-18" {
+						linkedin = &match
+					}
+				}
+				if linkedin == nil {
+					t.Fatal("no match in the linkedin breach")
+				}
+				if !reflect.DeepEqual(linkedin.SameRecordFields, tt.wantSameRecord) {
+					t.Errorf("SameRecordFields = %v, want %v", linkedin.SameRecordFields, tt.wantSameRecord)
+				}
+				if !reflect.DeepEqual(linkedin.IndependentFields, tt.wantIndependent) {
+					t.Errorf("IndependentFields = %v, want %v", linkedin.IndependentFields, tt.wantIndependent)
+				}
+			})
+		}
+	}
+}
+
+func TestGroupMatchedFields(t *testing.T) {
+	sameRecord, independent := groupMatchedFields(
+		[]string{"phone", "email", "firstName", "lastName"},
+		[][]string{{"firstName", "email"}, {"email", "firstName", "lastName"}, {"lastName", "firstName"}},
+	)
+	if want := [][]string{{"email", "firstName", "lastName"}}; !reflect.DeepEqual(sameRecord, want) {
+		t.Errorf("same record groups = %v, want %v", sameRecord, want)
+	}
+	if want := []string{"phone"}; !reflect.DeepEqual(independent, want) {
+		t.Errorf("independent fields = %v, want %v", independent, want)
+	}
+}
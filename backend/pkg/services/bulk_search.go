@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+const (
+	// All bulk searches together search at most bulkSearchConcurrency
+	// identities at a time, each querying at most bulkSearchFanOut breach
+	// tables at once. The product, not each factor, is what the database
+	// pool sees, so it is kept well below the pool size to leave room for
+	// interactive searches.
+	bulkSearchConcurrency = 4
+	bulkSearchFanOut      = 2
+	bulkSearchItemTimeout = 30 * time.Second
+	MaxBulkIdentities     = 10000
+)
+
+// BulkSearch runs every identity returned by next through BreachSearch with
+// concurrency bounded across all bulk searches. Each identity is searched
+// with the Mode, Strict and MinOccurrences of base. next returns io.EOF when
+// the input is exhausted. Each result is passed to emit as soon as its search
+// finishes, so neither the input nor the output is ever held in memory as a
+// whole. emit is never called concurrently. validate, if not nil, checks an
+// identity's fields before it is searched; an AppError fails that identity
+// only.
+func (s *BreachService) BulkSearch(ctx context.Context, base models.BreachSearchRequest, validate func(fields map[string]string) error, next func() (*models.BulkSearchItem, error), emit func(*models.BulkSearchResult) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	items := make(chan *models.BulkSearchItem)
+	results := make(chan *models.BulkSearchResult)
+
+	// readErr is only read after results is closed, which happens after the
+	// reader goroutine has returned.
+	var readErr error
+	go func() {
+		defer close(items)
+		for count := 1; ; count++ {
+			item, err := next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				readErr = err
+				return
+			}
+			if count > MaxBulkIdentities {
+				readErr = utils.NewAppError(http.StatusRequestEntityTooLarge, fmt.Sprintf("At most %d identities can be searched per request", MaxBulkIdentities))
+				return
+			}
+			select {
+			case items <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < bulkSearchConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range items {
+				select {
+				case s.bulkSlots <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				result := s.searchBulkItem(ctx, base, validate, item)
+				<-s.bulkSlots
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for result := range results {
+		if err := emit(result); err != nil {
+			cancel()
+			for range results {
+			}
+			return err
+		}
+	}
+
+	if readErr != nil {
+		return readErr
+	}
+	return ctx.Err()
+}
+
+func (s *BreachService) searchBulkItem(ctx context.Context, req models.BreachSearchRequest, validate func(map[string]string) error, item *models.BulkSearchItem) *models.BulkSearchResult {
+	if len(item.Fields) == 0 {
+		return &models.BulkSearchResult{ID: item.ID, Error: "Missing required fields"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bulkSearchItemTimeout)
+	defer cancel()
+
+	var result interface{}
+	var err error
+	if validate != nil {
+		err = validate(item.Fields)
+	}
+	if err == nil {
+		req.Fields = item.Fields
+		req.HashVersion = item.HashVersion
+		result, err = s.breachSearch(ctx, &req, bulkSearchFanOut)
+	}
+	if err != nil {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) {
+			return &models.BulkSearchResult{ID: item.ID, Error: appErr.Message}
+		}
+		// item.ID is chosen by the client, so it is not logged
+		RequestLogger(ctx).ErrorContext(ctx, "Bulk search item failed", "error", err)
+		return &models.BulkSearchResult{ID: item.ID, Error: "Search failed"}
+	}
+
+	return &models.BulkSearchResult{ID: item.ID, Result: result}
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+const linkedinEmailHash = "a1b2c3d4e5f6789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890"
+
+func sliceItemReader(items []models.BulkSearchItem) func() (*models.BulkSearchItem, error) {
+	i := 0
+	return func() (*models.BulkSearchItem, error) {
+		if i >= len(items) {
+			return nil, io.EOF
+		}
+		i++
+		return &items[i-1], nil
+	}
+}
+
+func TestBreachService_BulkSearch(t *testing.T) {
+	service := NewBreachService(repositories.NewMockBreachRepository(), nil, nil, nil)
+
+	items := []models.BulkSearchItem{
+		{ID: "breached", Fields: map[string]string{"email": linkedinEmailHash}},
+		{ID: "clean", Fields: map[string]string{"email": "0000"}},
+		{ID: "empty", Fields: map[string]string{}},
+		{ID: "unsearchable", Fields: map[string]string{"ssn": "a1b2c"}},
+	}
+	for i := 0; i < 50; i++ {
+		items = append(items, models.BulkSearchItem{ID: "filler", Fields: map[string]string{"phone": "1234"}})
+	}
+
+	results := make(map[string]*models.BulkSearchResult)
+	count := 0
+	validate := func(fields map[string]string) error {
+		if _, ok := fields["ssn"]; ok {
+			return utils.NewAppError(http.StatusBadRequest, "ssn cannot be searched in personal mode")
+		}
+		return nil
+	}
+	err := service.BulkSearch(context.Background(), models.BreachSearchRequest{Mode: "personal"}, validate, sliceItemReader(items), func(result *models.BulkSearchResult) error {
+		results[result.ID] = result
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("BulkSearch() error = %v", err)
+	}
+	if count != len(items) {
+		t.Fatalf("BulkSearch() emitted %d results, want %d", count, len(items))
+	}
+
+	breached, ok := results["breached"].Result.(*models.PersonalSearchResponse)
+	if !ok || len(breached.ExactMatches) != 1 {
+		t.Errorf("breached identity result = %+v, want one exact match", results["breached"].Result)
+	}
+	clean, ok := results["clean"].Result.(*models.PersonalSearchResponse)
+	if !ok || len(clean.ExactMatches) != 0 {
+		t.Errorf("clean identity result = %+v, want no matches", results["clean"].Result)
+	}
+	if results["empty"].Error == "" {
+		t.Error("identity without fields did not report an error")
+	}
+	// A field the mode cannot search fails that identity, not the stream
+	if got := results["unsearchable"].Error; got != "ssn cannot be searched in personal mode" {
+		t.Errorf("unsearchable identity error = %q", got)
+	}
+}
+
+func TestBreachService_BulkSearchStopsOnEmitError(t *testing.T) {
+	service := NewBreachService(repositories.NewMockBreachRepository(), nil, nil, nil)
+
+	items := make([]models.BulkSearchItem, 100)
+	for i := range items {
+		items[i] = models.BulkSearchItem{ID: "x", Fields: map[string]string{"email": linkedinEmailHash}}
+	}
+
+	emitted := 0
+	err := service.BulkSearch(context.Background(), models.BreachSearchRequest{Mode: "personal"}, nil, sliceItemReader(items), func(*models.BulkSearchResult) error {
+		emitted++
+		return io.ErrClosedPipe
+	})
+	if err != io.ErrClosedPipe {
+		t.Errorf("BulkSearch() error = %v, want emit error", err)
+	}
+	if emitted != 1 {
+		t.Errorf("emit called %d times after failing, want 1", emitted)
+	}
+}
+
+// concurrencyRecorder tracks how many breach tables are queried at once.
+type concurrencyRecorder struct {
+	*repositories.MockBreachRepository
+	mu      sync.Mutex
+	current int
+	peak    int
+}
+
+func (r *concurrencyRecorder) FindExactMatches(ctx context.Context, breachName string, fieldHashes map[string][]string) ([]string, error) {
+	r.mu.Lock()
+	r.current++
+	r.peak = max(r.peak, r.current)
+	r.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	r.mu.Lock()
+	r.current--
+	r.mu.Unlock()
+	return r.MockBreachRepository.FindExactMatches(ctx, breachName, fieldHashes)
+}
+
+func TestBreachService_BulkSearchBoundsQueries(t *testing.T) {
+	repo := &concurrencyRecorder{MockBreachRepository: repositories.NewMockBreachRepository()}
+	for i := 0; i < 20; i++ {
+		repo.AddBreach(models.BreachMetadata{ID: uint64(100 + i), Name: fmt.Sprintf("breach_extra_%d", i), Fields: []string{"email"}})
+	}
+	service := NewBreachService(repo, nil, nil, nil)
+
+	items := make([]models.BulkSearchItem, 20)
+	for i := range items {
+		items[i] = models.BulkSearchItem{ID: "x", Fields: map[string]string{"email": "0000"}}
+	}
+
+	// Two bulk searches at once share the same budget
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := service.BulkSearch(context.Background(), models.BreachSearchRequest{Mode: "personal"}, nil, sliceItemReader(items), func(*models.BulkSearchResult) error { return nil })
+			if err != nil {
+				t.Errorf("BulkSearch() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if limit := bulkSearchConcurrency * bulkSearchFanOut; repo.peak > limit {
+		t.Errorf("peak concurrent breach queries = %d, want at most %d", repo.peak, limit)
+	}
+}
+
+func TestBreachService_BulkSearchStrict(t *testing.T) {
+	repo := &failingBreachRepository{
+		MockBreachRepository: repositories.NewMockBreachRepository(),
+		broken:               "breach_facebook_2019",
+	}
+	service := NewBreachService(repo, nil, nil, nil)
+	items := []models.BulkSearchItem{{ID: "a", Fields: map[string]string{"firstName": linkedinFirstNameHash}}}
+
+	search := func(base models.BreachSearchRequest) *models.BulkSearchResult {
+		var got *models.BulkSearchResult
+		err := service.BulkSearch(context.Background(), base, nil, sliceItemReader(items), func(result *models.BulkSearchResult) error {
+			got = result
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("BulkSearch() error = %v", err)
+		}
+		return got
+	}
+
+	lenient := search(models.BreachSearchRequest{Mode: "personal"})
+	if resp, ok := lenient.Result.(*models.PersonalSearchResponse); !ok || !resp.Incomplete {
+		t.Errorf("lenient result = %+v, want an incomplete response", lenient)
+	}
+	// Strict applies to every identity and fails only the ones left unchecked
+	strict := search(models.BreachSearchRequest{Mode: "personal", Strict: true})
+	if strict.Result != nil || strict.Error != "1 sources could not be checked" {
+		t.Errorf("strict result = %+v, want an unchecked sources error", strict)
+	}
+}
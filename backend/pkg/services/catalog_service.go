@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+const (
+	defaultCatalogPageSize = 20
+	maxCatalogPageSize     = 100
+)
+
+// BreachListQuery holds the raw catalog query parameters.
+type BreachListQuery struct {
+	Page      int
+	PageSize  int
+	Industry  string
+	FieldType string
+	From      string // YYYY-MM-DD
+	To        string // YYYY-MM-DD
+	Sort      string // "date" or "size"
+	Order     string // "asc" or "desc"
+}
+
+type CatalogService struct {
+	breachRepo repositories.BreachRepository
+}
+
+func NewCatalogService(breachRepo repositories.BreachRepository) *CatalogService {
+	return &CatalogService{breachRepo: breachRepo}
+}
+
+func (s *CatalogService) ListBreaches(ctx context.Context, q *BreachListQuery) (*models.BreachListResponse, error) {
+	page := q.Page
+	if page == 0 {
+		page = 1
+	}
+	if page < 1 {
+		return nil, utils.NewAppError(http.StatusBadRequest, "page must be at least 1")
+	}
+	pageSize := q.PageSize
+	if pageSize == 0 {
+		pageSize = defaultCatalogPageSize
+	}
+	if pageSize < 1 || pageSize > maxCatalogPageSize {
+		return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("pageSize must be between 1 and %d", maxCatalogPageSize))
+	}
+
+	filter := models.BreachListFilter{
+		Industry:  q.Industry,
+		FieldType: q.FieldType,
+		Limit:     pageSize,
+		Offset:    (page - 1) * pageSize,
+	}
+
+	var err error
+	if filter.From, err = parseCatalogDate("from", q.From); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseCatalogDate("to", q.To); err != nil {
+		return nil, err
+	}
+
+	switch q.Sort {
+	case "", "date":
+		filter.SortBy = "date"
+	case "size":
+		filter.SortBy = "size"
+	default:
+		return nil, utils.NewAppError(http.StatusBadRequest, "sort must be date or size")
+	}
+	switch q.Order {
+	case "", "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		return nil, utils.NewAppError(http.StatusBadRequest, "order must be asc or desc")
+	}
+
+	breaches, total, err := s.breachRepo.ListBreaches(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list breaches: %w", err)
+	}
+
+	return &models.BreachListResponse{
+		Breaches: breaches,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+func (s *CatalogService) GetBreach(ctx context.Context, name string) (*models.BreachMetadata, error) {
+	metadata, err := s.breachRepo.GetBreachMetadata(ctx, name)
+	if errors.Is(err, repositories.ErrBreachNotFound) {
+		return nil, utils.NewAppError(http.StatusNotFound, "Breach not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get breach: %w", err)
+	}
+	return metadata, nil
+}
+
+func (s *CatalogService) ListFields(ctx context.Context) ([]models.FieldCoverage, error) {
+	coverage, err := s.breachRepo.GetFieldCoverage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get field coverage: %w", err)
+	}
+	return coverage, nil
+}
+
+func parseCatalogDate(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("%s must be a date in YYYY-MM-DD format", name))
+	}
+	return &t, nil
+}
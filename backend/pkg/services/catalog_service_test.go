@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+func TestCatalogService_ListBreaches(t *testing.T) {
+	service := NewCatalogService(repositories.NewMockBreachRepository())
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		query     BreachListQuery
+		wantNames []string
+		wantTotal int
+	}{
+		{
+			name:      "default sorts newest first",
+			query:     BreachListQuery{},
+			wantNames: []string{"breach_linkedin_2021", "breach_passwords_2020", "breach_facebook_2019"},
+			wantTotal: 3,
+		},
+		{
+			name:      "sort by size ascending",
+			query:     BreachListQuery{Sort: "size", Order: "asc"},
+			wantNames: []string{"breach_facebook_2019", "breach_passwords_2020", "breach_linkedin_2021"},
+			wantTotal: 3,
+		},
+		{
+			name:      "filter by industry and field",
+			query:     BreachListQuery{Industry: "technology", FieldType: "email"},
+			wantNames: []string{"breach_linkedin_2021"},
+			wantTotal: 1,
+		},
+		{
+			name:      "date range",
+			query:     BreachListQuery{From: "2020-01-01", To: "2020-12-31"},
+			wantNames: []string{"breach_passwords_2020"},
+			wantTotal: 1,
+		},
+		{
+			name:      "second page",
+			query:     BreachListQuery{Page: 2, PageSize: 2},
+			wantNames: []string{"breach_facebook_2019"},
+			wantTotal: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.ListBreaches(ctx, &tt.query)
+			if err != nil {
+				t.Fatalf("ListBreaches() error = %v", err)
+			}
+			if resp.Total != tt.wantTotal {
+				t.Errorf("ListBreaches() total = %d, want %d", resp.Total, tt.wantTotal)
+			}
+			if len(resp.Breaches) != len(tt.wantNames) {
+				t.Fatalf("ListBreaches() got %d breaches, want %d", len(resp.Breaches), len(tt.wantNames))
+			}
+			for i, name := range tt.wantNames {
+				if resp.Breaches[i].Name != name {
+					t.Errorf("ListBreaches()[%d] = %s, want %s", i, resp.Breaches[i].Name, name)
+				}
+			}
+		})
+	}
+}
+
+func TestCatalogService_InvalidQueries(t *testing.T) {
+	service := NewCatalogService(repositories.NewMockBreachRepository())
+
+	for _, q := range []BreachListQuery{
+		{Page: -1},
+		{PageSize: maxCatalogPageSize + 1},
+		{From: "yesterday"},
+		{Sort: "name"},
+		{Order: "sideways"},
+	} {
+		_, err := service.ListBreaches(context.Background(), &q)
+		var appErr *utils.AppError
+		if !errors.As(err, &appErr) || appErr.Code != http.StatusBadRequest {
+			t.Errorf("ListBreaches(%+v) error = %v, want 400", q, err)
+		}
+	}
+}
+
+func TestCatalogService_GetBreachAndFields(t *testing.T) {
+	service := NewCatalogService(repositories.NewMockBreachRepository())
+	ctx := context.Background()
+
+	breach, err := service.GetBreach(ctx, "breach_facebook_2019")
+	if err != nil {
+		t.Fatalf("GetBreach() error = %v", err)
+	}
+	if breach.Industry != "Social Media" || len(breach.Fields) != 3 {
+		t.Errorf("GetBreach() = %+v", breach)
+	}
+
+	_, err = service.GetBreach(ctx, "nope")
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusNotFound {
+		t.Errorf("GetBreach(unknown) error = %v, want 404", err)
+	}
+
+	fields, err := service.ListFields(ctx)
+	if err != nil {
+		t.Fatalf("ListFields() error = %v", err)
+	}
+	coverage := make(map[string]int)
+	for _, f := range fields {
+		coverage[f.Field] = f.Breaches
+	}
+	if coverage["firstName"] != 2 || coverage["password"] != 1 {
+		t.Errorf("ListFields() = %v", coverage)
+	}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+)
+
+type contextKey int
+
+const (
+	userContextKey contextKey = iota
+	apiKeyContextKey
+	membershipContextKey
+	requestIDContextKey
+)
+
+// ContextWithRequestID returns a copy of ctx carrying the ID the request is
+// logged under.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, id)
+}
+
+// RequestIDFromContext returns the request's ID, or "" outside a request.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
+
+// RequestLogger returns the default logger, tagged with the request ID of
+// ctx if it has one, so lines can be matched with the access log.
+func RequestLogger(ctx context.Context) *slog.Logger {
+	if id := RequestIDFromContext(ctx); id != "" {
+		return slog.Default().With("request_id", id)
+	}
+	return slog.Default()
+}
+
+// ContextWithUser returns a copy of ctx carrying the authenticated caller.
+func ContextWithUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// UserFromContext returns the authenticated caller, if the request had one.
+func UserFromContext(ctx context.Context) (*models.User, bool) {
+	user, ok := ctx.Value(userContextKey).(*models.User)
+	return user, ok && user != nil
+}
+
+// ContextWithAPIKey returns a copy of ctx carrying the API key the caller
+// authenticated with.
+func ContextWithAPIKey(ctx context.Context, key *models.APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey, key)
+}
+
+// APIKeyFromContext returns the API key used for the request, if any.
+func APIKeyFromContext(ctx context.Context) (*models.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey).(*models.APIKey)
+	return key, ok && key != nil
+}
+
+// ContextWithMembership returns a copy of ctx carrying the organization
+// membership of the caller; nil records that the caller has none.
+func ContextWithMembership(ctx context.Context, membership *models.Membership) context.Context {
+	return context.WithValue(ctx, membershipContextKey, membership)
+}
+
+// MembershipFromContext returns the caller's organization membership. loaded
+// is false when it was never looked up for this request.
+func MembershipFromContext(ctx context.Context) (membership *models.Membership, loaded bool) {
+	membership, loaded = ctx.Value(membershipContextKey).(*models.Membership)
+	return membership, loaded
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+const (
+	maxDomainClaims        = 20
+	maxDomainLength        = 253
+	domainAccountLimit     = 1000
+	maxDomainEmailHashes   = 10000
+	domainVerifyTimeout    = 10 * time.Second
+	domainVerifyFileLimit  = 4096
+	domainVerificationName = "breach-radar-verification"
+	domainDNSPrefix        = "_breach-radar."
+	domainVerificationPath = "/.well-known/breach-radar-verification.txt"
+)
+
+var (
+	errDomainClaimNotFound = utils.NewAppError(http.StatusNotFound, "Domain claim not found")
+	errDomainPlanRequired  = utils.NewAppError(http.StatusForbidden, "Domain monitoring requires an enterprise organization")
+
+	domainLabelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+)
+
+// TXTResolver looks up DNS TXT records. *net.Resolver implements it.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// DomainService lets enterprise organizations claim email domains and, once
+// they prove ownership, list every breached account of the domain.
+//
+// Ownership is proven by publishing the claim's token either as a DNS TXT
+// record or as a file on the domain's web server. Emails are only stored
+// hashed, so accounts are reported by email hash; the customer can send the
+// v1 hashes of their own directory to have accounts reported by those.
+type DomainService struct {
+	domainRepo repositories.DomainRepository
+	breachRepo repositories.BreachRepository
+	hasher     *hashing.Hasher
+	schemes    *hashing.Schemes
+	resolver   TXTResolver
+	client     *http.Client
+	now        func() time.Time
+
+	// allowAddress reports whether the default client may fetch a
+	// verification file from ip, so a claimed domain pointed at the
+	// server's own network cannot be used to probe it.
+	allowAddress func(ip netip.Addr) bool
+}
+
+// NewDomainService verifies DNS records with resolver and files with client;
+// nil uses net.DefaultResolver and a client with a domainVerifyTimeout
+// timeout that only dials public addresses. Verification files are only
+// fetched over HTTPS and redirects are not followed, so the file has to be
+// served by the domain itself. Domain
+// hashes are searched under every active version of schemes; nil means v1
+// only.
+func NewDomainService(domainRepo repositories.DomainRepository, breachRepo repositories.BreachRepository, hasher *hashing.Hasher, schemes *hashing.Schemes, resolver TXTResolver, client *http.Client) *DomainService {
+	if schemes == nil {
+		schemes = hashing.V1Schemes()
+	}
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	s := &DomainService{
+		domainRepo:   domainRepo,
+		breachRepo:   breachRepo,
+		hasher:       hasher,
+		schemes:      schemes,
+		resolver:     resolver,
+		now:          time.Now,
+		allowAddress: publicAddress,
+	}
+
+	if client == nil {
+		allow := func(ip netip.Addr) bool { return s.allowAddress(ip) }
+		client = &http.Client{Timeout: domainVerifyTimeout, Transport: guardedTransport(domainVerifyTimeout, allow)}
+	}
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	s.client = &noRedirects
+
+	return s
+}
+
+func (s *DomainService) CreateClaim(ctx context.Context, member *models.Membership, req *models.CreateDomainClaimRequest) (*models.DomainClaim, error) {
+	if err := requireEnterprise(member); err != nil {
+		return nil, err
+	}
+
+	domain := hashing.NormalizeDomain(req.Domain)
+	if !validDomain(domain) {
+		return nil, utils.NewAppError(http.StatusBadRequest, "A valid domain name is required")
+	}
+
+	existing, err := s.domainRepo.ListClaims(ctx, member.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domain claims: %w", err)
+	}
+	if len(existing) >= maxDomainClaims {
+		return nil, utils.NewAppError(http.StatusConflict, fmt.Sprintf("At most %d domains can be claimed", maxDomainClaims))
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	claim := &models.DomainClaim{OrgID: member.ID, Domain: domain, Token: token}
+	if err := s.domainRepo.CreateClaim(ctx, claim); err != nil {
+		if errors.Is(err, repositories.ErrDomainClaimed) {
+			return nil, utils.NewAppError(http.StatusConflict, "Domain already claimed")
+		}
+		return nil, fmt.Errorf("failed to create domain claim: %w", err)
+	}
+
+	return withVerification(claim), nil
+}
+
+func (s *DomainService) ListClaims(ctx context.Context, member *models.Membership) ([]models.DomainClaim, error) {
+	if err := requireEnterprise(member); err != nil {
+		return nil, err
+	}
+
+	claims, err := s.domainRepo.ListClaims(ctx, member.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domain claims: %w", err)
+	}
+	for i := range claims {
+		withVerification(&claims[i])
+	}
+	return claims, nil
+}
+
+func (s *DomainService) DeleteClaim(ctx context.Context, member *models.Membership, claimID uint64) error {
+	if err := s.domainRepo.DeleteClaim(ctx, member.ID, claimID); err != nil {
+		if errors.Is(err, repositories.ErrDomainClaimNotFound) {
+			return errDomainClaimNotFound
+		}
+		return fmt.Errorf("failed to delete domain claim: %w", err)
+	}
+	return nil
+}
+
+// VerifyClaim checks that the claim's token is published with the given
+// method. Verifying a claim that is already verified is a no-op.
+func (s *DomainService) VerifyClaim(ctx context.Context, member *models.Membership, claimID uint64, req *models.VerifyDomainRequest) (*models.DomainClaim, error) {
+	if err := requireEnterprise(member); err != nil {
+		return nil, err
+	}
+
+	claim, err := s.getClaim(ctx, member, claimID)
+	if err != nil {
+		return nil, err
+	}
+	if claim.VerifiedAt != nil {
+		return withVerification(claim), nil
+	}
+
+	var found bool
+	switch req.Method {
+	case models.DomainVerificationDNS:
+		found = s.checkDNS(ctx, claim)
+	case models.DomainVerificationFile:
+		found = s.checkFile(ctx, claim)
+	default:
+		return nil, utils.NewAppError(http.StatusBadRequest, "method must be one of dns, file")
+	}
+	if !found {
+		return nil, utils.NewAppError(http.StatusUnprocessableEntity, fmt.Sprintf("Verification token for %s not found", claim.Domain))
+	}
+
+	now := s.now().UTC()
+	if err := s.domainRepo.MarkVerified(ctx, claim.ID, req.Method, now); err != nil {
+		if errors.Is(err, repositories.ErrDomainVerified) {
+			return nil, utils.NewAppError(http.StatusConflict, "Domain is verified by another organization")
+		}
+		return nil, fmt.Errorf("failed to verify domain claim: %w", err)
+	}
+	claim.VerificationMethod = req.Method
+	claim.VerifiedAt = &now
+
+	return withVerification(claim), nil
+}
+
+// checkDNS accepts the record on the _breach-radar subdomain or on the
+// domain itself, for DNS hosts that cannot add underscore names.
+func (s *DomainService) checkDNS(ctx context.Context, claim *models.DomainClaim) bool {
+	want := verificationValue(claim)
+	for _, name := range []string{domainDNSPrefix + claim.Domain, claim.Domain} {
+		records, err := s.resolver.LookupTXT(ctx, name)
+		if err != nil {
+			continue
+		}
+		for _, record := range records {
+			if strings.TrimSpace(record) == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkFile accepts the record value on any line of the verification file.
+func (s *DomainService) checkFile(ctx context.Context, claim *models.DomainClaim) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, verificationFileURL(claim), nil)
+	if err != nil {
+		return false
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	want := verificationValue(claim)
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, domainVerifyFileLimit))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
+
+// Search lists the breached accounts of a verified domain, grouped by breach,
+// with at most domainAccountLimit accounts per breach. A request with email
+// hashes only lists those accounts and reports them by the v1 hash sent; req
+// may be nil.
+func (s *DomainService) Search(ctx context.Context, member *models.Membership, claimID uint64, req *models.DomainSearchRequest) (*models.DomainSearchResponse, error) {
+	if err := requireEnterprise(member); err != nil {
+		return nil, err
+	}
+
+	claim, err := s.getClaim(ctx, member, claimID)
+	if err != nil {
+		return nil, err
+	}
+	if claim.VerifiedAt == nil {
+		return nil, utils.NewAppError(http.StatusForbidden, fmt.Sprintf("Ownership of %s is not verified", claim.Domain))
+	}
+
+	breaches, _, err := s.breachRepo.ListBreaches(ctx, models.BreachListFilter{FieldType: "email"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list breaches: %w", err)
+	}
+	covered, err := s.breachRepo.BreachesWithEmailDomain(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list breaches with email domains: %w", err)
+	}
+
+	domainHashes, err := s.schemes.DeriveActive(s.hasher.DomainHash(claim.Domain), hashing.SchemeV1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash %s: %w", claim.Domain, err)
+	}
+	var emailHashes []string
+	submitted := make(map[string]string) // stored hash -> v1 hash sent
+	if req != nil && req.EmailHashes != nil {
+		if len(req.EmailHashes) > maxDomainEmailHashes {
+			return nil, utils.NewAppError(http.StatusRequestEntityTooLarge, fmt.Sprintf("At most %d email hashes can be searched at once", maxDomainEmailHashes))
+		}
+		emailHashes = []string{}
+		for _, hash := range req.EmailHashes {
+			if !hashing.IsFullHash(hash) {
+				return nil, utils.NewAppError(http.StatusBadRequest, "Email hashes must be v1 full hashes")
+			}
+			derived, err := s.schemes.DeriveActive(hash, hashing.SchemeV1)
+			if err != nil {
+				return nil, fmt.Errorf("failed to derive email hash: %w", err)
+			}
+			for _, stored := range derived {
+				submitted[stored] = hash
+			}
+			emailHashes = append(emailHashes, derived...)
+		}
+	}
+
+	response := &models.DomainSearchResponse{
+		Domain:    claim.Domain,
+		Breaches:  []models.DomainBreach{},
+		Uncovered: []string{},
+	}
+	for _, breach := range breaches {
+		if !containsField(covered, breach.Name) {
+			response.Uncovered = append(response.Uncovered, breach.Name)
+			continue
+		}
+
+		var fields []string
+		for _, fieldType := range breach.Fields {
+			if repositories.PersonalColumn(fieldType) != "" {
+				fields = append(fields, fieldType)
+			}
+		}
+		accounts, total, err := s.breachRepo.FindDomainAccounts(ctx, breach.Name, domainHashes, emailHashes, fields, domainAccountLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %s: %w", breach.Name, err)
+		}
+		for i := range accounts {
+			if hash, ok := submitted[accounts[i].EmailHash]; ok {
+				accounts[i].EmailHash, accounts[i].HashVersion = hash, hashing.SchemeV1
+			}
+		}
+		if total == 0 {
+			continue
+		}
+		response.Breaches = append(response.Breaches, models.DomainBreach{
+			Breach:           breach.Name,
+			DisplayName:      breach.DisplayName,
+			Date:             breach.Date,
+			AffectedAccounts: total,
+			Accounts:         accounts,
+			Truncated:        int64(len(accounts)) < total,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *DomainService) getClaim(ctx context.Context, member *models.Membership, claimID uint64) (*models.DomainClaim, error) {
+	claim, err := s.domainRepo.GetClaim(ctx, member.ID, claimID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrDomainClaimNotFound) {
+			return nil, errDomainClaimNotFound
+		}
+		return nil, fmt.Errorf("failed to get domain claim: %w", err)
+	}
+	return claim, nil
+}
+
+func requireEnterprise(member *models.Membership) error {
+	if member == nil || member.Plan != "enterprise" {
+		return errDomainPlanRequired
+	}
+	return nil
+}
+
+// validDomain accepts host names of at least two labels. IP addresses are
+// rejected since they have no email addresses.
+func validDomain(domain string) bool {
+	if len(domain) > maxDomainLength || net.ParseIP(domain) != nil {
+		return false
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if !domainLabelPattern.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+func verificationValue(claim *models.DomainClaim) string {
+	return domainVerificationName + "=" + claim.Token
+}
+
+func verificationFileURL(claim *models.DomainClaim) string {
+	return "https://" + claim.Domain + domainVerificationPath
+}
+
+// withVerification fills in the instructions for unverified claims.
+func withVerification(claim *models.DomainClaim) *models.DomainClaim {
+	claim.Verification = nil
+	if claim.VerifiedAt == nil {
+		claim.Verification = &models.DomainVerification{
+			DNSRecordName:  domainDNSPrefix + claim.Domain,
+			DNSRecordValue: verificationValue(claim),
+			FileURL:        verificationFileURL(claim),
+			FileContent:    verificationValue(claim),
+		}
+	}
+	return claim
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+)
+
+// fakeResolver answers TXT lookups from a map and fails for other names,
+// like a lookup of a name that does not exist.
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, exists := f[name]
+	if !exists {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+var enterpriseOrg = &models.Membership{Organization: models.Organization{ID: 1, Plan: "enterprise"}, Role: models.RoleOwner}
+
+// testDomainSchemes has a v2 pepper, so domain searches cover rows of both
+// versions.
+var testDomainSchemes, _ = hashing.ParseSchemes("2:00112233445566778899aabbccddeeff", "")
+
+func newTestDomainService(resolver TXTResolver, client *http.Client) (*DomainService, *repositories.MockBreachRepository) {
+	breachRepo := repositories.NewMockBreachRepository()
+	service := NewDomainService(repositories.NewMockDomainRepository(), breachRepo, hashing.NewHasher("test_salt"), testDomainSchemes, resolver, client)
+	service.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	return service, breachRepo
+}
+
+func TestDomainService_CreateClaimValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		member     *models.Membership
+		domain     string
+		wantDomain string
+		wantStatus int
+	}{
+		{name: "normalized", member: enterpriseOrg, domain: " Example.COM. ", wantDomain: "example.com"},
+		{name: "subdomain", member: enterpriseOrg, domain: "mail.example.co.uk", wantDomain: "mail.example.co.uk"},
+		{name: "professional plan", member: &models.Membership{Organization: models.Organization{ID: 2, Plan: "professional"}}, domain: "example.com", wantStatus: http.StatusForbidden},
+		{name: "single label", member: enterpriseOrg, domain: "localhost", wantStatus: http.StatusBadRequest},
+		{name: "ip address", member: enterpriseOrg, domain: "10.0.0.1", wantStatus: http.StatusBadRequest},
+		{name: "email address", member: enterpriseOrg, domain: "it@example.com", wantStatus: http.StatusBadRequest},
+		{name: "url", member: enterpriseOrg, domain: "https://example.com/", wantStatus: http.StatusBadRequest},
+		{name: "leading hyphen", member: enterpriseOrg, domain: "-example.com", wantStatus: http.StatusBadRequest},
+		{name: "long label", member: enterpriseOrg, domain: strings.Repeat("a", 64) + ".com", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestDomainService(fakeResolver{}, nil)
+
+			claim, err := service.CreateClaim(context.Background(), tt.member, &models.CreateDomainClaimRequest{Domain: tt.domain})
+			if tt.wantStatus != 0 {
+				wantAppStatus(t, err, tt.wantStatus)
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateClaim() error = %v", err)
+			}
+			if claim.Domain != tt.wantDomain {
+				t.Errorf("CreateClaim() domain = %q, want %q", claim.Domain, tt.wantDomain)
+			}
+			if claim.Verification == nil || claim.Verification.DNSRecordValue != "breach-radar-verification="+claim.Token {
+				t.Errorf("CreateClaim() verification = %+v", claim.Verification)
+			}
+		})
+	}
+}
+
+func TestDomainService_CreateClaimDuplicate(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestDomainService(fakeResolver{}, nil)
+
+	if _, err := service.CreateClaim(ctx, enterpriseOrg, &models.CreateDomainClaimRequest{Domain: "example.com"}); err != nil {
+		t.Fatalf("CreateClaim() error = %v", err)
+	}
+	_, err := service.CreateClaim(ctx, enterpriseOrg, &models.CreateDomainClaimRequest{Domain: "EXAMPLE.com"})
+	wantAppStatus(t, err, http.StatusConflict)
+}
+
+func TestDomainService_VerifyDNS(t *testing.T) {
+	ctx := context.Background()
+	resolver := fakeResolver{}
+	service, _ := newTestDomainService(resolver, nil)
+
+	claim, err := service.CreateClaim(ctx, enterpriseOrg, &models.CreateDomainClaimRequest{Domain: "example.com"})
+	if err != nil {
+		t.Fatalf("CreateClaim() error = %v", err)
+	}
+	verify := &models.VerifyDomainRequest{Method: models.DomainVerificationDNS}
+
+	_, err = service.VerifyClaim(ctx, enterpriseOrg, claim.ID, verify)
+	wantAppStatus(t, err, http.StatusUnprocessableEntity)
+
+	resolver["_breach-radar.example.com"] = []string{"v=spf1 -all", "breach-radar-verification=someone-elses-token"}
+	_, err = service.VerifyClaim(ctx, enterpriseOrg, claim.ID, verify)
+	wantAppStatus(t, err, http.StatusUnprocessableEntity)
+
+	resolver["example.com"] = []string{claim.Verification.DNSRecordValue}
+	verified, err := service.VerifyClaim(ctx, enterpriseOrg, claim.ID, verify)
+	if err != nil {
+		t.Fatalf("VerifyClaim() error = %v", err)
+	}
+	if verified.VerifiedAt == nil || verified.VerificationMethod != models.DomainVerificationDNS || verified.Verification != nil {
+		t.Errorf("VerifyClaim() = %+v, want verified by dns", verified)
+	}
+
+	// A second organization can claim the domain but never verify it.
+	other := &models.Membership{Organization: models.Organization{ID: 2, Plan: "enterprise"}, Role: models.RoleOwner}
+	otherClaim, err := service.CreateClaim(ctx, other, &models.CreateDomainClaimRequest{Domain: "example.com"})
+	if err != nil {
+		t.Fatalf("CreateClaim() error = %v", err)
+	}
+	resolver["_breach-radar.example.com"] = []string{otherClaim.Verification.DNSRecordValue}
+	_, err = service.VerifyClaim(ctx, other, otherClaim.ID, verify)
+	wantAppStatus(t, err, http.StatusConflict)
+
+	_, err = service.VerifyClaim(ctx, other, claim.ID, verify)
+	wantAppStatus(t, err, http.StatusNotFound)
+}
+
+func TestDomainService_VerifyFile(t *testing.T) {
+	ctx := context.Background()
+	content := ""
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "example.com" || r.URL.Path != "/.well-known/breach-radar-verification.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, content)
+	}))
+	defer server.Close()
+
+	// Send requests for any host to the test server, whose certificate
+	// covers example.com.
+	client := server.Client()
+	transport := client.Transport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	client.Transport = transport
+
+	service, _ := newTestDomainService(fakeResolver{}, client)
+	claim, err := service.CreateClaim(ctx, enterpriseOrg, &models.CreateDomainClaimRequest{Domain: "example.com"})
+	if err != nil {
+		t.Fatalf("CreateClaim() error = %v", err)
+	}
+	if claim.Verification.FileURL != "https://example.com/.well-known/breach-radar-verification.txt" {
+		t.Errorf("CreateClaim() file url = %q", claim.Verification.FileURL)
+	}
+	verify := &models.VerifyDomainRequest{Method: models.DomainVerificationFile}
+
+	content = "breach-radar-verification=wrong\n"
+	_, err = service.VerifyClaim(ctx, enterpriseOrg, claim.ID, verify)
+	wantAppStatus(t, err, http.StatusUnprocessableEntity)
+
+	content = "# breach radar\n" + claim.Verification.FileContent + "\n"
+	verified, err := service.VerifyClaim(ctx, enterpriseOrg, claim.ID, verify)
+	if err != nil {
+		t.Fatalf("VerifyClaim() error = %v", err)
+	}
+	if verified.VerifiedAt == nil || verified.VerificationMethod != models.DomainVerificationFile {
+		t.Errorf("VerifyClaim() = %+v, want verified by file", verified)
+	}
+}
+
+func TestDomainService_VerifyFileRefusesPrivateAddresses(t *testing.T) {
+	ctx := context.Background()
+	var content string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, content)
+	}))
+	defer server.Close()
+
+	// Keep the default guarded dialer, but point every host at the test
+	// server on loopback, like a claimed domain resolving to a private
+	// address.
+	service, _ := newTestDomainService(fakeResolver{}, nil)
+	transport := service.client.Transport.(*http.Transport)
+	dial := transport.DialContext
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dial(ctx, network, server.Listener.Addr().String())
+	}
+	transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	claim, err := service.CreateClaim(ctx, enterpriseOrg, &models.CreateDomainClaimRequest{Domain: "example.com"})
+	if err != nil {
+		t.Fatalf("CreateClaim() error = %v", err)
+	}
+	content = claim.Verification.FileContent
+	verify := &models.VerifyDomainRequest{Method: models.DomainVerificationFile}
+
+	_, err = service.VerifyClaim(ctx, enterpriseOrg, claim.ID, verify)
+	wantAppStatus(t, err, http.StatusUnprocessableEntity)
+
+	service.allowAddress = func(netip.Addr) bool { return true }
+	if _, err := service.VerifyClaim(ctx, enterpriseOrg, claim.ID, verify); err != nil {
+		t.Fatalf("VerifyClaim() with loopback allowed error = %v", err)
+	}
+}
+
+func TestDomainService_Search(t *testing.T) {
+	ctx := context.Background()
+	resolver := fakeResolver{}
+	service, breachRepo := newTestDomainService(resolver, nil)
+	hasher := hashing.NewHasher("test_salt")
+
+	domainHash := hasher.DomainHash("example.com")
+	alice := hasher.FullHash("alice@example.com", "email")
+	bob := hasher.FullHash("bob@example.com", "email")
+	// Alice's row has been rehashed to v2, Bob's is still v1
+	v2 := func(hash string) string {
+		derived, err := testDomainSchemes.Derive(hash, hashing.SchemeV1, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return derived
+	}
+	breachRepo.AddPersonalRecord("breach_linkedin_2021", map[string]string{
+		"email": v2(alice), "firstName": v2(hasher.FullHash("Alice", "firstName")), repositories.EmailDomainField: v2(domainHash),
+	})
+	breachRepo.AddPersonalRecord("breach_linkedin_2021", map[string]string{
+		"email": bob, "username": hasher.FullHash("bob", "username"), repositories.EmailDomainField: domainHash,
+	})
+	breachRepo.AddPersonalRecord("breach_linkedin_2021", map[string]string{
+		"email": hasher.FullHash("carol@other.com", "email"), repositories.EmailDomainField: hasher.DomainHash("other.com"),
+	})
+	// Loaded before email domains were stored
+	breachRepo.AddBreach(models.BreachMetadata{ID: 4, Name: "breach_forum_2015", Fields: []string{"email", "password"}})
+	breachRepo.AddPersonalRecord("breach_forum_2015", map[string]string{"email": alice})
+
+	claim, err := service.CreateClaim(ctx, enterpriseOrg, &models.CreateDomainClaimRequest{Domain: "example.com"})
+	if err != nil {
+		t.Fatalf("CreateClaim() error = %v", err)
+	}
+
+	_, err = service.Search(ctx, enterpriseOrg, claim.ID, nil)
+	wantAppStatus(t, err, http.StatusForbidden)
+
+	resolver["_breach-radar.example.com"] = []string{claim.Verification.DNSRecordValue}
+	if _, err := service.VerifyClaim(ctx, enterpriseOrg, claim.ID, &models.VerifyDomainRequest{Method: models.DomainVerificationDNS}); err != nil {
+		t.Fatalf("VerifyClaim() error = %v", err)
+	}
+
+	results, err := service.Search(ctx, enterpriseOrg, claim.ID, nil)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results.Breaches) != 1 || results.Breaches[0].Breach != "breach_linkedin_2021" {
+		t.Fatalf("Search() breaches = %+v, want breach_linkedin_2021 only", results.Breaches)
+	}
+	breach := results.Breaches[0]
+	if breach.AffectedAccounts != 2 || len(breach.Accounts) != 2 || breach.Truncated {
+		t.Fatalf("Search() breach = %+v, want 2 accounts", breach)
+	}
+	if got := breach.Accounts[0]; got.EmailHash != v2(alice) || strings.Join(got.ExposedFields, ",") != "email,firstName" {
+		t.Errorf("Search() first account = %+v", got)
+	}
+	if got := breach.Accounts[1]; got.EmailHash != bob || strings.Join(got.ExposedFields, ",") != "email,username" {
+		t.Errorf("Search() second account = %+v", got)
+	}
+	if strings.Join(results.Uncovered, ",") != "breach_forum_2015" {
+		t.Errorf("Search() uncovered = %v, want [breach_forum_2015]", results.Uncovered)
+	}
+
+	// Accounts are reported by the v1 hashes the customer sent, whatever
+	// version they are stored under
+	dave := hasher.FullHash("dave@example.com", "email")
+	results, err = service.Search(ctx, enterpriseOrg, claim.ID, &models.DomainSearchRequest{EmailHashes: []string{alice, dave}})
+	if err != nil {
+		t.Fatalf("Search() with email hashes error = %v", err)
+	}
+	if len(results.Breaches) != 1 || len(results.Breaches[0].Accounts) != 1 {
+		t.Fatalf("Search() with email hashes = %+v, want Alice only", results.Breaches)
+	}
+	if got := results.Breaches[0].Accounts[0]; got.EmailHash != alice || got.HashVersion != hashing.SchemeV1 {
+		t.Errorf("Search() with email hashes account = %+v, want Alice's v1 hash", got)
+	}
+
+	_, err = service.Search(ctx, enterpriseOrg, claim.ID, &models.DomainSearchRequest{EmailHashes: []string{"alice@example.com"}})
+	wantAppStatus(t, err, http.StatusBadRequest)
+
+	_, err = service.Search(ctx, &models.Membership{Organization: models.Organization{ID: 1, Plan: "free"}}, claim.ID, nil)
+	wantAppStatus(t, err, http.StatusForbidden)
+}
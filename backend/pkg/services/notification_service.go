@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/notify"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+const digestInterval = 24 * time.Hour
+
+// NotificationService emails watchlist alerts according to each user's
+// preference: one email per scan, a daily digest, or nothing.
+//
+// Digests need no schedule of their own. A user's pending alerts are sent
+// once the oldest of them is a day old, so nobody gets more than one digest
+// a day and no alert waits longer than that.
+type NotificationService struct {
+	userRepo  repositories.UserRepository
+	watchRepo repositories.WatchlistRepository
+	notifier  notify.Notifier
+	templates *notify.Templates
+	appURL    string
+	interval  time.Duration
+	now       func() time.Time
+}
+
+// NewNotificationService sends with notifier; nil logs emails instead.
+// appURL is the frontend address links in emails point to.
+func NewNotificationService(userRepo repositories.UserRepository, watchRepo repositories.WatchlistRepository, notifier notify.Notifier, appURL string, interval time.Duration) *NotificationService {
+	if notifier == nil {
+		notifier = notify.NewLogNotifier(nil)
+	}
+	return &NotificationService{
+		userRepo:  userRepo,
+		watchRepo: watchRepo,
+		notifier:  notifier,
+		templates: notify.DefaultTemplates(),
+		appURL:    strings.TrimRight(appURL, "/"),
+		interval:  interval,
+		now:       time.Now,
+	}
+}
+
+func (s *NotificationService) GetPreferences(ctx context.Context, user *models.User) *models.NotificationPreferences {
+	return &models.NotificationPreferences{AlertEmails: alertEmailPreference(user)}
+}
+
+func (s *NotificationService) UpdatePreferences(ctx context.Context, user *models.User, req *models.NotificationPreferences) (*models.NotificationPreferences, error) {
+	if !containsField(models.AlertEmailOptions, req.AlertEmails) {
+		return nil, utils.NewAppError(http.StatusBadRequest, "alertEmails must be one of "+strings.Join(models.AlertEmailOptions, ", "))
+	}
+
+	updated := *user
+	updated.AlertEmails = req.AlertEmails
+	if err := s.userRepo.UpdateUser(ctx, &updated); err != nil {
+		return nil, fmt.Errorf("failed to update notification preferences: %w", err)
+	}
+	return s.GetPreferences(ctx, &updated), nil
+}
+
+// SendAlertEmails emails every user with alerts that are due and returns
+// how many emails were sent. A user whose email fails is retried on the
+// next run; the others are not held up.
+func (s *NotificationService) SendAlertEmails(ctx context.Context) (int, error) {
+	alerts, err := s.watchRepo.ListUnemailedAlerts(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list unemailed alerts: %w", err)
+	}
+
+	var userIDs []uint64
+	byUser := make(map[uint64][]models.WatchlistAlert)
+	for _, alert := range alerts {
+		if _, seen := byUser[alert.UserID]; !seen {
+			userIDs = append(userIDs, alert.UserID)
+		}
+		byUser[alert.UserID] = append(byUser[alert.UserID], alert)
+	}
+
+	sent := 0
+	now := s.now().UTC()
+	for _, userID := range userIDs {
+		emailed, err := s.emailUser(ctx, userID, byUser[userID], now)
+		if err != nil {
+			log.Printf("Failed to email alerts to user %d -> %v", userID, err)
+			continue
+		}
+		if emailed {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// emailUser expects alerts oldest first.
+func (s *NotificationService) emailUser(ctx context.Context, userID uint64, alerts []models.WatchlistAlert, now time.Time) (bool, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil && !errors.Is(err, repositories.ErrUserNotFound) {
+		return false, err
+	}
+
+	event := ""
+	if user != nil {
+		switch alertEmailPreference(user) {
+		case models.AlertEmailsImmediate:
+			event = notify.EventAlert
+		case models.AlertEmailsDaily:
+			if now.Sub(alerts[0].CreatedAt) < digestInterval {
+				return false, nil
+			}
+			event = notify.EventDigest
+		}
+	}
+
+	if event != "" {
+		msg, err := s.templates.Render(event, user.Email, notify.AlertData{
+			Name:      displayName(user),
+			Alerts:    alerts,
+			AlertsURL: s.appURL + "/alerts",
+		})
+		if err != nil {
+			return false, err
+		}
+		if err := s.notifier.Send(ctx, msg); err != nil {
+			return false, err
+		}
+	}
+
+	ids := make([]uint64, len(alerts))
+	for i, alert := range alerts {
+		ids[i] = alert.ID
+	}
+	if err := s.watchRepo.MarkAlertsEmailed(ctx, ids, now); err != nil {
+		return false, err
+	}
+	return event != "", nil
+}
+
+// Run sends due alert emails every interval until ctx is cancelled.
+func (s *NotificationService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SendAlertEmails(ctx); err != nil {
+			log.Printf("Failed to send alert emails -> %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// alertEmailPreference treats accounts without a preference as daily, the
+// default for new accounts.
+func alertEmailPreference(user *models.User) string {
+	if user.AlertEmails == "" {
+		return models.AlertEmailsDaily
+	}
+	return user.AlertEmails
+}
+
+func displayName(user *models.User) string {
+	if user.FirstName != "" {
+		return user.FirstName
+	}
+	return "there"
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/notify"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+type recordingNotifier struct {
+	mu       sync.Mutex
+	messages []notify.Message
+	err      error
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, msg notify.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+func TestNotificationService_SendAlertEmails(t *testing.T) {
+	ctx := context.Background()
+	emailHash := strings.Repeat("a1", 64)
+
+	breachRepo := repositories.NewMockBreachRepository()
+	watchRepo := repositories.NewMockWatchlistRepository(breachRepo)
+	userRepo := repositories.NewMockUserRepository()
+	watchlist := NewWatchlistService(watchRepo, breachRepo, nil, nil, time.Minute)
+	notifier := &recordingNotifier{}
+	service := NewNotificationService(userRepo, watchRepo, notifier, "https://app.example.com/", time.Minute)
+
+	users := map[string]*models.User{}
+	for _, preference := range models.AlertEmailOptions {
+		user := &models.User{Email: preference + "@example.com", FirstName: "Sam", AlertEmails: preference}
+		if err := userRepo.CreateUser(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := watchlist.CreateEntry(ctx, user, &models.CreateWatchlistEntryRequest{Label: "Email", Fields: map[string]string{"email": emailHash}}); err != nil {
+			t.Fatal(err)
+		}
+		users[preference] = user
+	}
+
+	breachRepo.AddBreach(models.BreachMetadata{ID: 10, Name: "breach_new_2024", Fields: []string{"email"}})
+	breachRepo.AddPersonalRecord("breach_new_2024", map[string]string{"email": emailHash})
+	if created, err := watchlist.ScanNewBreaches(ctx); err != nil || created != 3 {
+		t.Fatalf("ScanNewBreaches() = %d, %v, want 3 alerts", created, err)
+	}
+
+	// Only the immediate user hears about it right away
+	if sent, err := service.SendAlertEmails(ctx); err != nil || sent != 1 {
+		t.Fatalf("SendAlertEmails() = %d, %v, want 1", sent, err)
+	}
+	if len(notifier.messages) != 1 || notifier.messages[0].To != users[models.AlertEmailsImmediate].Email {
+		t.Fatalf("messages = %+v, want one to the immediate user", notifier.messages)
+	}
+	msg := notifier.messages[0]
+	if !strings.Contains(msg.Text, "breach_new_2024") || !strings.Contains(msg.Text, "https://app.example.com/alerts") {
+		t.Errorf("alert email text = %q", msg.Text)
+	}
+
+	// Nothing is sent twice
+	if sent, _ := service.SendAlertEmails(ctx); sent != 0 {
+		t.Errorf("second SendAlertEmails() = %d, want 0", sent)
+	}
+
+	// The daily digest goes out once the alert is a day old
+	service.now = func() time.Time { return time.Now().Add(digestInterval) }
+	if sent, err := service.SendAlertEmails(ctx); err != nil || sent != 1 {
+		t.Fatalf("SendAlertEmails() a day later = %d, %v, want 1", sent, err)
+	}
+	digest := notifier.messages[1]
+	if digest.To != users[models.AlertEmailsDaily].Email || !strings.HasPrefix(digest.Subject, "Your Breach Radar digest") {
+		t.Errorf("digest = %q to %s", digest.Subject, digest.To)
+	}
+	if len(notifier.messages) != 2 {
+		t.Errorf("sent %d emails in total, want 2 (the off user gets none)", len(notifier.messages))
+	}
+}
+
+func TestNotificationService_FailedSendIsRetried(t *testing.T) {
+	ctx := context.Background()
+	emailHash := strings.Repeat("a1", 64)
+
+	breachRepo := repositories.NewMockBreachRepository()
+	watchRepo := repositories.NewMockWatchlistRepository(breachRepo)
+	userRepo := repositories.NewMockUserRepository()
+	watchlist := NewWatchlistService(watchRepo, breachRepo, nil, nil, time.Minute)
+	notifier := &recordingNotifier{err: errors.New("smtp down")}
+	service := NewNotificationService(userRepo, watchRepo, notifier, "", time.Minute)
+
+	user := &models.User{Email: "sam@example.com", AlertEmails: models.AlertEmailsImmediate}
+	userRepo.CreateUser(ctx, user)
+	watchlist.CreateEntry(ctx, user, &models.CreateWatchlistEntryRequest{Label: "Email", Fields: map[string]string{"email": emailHash}})
+	breachRepo.AddBreach(models.BreachMetadata{ID: 10, Name: "breach_new_2024", Fields: []string{"email"}})
+	breachRepo.AddPersonalRecord("breach_new_2024", map[string]string{"email": emailHash})
+	watchlist.ScanNewBreaches(ctx)
+
+	if sent, _ := service.SendAlertEmails(ctx); sent != 0 {
+		t.Fatalf("SendAlertEmails() with failing notifier = %d, want 0", sent)
+	}
+	notifier.err = nil
+	if sent, _ := service.SendAlertEmails(ctx); sent != 1 {
+		t.Errorf("SendAlertEmails() after recovery = %d, want 1", sent)
+	}
+}
+
+func TestNotificationService_UpdatePreferences(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repositories.NewMockUserRepository()
+	breachRepo := repositories.NewMockBreachRepository()
+	service := NewNotificationService(userRepo, repositories.NewMockWatchlistRepository(breachRepo), &recordingNotifier{}, "", time.Minute)
+
+	user := &models.User{Email: "sam@example.com"}
+	userRepo.CreateUser(ctx, user)
+
+	if got := service.GetPreferences(ctx, user).AlertEmails; got != models.AlertEmailsDaily {
+		t.Errorf("GetPreferences() = %q, want daily by default", got)
+	}
+
+	var appErr *utils.AppError
+	if _, err := service.UpdatePreferences(ctx, user, &models.NotificationPreferences{AlertEmails: "hourly"}); !errors.As(err, &appErr) || appErr.Code != http.StatusBadRequest {
+		t.Errorf("UpdatePreferences(hourly) error = %v, want 400", err)
+	}
+
+	if _, err := service.UpdatePreferences(ctx, user, &models.NotificationPreferences{AlertEmails: models.AlertEmailsOff}); err != nil {
+		t.Fatalf("UpdatePreferences() error = %v", err)
+	}
+	stored, _ := userRepo.GetUserByID(ctx, user.ID)
+	if stored.AlertEmails != models.AlertEmailsOff {
+		t.Errorf("stored preference = %q, want off", stored.AlertEmails)
+	}
+}
@@ -0,0 +1,355 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/notify"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+const (
+	maxOrgNameLength = 100
+	// maxOrgMembers counts pending invitations too.
+	maxOrgMembers = 100
+	invitationTTL = 7 * 24 * time.Hour
+	orgPlan       = "enterprise"
+)
+
+var (
+	errNotOrgMember       = utils.NewAppError(http.StatusNotFound, "You do not belong to an organization")
+	errMemberNotFound     = utils.NewAppError(http.StatusNotFound, "Member not found")
+	errInvitationNotFound = utils.NewAppError(http.StatusNotFound, "Invitation not found")
+	errAlreadyMember      = utils.NewAppError(http.StatusConflict, "Account already belongs to an organization")
+	errLastOwner          = utils.NewAppError(http.StatusConflict, "An organization needs at least one owner")
+	errOwnerRequired      = utils.NewAppError(http.StatusForbidden, "Only owners can grant or change the owner role")
+)
+
+// OrganizationService manages organizations, their members and invitations.
+// Callers are expected to have been authorized for the matching permission;
+// the service only enforces the rules about owners that depend on who is
+// acting on whom.
+type OrganizationService struct {
+	orgRepo   repositories.OrganizationRepository
+	notifier  notify.Notifier
+	templates *notify.Templates
+	appURL    string
+	now       func() time.Time
+}
+
+// NewOrganizationService emails invitations with notifier; nil logs them
+// instead. appURL is the frontend address invitation links point to.
+func NewOrganizationService(orgRepo repositories.OrganizationRepository, notifier notify.Notifier, appURL string) *OrganizationService {
+	if notifier == nil {
+		notifier = notify.NewLogNotifier(nil)
+	}
+	return &OrganizationService{
+		orgRepo:   orgRepo,
+		notifier:  notifier,
+		templates: notify.DefaultTemplates(),
+		appURL:    strings.TrimRight(appURL, "/"),
+		now:       time.Now,
+	}
+}
+
+// CreateOrganization makes user the owner of a new organization. Team
+// management is part of the enterprise plan.
+func (s *OrganizationService) CreateOrganization(ctx context.Context, user *models.User, req *models.CreateOrganizationRequest) (*models.Membership, error) {
+	if user.Plan != orgPlan {
+		return nil, utils.NewAppError(http.StatusForbidden, "Organizations require the enterprise plan")
+	}
+	name, err := validateOrgName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	org := &models.Organization{Name: name, Plan: orgPlan}
+	if err := s.orgRepo.CreateOrganization(ctx, org, user.ID); err != nil {
+		if errors.Is(err, repositories.ErrAlreadyMember) {
+			return nil, errAlreadyMember
+		}
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	return &models.Membership{Organization: *org, Role: models.RoleOwner}, nil
+}
+
+func (s *OrganizationService) GetOrganization(ctx context.Context, user *models.User) (*models.Membership, error) {
+	membership, err := s.orgRepo.GetMembership(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrMembershipNotFound) {
+			return nil, errNotOrgMember
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	return membership, nil
+}
+
+func (s *OrganizationService) UpdateOrganization(ctx context.Context, member *models.Membership, req *models.UpdateOrganizationRequest) (*models.Membership, error) {
+	name, err := validateOrgName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *member
+	updated.Name = name
+	if err := s.orgRepo.UpdateOrganization(ctx, &updated.Organization); err != nil {
+		return nil, fmt.Errorf("failed to update organization: %w", err)
+	}
+	return &updated, nil
+}
+
+// DeleteOrganization removes the organization with its memberships,
+// invitations and domain claims. Member accounts and their own resources
+// are kept.
+func (s *OrganizationService) DeleteOrganization(ctx context.Context, member *models.Membership) error {
+	if err := s.orgRepo.DeleteOrganization(ctx, member.ID); err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	return nil
+}
+
+func (s *OrganizationService) ListMembers(ctx context.Context, member *models.Membership) ([]models.Member, error) {
+	members, err := s.orgRepo.ListMembers(ctx, member.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	return members, nil
+}
+
+func (s *OrganizationService) UpdateMemberRole(ctx context.Context, actor *models.Membership, userID uint64, req *models.UpdateMemberRequest) (*models.Member, error) {
+	if !containsField(models.OrgRoles, req.Role) {
+		return nil, utils.NewAppError(http.StatusBadRequest, "role must be one of "+strings.Join(models.OrgRoles, ", "))
+	}
+
+	members, err := s.orgRepo.ListMembers(ctx, actor.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	target := findMember(members, userID)
+	if target == nil {
+		return nil, errMemberNotFound
+	}
+	if (target.Role == models.RoleOwner || req.Role == models.RoleOwner) && actor.Role != models.RoleOwner {
+		return nil, errOwnerRequired
+	}
+	if target.Role == models.RoleOwner && req.Role != models.RoleOwner && countOwners(members) == 1 {
+		return nil, errLastOwner
+	}
+
+	if err := s.orgRepo.UpdateMemberRole(ctx, actor.ID, userID, req.Role); err != nil {
+		if errors.Is(err, repositories.ErrMembershipNotFound) {
+			return nil, errMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to update member: %w", err)
+	}
+	target.Role = req.Role
+	return target, nil
+}
+
+func (s *OrganizationService) RemoveMember(ctx context.Context, actor *models.Membership, userID uint64) error {
+	members, err := s.orgRepo.ListMembers(ctx, actor.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list members: %w", err)
+	}
+	target := findMember(members, userID)
+	if target == nil {
+		return errMemberNotFound
+	}
+	if target.Role == models.RoleOwner && actor.Role != models.RoleOwner {
+		return errOwnerRequired
+	}
+	return s.removeMember(ctx, actor.ID, target, members)
+}
+
+// LeaveOrganization removes user from their organization. The last owner
+// has to hand over ownership or delete the organization instead.
+func (s *OrganizationService) LeaveOrganization(ctx context.Context, user *models.User, member *models.Membership) error {
+	members, err := s.orgRepo.ListMembers(ctx, member.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list members: %w", err)
+	}
+	target := findMember(members, user.ID)
+	if target == nil {
+		return errNotOrgMember
+	}
+	return s.removeMember(ctx, member.ID, target, members)
+}
+
+func (s *OrganizationService) removeMember(ctx context.Context, orgID uint64, target *models.Member, members []models.Member) error {
+	if target.Role == models.RoleOwner && countOwners(members) == 1 {
+		return errLastOwner
+	}
+	if err := s.orgRepo.RemoveMember(ctx, orgID, target.UserID); err != nil {
+		if errors.Is(err, repositories.ErrMembershipNotFound) {
+			return errMemberNotFound
+		}
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	return nil
+}
+
+// CreateInvitation emails an invitation link to req.Email. Only the link
+// carries the token, so a lost email means revoking and inviting again.
+func (s *OrganizationService) CreateInvitation(ctx context.Context, user *models.User, actor *models.Membership, req *models.CreateInvitationRequest) (*models.Invitation, error) {
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if !containsField(models.OrgRoles, req.Role) {
+		return nil, utils.NewAppError(http.StatusBadRequest, "role must be one of "+strings.Join(models.OrgRoles, ", "))
+	}
+	if req.Role == models.RoleOwner && actor.Role != models.RoleOwner {
+		return nil, errOwnerRequired
+	}
+
+	members, err := s.orgRepo.ListMembers(ctx, actor.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	for _, member := range members {
+		if member.Email == email {
+			return nil, utils.NewAppError(http.StatusConflict, "Already a member")
+		}
+	}
+	pending, err := s.orgRepo.ListInvitations(ctx, actor.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	if len(members)+len(pending) >= maxOrgMembers {
+		return nil, utils.NewAppError(http.StatusConflict, fmt.Sprintf("At most %d members and pending invitations are allowed", maxOrgMembers))
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	invitation := &models.Invitation{
+		OrgID:     actor.ID,
+		Email:     email,
+		Role:      req.Role,
+		TokenHash: hashToken(token),
+		InvitedBy: user.ID,
+		ExpiresAt: s.now().UTC().Add(invitationTTL),
+	}
+	if err := s.orgRepo.CreateInvitation(ctx, invitation); err != nil {
+		if errors.Is(err, repositories.ErrInvitationExists) {
+			return nil, utils.NewAppError(http.StatusConflict, "An invitation for this email is already pending")
+		}
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	inviter := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if inviter == "" {
+		inviter = user.Email
+	}
+	msg, err := s.templates.Render(notify.EventInvitation, email, notify.InvitationData{
+		Organization: actor.Name,
+		InvitedBy:    inviter,
+		Role:         req.Role,
+		URL:          s.appURL + "/invitations/accept?token=" + url.QueryEscape(token),
+		ExpiresIn:    "7 days",
+	})
+	if err == nil {
+		err = s.notifier.Send(ctx, msg)
+	}
+	if err != nil {
+		// Without the email nobody can accept it, so do not leave it pending
+		if delErr := s.orgRepo.DeleteInvitation(ctx, actor.ID, invitation.ID); delErr != nil {
+			err = errors.Join(err, delErr)
+		}
+		return nil, fmt.Errorf("failed to send invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+func (s *OrganizationService) ListInvitations(ctx context.Context, member *models.Membership) ([]models.Invitation, error) {
+	invitations, err := s.orgRepo.ListInvitations(ctx, member.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+func (s *OrganizationService) DeleteInvitation(ctx context.Context, member *models.Membership, invitationID uint64) error {
+	if err := s.orgRepo.DeleteInvitation(ctx, member.ID, invitationID); err != nil {
+		if errors.Is(err, repositories.ErrInvitationNotFound) {
+			return errInvitationNotFound
+		}
+		return fmt.Errorf("failed to delete invitation: %w", err)
+	}
+	return nil
+}
+
+// AcceptInvitation adds user to the organization that invited them. The
+// invitation only works for the account with the address it was sent to.
+func (s *OrganizationService) AcceptInvitation(ctx context.Context, user *models.User, req *models.AcceptInvitationRequest) (*models.Membership, error) {
+	if req.Token == "" {
+		return nil, utils.NewAppError(http.StatusBadRequest, "Token is required")
+	}
+
+	invitation, err := s.orgRepo.GetInvitationByTokenHash(ctx, hashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, repositories.ErrInvitationNotFound) {
+			return nil, errInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	now := s.now().UTC()
+	if now.After(invitation.ExpiresAt) {
+		return nil, utils.NewAppError(http.StatusGone, "Invitation has expired")
+	}
+	if !strings.EqualFold(invitation.Email, user.Email) {
+		return nil, utils.NewAppError(http.StatusForbidden, "Invitation was sent to another email address")
+	}
+
+	if err := s.orgRepo.AcceptInvitation(ctx, invitation, user.ID, now); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrAlreadyMember):
+			return nil, errAlreadyMember
+		case errors.Is(err, repositories.ErrInvitationNotFound):
+			return nil, errInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	return s.GetOrganization(ctx, user)
+}
+
+func validateOrgName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", utils.NewAppError(http.StatusBadRequest, "Name is required")
+	}
+	if len(name) > maxOrgNameLength {
+		return "", utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Name must be at most %d characters", maxOrgNameLength))
+	}
+	return name, nil
+}
+
+func findMember(members []models.Member, userID uint64) *models.Member {
+	for i := range members {
+		if members[i].UserID == userID {
+			return &members[i]
+		}
+	}
+	return nil
+}
+
+func countOwners(members []models.Member) int {
+	owners := 0
+	for _, member := range members {
+		if member.Role == models.RoleOwner {
+			owners++
+		}
+	}
+	return owners
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+var invitationLinkPattern = regexp.MustCompile(`https://app\.example\.com/invitations/accept\?token=(\S+)`)
+
+type orgTestEnv struct {
+	service  *OrganizationService
+	userRepo *repositories.MockUserRepository
+	notifier *recordingNotifier
+	clock    *time.Time
+}
+
+func newOrgTestEnv(t *testing.T) *orgTestEnv {
+	clock := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	userRepo := repositories.NewMockUserRepository()
+	notifier := &recordingNotifier{}
+	service := NewOrganizationService(repositories.NewMockOrganizationRepository(userRepo), notifier, "https://app.example.com/")
+	service.now = func() time.Time { return clock }
+	return &orgTestEnv{service: service, userRepo: userRepo, notifier: notifier, clock: &clock}
+}
+
+func (env *orgTestEnv) createUser(t *testing.T, email, plan string) *models.User {
+	t.Helper()
+	user := &models.User{Email: email, FirstName: "Test", Plan: plan}
+	if err := env.userRepo.CreateUser(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// invite invites email and returns the token from the emailed link.
+func (env *orgTestEnv) invite(t *testing.T, inviter *models.User, actor *models.Membership, email, role string) string {
+	t.Helper()
+	if _, err := env.service.CreateInvitation(context.Background(), inviter, actor, &models.CreateInvitationRequest{Email: email, Role: role}); err != nil {
+		t.Fatalf("CreateInvitation() error = %v", err)
+	}
+	msg := env.notifier.messages[len(env.notifier.messages)-1]
+	if msg.To != strings.ToLower(email) {
+		t.Fatalf("invitation sent to %q, want %q", msg.To, email)
+	}
+	match := invitationLinkPattern.FindStringSubmatch(msg.Text)
+	if match == nil {
+		t.Fatalf("invitation email has no link:\n%s", msg.Text)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// wantAppStatus fails the test unless err is an AppError with the given status.
+func wantAppStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.Code != status {
+		t.Fatalf("error = %v, want status %d", err, status)
+	}
+}
+
+func TestOrganizationService_CreateOrganization(t *testing.T) {
+	ctx := context.Background()
+	env := newOrgTestEnv(t)
+
+	_, err := env.service.CreateOrganization(ctx, env.createUser(t, "pro@example.com", "professional"), &models.CreateOrganizationRequest{Name: "Acme"})
+	wantAppStatus(t, err, http.StatusForbidden)
+
+	owner := env.createUser(t, "owner@example.com", "enterprise")
+	_, err = env.service.CreateOrganization(ctx, owner, &models.CreateOrganizationRequest{Name: "  "})
+	wantAppStatus(t, err, http.StatusBadRequest)
+
+	membership, err := env.service.CreateOrganization(ctx, owner, &models.CreateOrganizationRequest{Name: " Acme "})
+	if err != nil {
+		t.Fatalf("CreateOrganization() error = %v", err)
+	}
+	if membership.Name != "Acme" || membership.Role != models.RoleOwner || membership.Plan != "enterprise" {
+		t.Errorf("CreateOrganization() = %+v", membership)
+	}
+
+	_, err = env.service.CreateOrganization(ctx, owner, &models.CreateOrganizationRequest{Name: "Second"})
+	wantAppStatus(t, err, http.StatusConflict)
+}
+
+func TestOrganizationService_Invitations(t *testing.T) {
+	ctx := context.Background()
+	env := newOrgTestEnv(t)
+
+	owner := env.createUser(t, "owner@example.com", "enterprise")
+	org, err := env.service.CreateOrganization(ctx, owner, &models.CreateOrganizationRequest{Name: "Acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyst := env.createUser(t, "analyst@example.com", "free")
+	stranger := env.createUser(t, "stranger@example.com", "free")
+
+	token := env.invite(t, owner, org, "Analyst@Example.com", models.RoleAnalyst)
+
+	_, err = env.service.CreateInvitation(ctx, owner, org, &models.CreateInvitationRequest{Email: "analyst@example.com", Role: models.RoleViewer})
+	wantAppStatus(t, err, http.StatusConflict)
+	_, err = env.service.CreateInvitation(ctx, owner, org, &models.CreateInvitationRequest{Email: "owner@example.com", Role: models.RoleViewer})
+	wantAppStatus(t, err, http.StatusConflict)
+	_, err = env.service.CreateInvitation(ctx, owner, org, &models.CreateInvitationRequest{Email: "x@example.com", Role: "superuser"})
+	wantAppStatus(t, err, http.StatusBadRequest)
+
+	_, err = env.service.AcceptInvitation(ctx, stranger, &models.AcceptInvitationRequest{Token: token})
+	wantAppStatus(t, err, http.StatusForbidden)
+	_, err = env.service.AcceptInvitation(ctx, analyst, &models.AcceptInvitationRequest{Token: "not-a-token"})
+	wantAppStatus(t, err, http.StatusNotFound)
+
+	membership, err := env.service.AcceptInvitation(ctx, analyst, &models.AcceptInvitationRequest{Token: token})
+	if err != nil {
+		t.Fatalf("AcceptInvitation() error = %v", err)
+	}
+	if membership.ID != org.ID || membership.Role != models.RoleAnalyst {
+		t.Errorf("AcceptInvitation() = %+v, want analyst of %d", membership, org.ID)
+	}
+	_, err = env.service.AcceptInvitation(ctx, analyst, &models.AcceptInvitationRequest{Token: token})
+	wantAppStatus(t, err, http.StatusNotFound)
+
+	// Invitations expire
+	token = env.invite(t, owner, org, "stranger@example.com", models.RoleViewer)
+	*env.clock = env.clock.Add(invitationTTL + time.Minute)
+	_, err = env.service.AcceptInvitation(ctx, stranger, &models.AcceptInvitationRequest{Token: token})
+	wantAppStatus(t, err, http.StatusGone)
+
+	// Nothing stays pending when the email cannot be sent
+	env.notifier.err = errors.New("smtp down")
+	if _, err := env.service.CreateInvitation(ctx, owner, org, &models.CreateInvitationRequest{Email: "late@example.com", Role: models.RoleViewer}); err == nil {
+		t.Fatal("CreateInvitation() succeeded without sending the email")
+	}
+	pending, err := env.service.ListInvitations(ctx, org)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Email != "stranger@example.com" {
+		t.Errorf("pending invitations = %+v, want only the expired one", pending)
+	}
+}
+
+func TestOrganizationService_OwnerRules(t *testing.T) {
+	ctx := context.Background()
+	env := newOrgTestEnv(t)
+
+	owner := env.createUser(t, "owner@example.com", "enterprise")
+	ownerOrg, err := env.service.CreateOrganization(ctx, owner, &models.CreateOrganizationRequest{Name: "Acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := env.createUser(t, "admin@example.com", "free")
+	if _, err := env.service.AcceptInvitation(ctx, admin, &models.AcceptInvitationRequest{Token: env.invite(t, owner, ownerOrg, admin.Email, models.RoleAdmin)}); err != nil {
+		t.Fatal(err)
+	}
+	adminOrg := &models.Membership{Organization: ownerOrg.Organization, Role: models.RoleAdmin}
+
+	_, err = env.service.CreateInvitation(ctx, admin, adminOrg, &models.CreateInvitationRequest{Email: "new@example.com", Role: models.RoleOwner})
+	wantAppStatus(t, err, http.StatusForbidden)
+	_, err = env.service.UpdateMemberRole(ctx, adminOrg, admin.ID, &models.UpdateMemberRequest{Role: models.RoleOwner})
+	wantAppStatus(t, err, http.StatusForbidden)
+	_, err = env.service.UpdateMemberRole(ctx, adminOrg, owner.ID, &models.UpdateMemberRequest{Role: models.RoleViewer})
+	wantAppStatus(t, err, http.StatusForbidden)
+	err = env.service.RemoveMember(ctx, adminOrg, owner.ID)
+	wantAppStatus(t, err, http.StatusForbidden)
+
+	// The last owner can neither step down nor leave
+	_, err = env.service.UpdateMemberRole(ctx, ownerOrg, owner.ID, &models.UpdateMemberRequest{Role: models.RoleAdmin})
+	wantAppStatus(t, err, http.StatusConflict)
+	err = env.service.LeaveOrganization(ctx, owner, ownerOrg)
+	wantAppStatus(t, err, http.StatusConflict)
+
+	// Once ownership is handed over they can
+	if _, err := env.service.UpdateMemberRole(ctx, ownerOrg, admin.ID, &models.UpdateMemberRequest{Role: models.RoleOwner}); err != nil {
+		t.Fatalf("UpdateMemberRole() error = %v", err)
+	}
+	if err := env.service.LeaveOrganization(ctx, owner, ownerOrg); err != nil {
+		t.Fatalf("LeaveOrganization() error = %v", err)
+	}
+
+	members, err := env.service.ListMembers(ctx, ownerOrg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 1 || members[0].UserID != admin.ID || members[0].Role != models.RoleOwner {
+		t.Errorf("members = %+v, want only the new owner", members)
+	}
+	if _, err := env.service.GetOrganization(ctx, owner); err == nil {
+		t.Error("GetOrganization() still finds the organization after leaving")
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+// Padded ranges are grown to a random size in this interval so that their
+// size says nothing about the prefix that was queried.
+const (
+	minPaddedRangeSize = 800
+	maxPaddedRangeSize = 1000
+)
+
+// RangeService answers k-anonymity range queries: the caller sends a hash
+// prefix and compares the returned suffixes locally.
+type RangeService struct {
+	breachRepo        repositories.BreachRepository
+	partialHashLength int
+}
+
+// NewRangeService accepts prefixes from hashing.MinPartialHashLength up to
+// partialHashLength characters; longer prefixes would narrow the range below
+// what the server is configured to reveal.
+func NewRangeService(breachRepo repositories.BreachRepository, partialHashLength int) *RangeService {
+	return &RangeService{breachRepo: breachRepo, partialHashLength: partialHashLength}
+}
+
+// GetRange returns every suffix stored for fieldType under prefix, sorted,
+// with how often it occurs across all breaches.
+func (s *RangeService) GetRange(ctx context.Context, fieldType, prefix string) (*models.HashRange, error) {
+	if repositories.SensitiveTable(fieldType) == "" {
+		return nil, utils.NewAppError(http.StatusNotFound, fmt.Sprintf("No range data for field type %s", fieldType))
+	}
+
+	prefix = strings.ToLower(prefix)
+	if len(prefix) < hashing.MinPartialHashLength || len(prefix) > s.partialHashLength {
+		return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("prefix must be %d to %d hex characters", hashing.MinPartialHashLength, s.partialHashLength))
+	}
+	if err := hashing.ValidatePartialHash(prefix, len(prefix)); err != nil {
+		return nil, utils.NewAppError(http.StatusBadRequest, "prefix "+err.Error())
+	}
+
+	matches, err := s.breachRepo.FindSensitiveMatches(ctx, fieldType, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s range %s: %w", fieldType, prefix, err)
+	}
+
+	counts := make(map[string]int)
+	for _, hashes := range matches {
+		for hash, occurrences := range hashes {
+			counts[strings.TrimPrefix(hash, prefix)] += occurrences
+		}
+	}
+
+	hashRange := &models.HashRange{FieldType: fieldType, Prefix: prefix, Suffixes: []models.HashSuffix{}}
+	for suffix, count := range counts {
+		hashRange.Suffixes = append(hashRange.Suffixes, models.HashSuffix{Suffix: suffix, Count: count})
+	}
+	sortSuffixes(hashRange.Suffixes)
+
+	return hashRange, nil
+}
+
+// PadRange returns a copy of hashRange grown with random count-0 suffixes to
+// between minPaddedRangeSize and maxPaddedRangeSize entries. Ranges that are
+// already larger are returned unchanged.
+func PadRange(hashRange *models.HashRange) (*models.HashRange, error) {
+	spread, err := rand.Int(rand.Reader, big.NewInt(maxPaddedRangeSize-minPaddedRangeSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pick padding size: %w", err)
+	}
+	target := minPaddedRangeSize + int(spread.Int64())
+
+	// Padding must look exactly like the real suffixes
+	suffixLength := hashing.FullHashLength - len(hashRange.Prefix)
+	if len(hashRange.Suffixes) > 0 {
+		suffixLength = len(hashRange.Suffixes[0].Suffix)
+	}
+	seen := make(map[string]bool, target)
+	padded := &models.HashRange{
+		FieldType: hashRange.FieldType,
+		Prefix:    hashRange.Prefix,
+		Suffixes:  append(make([]models.HashSuffix, 0, target), hashRange.Suffixes...),
+	}
+	for _, suffix := range hashRange.Suffixes {
+		seen[suffix.Suffix] = true
+	}
+
+	buf := make([]byte, (suffixLength+1)/2)
+	for len(padded.Suffixes) < target {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate padding: %w", err)
+		}
+		suffix := hex.EncodeToString(buf)[:suffixLength]
+		if seen[suffix] {
+			continue
+		}
+		seen[suffix] = true
+		padded.Suffixes = append(padded.Suffixes, models.HashSuffix{Suffix: suffix})
+	}
+	sortSuffixes(padded.Suffixes)
+
+	return padded, nil
+}
+
+func sortSuffixes(suffixes []models.HashSuffix) {
+	sort.Slice(suffixes, func(i, j int) bool { return suffixes[i].Suffix < suffixes[j].Suffix })
+}
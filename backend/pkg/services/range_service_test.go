@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+func TestRangeService_GetRange(t *testing.T) {
+	service := NewRangeService(repositories.NewMockBreachRepository(), hashing.PartialHashLength)
+
+	tests := []struct {
+		name         string
+		fieldType    string
+		prefix       string
+		wantSuffixes int
+		wantStatus   int
+	}{
+		{name: "shared prefix", fieldType: "password", prefix: "a1b2c", wantSuffixes: 3},
+		{name: "longer prefix", fieldType: "password", prefix: "A1B2C3D4E5", wantStatus: http.StatusBadRequest},
+		{name: "configured length", fieldType: "password", prefix: "a1b2c3d4", wantSuffixes: 3},
+		{name: "no hashes", fieldType: "password", prefix: "fffff", wantSuffixes: 0},
+		{name: "too short", fieldType: "password", prefix: "a1b2", wantStatus: http.StatusBadRequest},
+		{name: "not hex", fieldType: "password", prefix: "a1b2g", wantStatus: http.StatusBadRequest},
+		{name: "personal field", fieldType: "email", prefix: "a1b2c", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashRange, err := service.GetRange(context.Background(), tt.fieldType, tt.prefix)
+			if tt.wantStatus != 0 {
+				var appErr *utils.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantStatus {
+					t.Fatalf("GetRange() error = %v, want status %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetRange() error = %v", err)
+			}
+			if len(hashRange.Suffixes) != tt.wantSuffixes {
+				t.Fatalf("got %d suffixes, want %d", len(hashRange.Suffixes), tt.wantSuffixes)
+			}
+			for i, suffix := range hashRange.Suffixes {
+				if suffix.Count != 1 || strings.HasPrefix(suffix.Suffix, tt.prefix) {
+					t.Errorf("suffix %+v not stripped or miscounted", suffix)
+				}
+				if i > 0 && hashRange.Suffixes[i-1].Suffix >= suffix.Suffix {
+					t.Errorf("suffixes not sorted at %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestPadRange(t *testing.T) {
+	service := NewRangeService(repositories.NewMockBreachRepository(), hashing.PartialHashLength)
+	hashRange, err := service.GetRange(context.Background(), "password", "a1b2c")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	padded, err := PadRange(hashRange)
+	if err != nil {
+		t.Fatalf("PadRange() error = %v", err)
+	}
+	if len(padded.Suffixes) < minPaddedRangeSize || len(padded.Suffixes) > maxPaddedRangeSize {
+		t.Errorf("padded to %d suffixes, want %d-%d", len(padded.Suffixes), minPaddedRangeSize, maxPaddedRangeSize)
+	}
+
+	real := 0
+	for _, suffix := range padded.Suffixes {
+		if suffix.Count > 0 {
+			real++
+			continue
+		}
+		if len(suffix.Suffix) != len(hashRange.Suffixes[0].Suffix) {
+			t.Fatalf("padding suffix %q does not look like a real one", suffix.Suffix)
+		}
+	}
+	if real != len(hashRange.Suffixes) {
+		t.Errorf("padded range kept %d real suffixes, want %d", real, len(hashRange.Suffixes))
+	}
+	if len(hashRange.Suffixes) != 3 {
+		t.Error("PadRange modified its input")
+	}
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+)
+
+// PlanLimits describes how much traffic a caller may send. A DailyQuota of 0
+// means unlimited; the token bucket still applies to smooth out bursts.
+// MaxBulkIdentities caps the identities of one bulk search, each of which
+// also counts against the daily quota.
+type PlanLimits struct {
+	DailyQuota        int
+	Burst             int
+	RefillPerSecond   float64
+	MaxBulkIdentities int
+}
+
+const planAnonymous = "anonymous"
+
+// Limits for searches made from the website, by plan.
+var webPlanLimits = map[string]PlanLimits{
+	planAnonymous:  {DailyQuota: 10, Burst: 5, RefillPerSecond: 0.2},
+	"free":         {DailyQuota: 10, Burst: 5, RefillPerSecond: 0.2, MaxBulkIdentities: 10},
+	"professional": {DailyQuota: 0, Burst: 20, RefillPerSecond: 2, MaxBulkIdentities: 1000},
+	"enterprise":   {DailyQuota: 0, Burst: 50, RefillPerSecond: 5, MaxBulkIdentities: MaxBulkIdentities},
+}
+
+// Limits for calls authenticated with an API key, by plan.
+var apiPlanLimits = map[string]PlanLimits{
+	"free":         {DailyQuota: 10, Burst: 5, RefillPerSecond: 0.2, MaxBulkIdentities: 10},
+	"professional": {DailyQuota: 1000, Burst: 20, RefillPerSecond: 2, MaxBulkIdentities: 1000},
+	"enterprise":   {DailyQuota: 0, Burst: 100, RefillPerSecond: 20, MaxBulkIdentities: MaxBulkIdentities},
+}
+
+// Limits shared by every member of an organization. Organizations are only
+// offered on the enterprise plan, so like enterprise accounts they have no
+// daily quota. Searches from the website and with API keys draw on the same
+// burst bucket.
+var orgLimits = PlanLimits{DailyQuota: 0, Burst: 200, RefillPerSecond: 50, MaxBulkIdentities: MaxBulkIdentities}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int // daily quota, 0 when unlimited
+	Remaining  int
+	Reset      time.Time // when the daily quota resets
+	RetryAfter time.Duration
+}
+
+type RateLimitService struct {
+	rateLimitRepo repositories.RateLimitRepository
+	pruneInterval time.Duration
+	now           func() time.Time
+}
+
+// NewRateLimitService meters callers with rateLimitRepo. Run deletes idle
+// buckets and past usage every pruneInterval so anonymous per-IP keys do not
+// pile up.
+func NewRateLimitService(rateLimitRepo repositories.RateLimitRepository, pruneInterval time.Duration) *RateLimitService {
+	return &RateLimitService{rateLimitRepo: rateLimitRepo, pruneInterval: pruneInterval, now: time.Now}
+}
+
+// Check meters one call for the caller in ctx. Members of an organization
+// are metered per organization, other API keys per key, signed-in users per
+// account and anonymous callers per client IP.
+func (s *RateLimitService) Check(ctx context.Context, clientIP string) (*RateLimitResult, error) {
+	key, limits := rateLimitSubject(ctx, clientIP)
+
+	now := s.now().UTC()
+	day := now.Truncate(24 * time.Hour)
+	result := &RateLimitResult{
+		Limit: limits.DailyQuota,
+		Reset: day.Add(24 * time.Hour),
+	}
+
+	allowed, tokens, err := s.rateLimitRepo.TakeToken(ctx, key, limits.Burst, limits.RefillPerSecond, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check burst limit: %w", err)
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration(math.Ceil((1-tokens)/limits.RefillPerSecond)) * time.Second
+		return result, nil
+	}
+
+	if limits.DailyQuota == 0 {
+		result.Allowed = true
+		return result, nil
+	}
+
+	return s.chargeQuota(ctx, key, limits, result, now)
+}
+
+// ChargeQuota counts one more call against the daily quota of the caller in
+// ctx without touching the burst limit. Bulk searches charge each identity
+// after the first this way; the request itself paid for the first.
+func (s *RateLimitService) ChargeQuota(ctx context.Context, clientIP string) (*RateLimitResult, error) {
+	key, limits := rateLimitSubject(ctx, clientIP)
+
+	now := s.now().UTC()
+	result := &RateLimitResult{
+		Limit: limits.DailyQuota,
+		Reset: now.Truncate(24 * time.Hour).Add(24 * time.Hour),
+	}
+	if limits.DailyQuota == 0 {
+		result.Allowed = true
+		return result, nil
+	}
+
+	return s.chargeQuota(ctx, key, limits, result, now)
+}
+
+func (s *RateLimitService) chargeQuota(ctx context.Context, key string, limits PlanLimits, result *RateLimitResult, now time.Time) (*RateLimitResult, error) {
+	allowed, count, err := s.rateLimitRepo.IncrementDailyUsage(ctx, key, now.Truncate(24*time.Hour), limits.DailyQuota)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check daily quota: %w", err)
+	}
+	result.Allowed = allowed
+	result.Remaining = max(limits.DailyQuota-count, 0)
+	if !allowed {
+		result.RetryAfter = result.Reset.Sub(now)
+	}
+
+	return result, nil
+}
+
+// Limits returns the plan limits that apply to the caller in ctx.
+func (s *RateLimitService) Limits(ctx context.Context, clientIP string) PlanLimits {
+	_, limits := rateLimitSubject(ctx, clientIP)
+	return limits
+}
+
+// Prune deletes buckets idle for longer than any plan takes to refill and the
+// usage counts of past days.
+func (s *RateLimitService) Prune(ctx context.Context) (int64, error) {
+	now := s.now().UTC()
+	deleted, err := s.rateLimitRepo.Prune(ctx, now.Add(-maxRefillWindow()), now.Truncate(24*time.Hour))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune rate limits: %w", err)
+	}
+	return deleted, nil
+}
+
+// Run prunes every pruneInterval until ctx is cancelled.
+func (s *RateLimitService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := s.Prune(ctx); err != nil {
+			log.Printf("Failed to prune rate limits -> %v", err)
+		}
+	}
+}
+
+// maxRefillWindow is the longest any plan takes to refill an empty bucket.
+// A bucket untouched for longer is full.
+func maxRefillWindow() time.Duration {
+	var window time.Duration
+	for _, plans := range []map[string]PlanLimits{webPlanLimits, apiPlanLimits, {"org": orgLimits}} {
+		for _, limits := range plans {
+			refill := time.Duration(math.Ceil(float64(limits.Burst)/limits.RefillPerSecond)) * time.Second
+			window = max(window, refill)
+		}
+	}
+	return window
+}
+
+func rateLimitSubject(ctx context.Context, clientIP string) (string, PlanLimits) {
+	user, hasUser := UserFromContext(ctx)
+	if membership, _ := MembershipFromContext(ctx); membership != nil && hasUser {
+		return fmt.Sprintf("org:%d", membership.ID), orgLimits
+	}
+	if apiKey, ok := APIKeyFromContext(ctx); ok && hasUser {
+		return fmt.Sprintf("key:%d", apiKey.ID), lookupPlanLimits(apiPlanLimits, user.Plan)
+	}
+	if hasUser {
+		return fmt.Sprintf("user:%d", user.ID), lookupPlanLimits(webPlanLimits, user.Plan)
+	}
+	return "ip:" + clientIP, webPlanLimits[planAnonymous]
+}
+
+// lookupPlanLimits falls back to the free tier for unknown plans.
+func lookupPlanLimits(limits map[string]PlanLimits, plan string) PlanLimits {
+	if l, ok := limits[plan]; ok {
+		return l
+	}
+	return limits["free"]
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+)
+
+func TestRateLimitService_AnonymousDailyQuota(t *testing.T) {
+	service := NewRateLimitService(repositories.NewMemoryRateLimitRepository(), time.Hour)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+	limits := webPlanLimits[planAnonymous]
+
+	for i := 0; i < limits.DailyQuota; i++ {
+		// Space calls out so the burst bucket never runs dry
+		now = now.Add(time.Minute)
+		result, err := service.Check(ctx, "192.0.2.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed {
+			t.Fatalf("call %d rejected before quota was used up", i+1)
+		}
+		if result.Remaining != limits.DailyQuota-i-1 {
+			t.Errorf("call %d remaining = %d, want %d", i+1, result.Remaining, limits.DailyQuota-i-1)
+		}
+	}
+
+	now = now.Add(time.Minute)
+	result, err := service.Check(ctx, "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed {
+		t.Fatal("call over quota was allowed")
+	}
+	if result.RetryAfter <= 0 {
+		t.Error("rejected call has no Retry-After")
+	}
+
+	// Another client is counted separately
+	if result, _ := service.Check(ctx, "192.0.2.2"); !result.Allowed {
+		t.Error("other client IP was rejected")
+	}
+
+	// Quotas reset at midnight UTC
+	now = time.Date(2025, 3, 2, 0, 1, 0, 0, time.UTC)
+	if result, _ := service.Check(ctx, "192.0.2.1"); !result.Allowed {
+		t.Error("call on the next day was rejected")
+	}
+}
+
+func TestRateLimitService_Burst(t *testing.T) {
+	service := NewRateLimitService(repositories.NewMemoryRateLimitRepository(), time.Hour)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	user := &models.User{ID: 7, Plan: "enterprise"}
+	ctx := ContextWithUser(context.Background(), user)
+	limits := webPlanLimits["enterprise"]
+
+	for i := 0; i < limits.Burst; i++ {
+		if result, _ := service.Check(ctx, "192.0.2.1"); !result.Allowed {
+			t.Fatalf("call %d within burst was rejected", i+1)
+		}
+	}
+
+	result, err := service.Check(ctx, "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed {
+		t.Fatal("call beyond burst was allowed")
+	}
+	if result.Limit != 0 {
+		t.Errorf("enterprise limit = %d, want unlimited", result.Limit)
+	}
+
+	now = now.Add(time.Second)
+	if result, _ := service.Check(ctx, "192.0.2.1"); !result.Allowed {
+		t.Error("call after refill was rejected")
+	}
+}
+
+func TestRateLimitService_OrganizationSharesBucket(t *testing.T) {
+	service := NewRateLimitService(repositories.NewMemoryRateLimitRepository(), time.Hour)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	membership := &models.Membership{Organization: models.Organization{ID: 3, Plan: "enterprise"}, Role: models.RoleAnalyst}
+	member := func(userID uint64) context.Context {
+		ctx := ContextWithUser(context.Background(), &models.User{ID: userID, Plan: "free"})
+		return ContextWithMembership(ctx, membership)
+	}
+	alice := member(1)
+	bobKey := ContextWithAPIKey(member(2), &models.APIKey{ID: 9})
+
+	// Enterprise organizations have no daily quota, but members share one
+	// burst bucket: Alice draining it holds back Bob's key too
+	for i := 0; i < orgLimits.Burst; i++ {
+		result, err := service.Check(alice, "192.0.2.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed || result.Limit != 0 {
+			t.Fatalf("call %d = %+v, want allowed without a daily quota", i+1, result)
+		}
+	}
+	if result, err := service.Check(bobKey, "192.0.2.2"); err != nil || result.Allowed {
+		t.Fatalf("Bob's call after Alice drained the bucket = %+v, %v, want rejected", result, err)
+	}
+
+	// Outside the organization the account's own plan applies
+	solo, _ := service.Check(ContextWithUser(context.Background(), &models.User{ID: 1, Plan: "free"}), "192.0.2.1")
+	if solo.Limit != webPlanLimits["free"].DailyQuota {
+		t.Errorf("personal limit = %d, want the free plan's", solo.Limit)
+	}
+}
+
+func TestRateLimitService_PruneIdleBuckets(t *testing.T) {
+	repo := repositories.NewMemoryRateLimitRepository()
+	service := NewRateLimitService(repo, time.Hour)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for _, ip := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"} {
+		if _, err := service.Check(ctx, ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Buckets still refilling are kept
+	if deleted, err := service.Prune(ctx); err != nil || deleted != 0 {
+		t.Fatalf("Prune() right away = %d, %v, want 0", deleted, err)
+	}
+
+	// A day later every bucket is full and yesterday's counts are done
+	now = now.Add(24 * time.Hour)
+	if deleted, err := service.Prune(ctx); err != nil || deleted != 6 {
+		t.Fatalf("Prune() a day later = %d, %v, want 3 buckets and 3 counts", deleted, err)
+	}
+
+	// Pruning changes nothing for the caller
+	result, err := service.Check(ctx, "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed || result.Remaining != webPlanLimits[planAnonymous].DailyQuota-1 {
+		t.Errorf("Check() after pruning = %+v, want a fresh quota", result)
+	}
+}
+
+func TestRateLimitService_ChargeQuota(t *testing.T) {
+	service := NewRateLimitService(repositories.NewMemoryRateLimitRepository(), time.Hour)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	free := ContextWithUser(context.Background(), &models.User{ID: 1, Plan: "free"})
+	quota := webPlanLimits["free"].DailyQuota
+
+	// Charges skip the burst bucket, so a bulk search can use the whole
+	// quota at once
+	for i := 0; i < quota; i++ {
+		result, err := service.ChargeQuota(free, "192.0.2.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed {
+			t.Fatalf("charge %d rejected before quota was used up", i+1)
+		}
+	}
+	if result, _ := service.ChargeQuota(free, "192.0.2.1"); result.Allowed {
+		t.Error("charge over quota was allowed")
+	}
+	if result, _ := service.Check(free, "192.0.2.1"); result.Allowed {
+		t.Error("call after charges used up the quota was allowed")
+	}
+
+	professional := ContextWithUser(context.Background(), &models.User{ID: 2, Plan: "professional"})
+	for i := 0; i < 2*quota; i++ {
+		if result, _ := service.ChargeQuota(professional, "192.0.2.1"); !result.Allowed {
+			t.Fatal("charge on an unlimited plan was rejected")
+		}
+	}
+}
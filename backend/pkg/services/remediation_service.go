@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+// Most urgent advice is listed first.
+var urgencyRank = map[string]int{"Critical": 0, "High": 1, "Medium": 2, "Low": 3}
+
+type RemediationService struct {
+	remediationRepo repositories.RemediationRepository
+}
+
+func NewRemediationService(remediationRepo repositories.RemediationRepository) *RemediationService {
+	return &RemediationService{remediationRepo: remediationRepo}
+}
+
+// ListRemediation returns the advice for fieldTypes, or every field type if
+// none are given, including advice specific to breachName if set.
+func (s *RemediationService) ListRemediation(ctx context.Context, fieldTypes []string, breachName string) (*models.RemediationResponse, error) {
+	for _, fieldType := range fieldTypes {
+		if !hashing.IsKnownFieldType(fieldType) {
+			return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unknown field type %s", fieldType))
+		}
+	}
+
+	var breachNames []string
+	if breachName != "" {
+		breachNames = []string{breachName}
+	}
+	remediations, err := s.remediationRepo.FindRemediations(ctx, fieldTypes, breachNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load remediation: %w", err)
+	}
+	sortRemediation(remediations)
+
+	return &models.RemediationResponse{Remediation: remediations}, nil
+}
+
+// Guidance loads the advice for a set of search results at once.
+func (s *RemediationService) Guidance(ctx context.Context, fieldTypes, breachNames []string) (*Guidance, error) {
+	remediations, err := s.remediationRepo.FindRemediations(ctx, fieldTypes, breachNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load remediation: %w", err)
+	}
+	return &Guidance{remediations: remediations}, nil
+}
+
+// Guidance is the advice loaded for one search. A nil Guidance has none.
+type Guidance struct {
+	remediations []models.Remediation
+}
+
+// For returns the advice for fieldTypes exposed by breachName, most urgent
+// first and advice specific to the breach before general advice.
+func (g *Guidance) For(breachName string, fieldTypes []string) []models.Remediation {
+	result := []models.Remediation{}
+	if g == nil {
+		return result
+	}
+	for _, remediation := range g.remediations {
+		if remediation.Breach != "" && remediation.Breach != breachName {
+			continue
+		}
+		if containsField(fieldTypes, remediation.FieldType) {
+			result = append(result, remediation)
+		}
+	}
+	sortRemediation(result)
+	return result
+}
+
+func sortRemediation(remediations []models.Remediation) {
+	sort.SliceStable(remediations, func(i, j int) bool {
+		a, b := remediations[i], remediations[j]
+		if urgencyRank[a.Urgency] != urgencyRank[b.Urgency] {
+			return urgencyRank[a.Urgency] < urgencyRank[b.Urgency]
+		}
+		if (a.Breach != "") != (b.Breach != "") {
+			return a.Breach != ""
+		}
+		return a.FieldType < b.FieldType
+	})
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
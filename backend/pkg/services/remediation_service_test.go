@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+func TestRemediationService_ListRemediation(t *testing.T) {
+	service := NewRemediationService(repositories.NewMockRemediationRepository())
+
+	tests := []struct {
+		name       string
+		fieldTypes []string
+		breach     string
+		want       []string // fieldType/breach of each entry, in order
+		wantStatus int
+	}{
+		{name: "everything general", want: []string{"password/", "email/", "firstName/"}},
+		{name: "one field", fieldTypes: []string{"email"}, want: []string{"email/"}},
+		{name: "breach specific first", fieldTypes: []string{"email"}, breach: "breach_linkedin_2021", want: []string{"email/breach_linkedin_2021", "email/"}},
+		{name: "unknown field", fieldTypes: []string{"shoeSize"}, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.ListRemediation(context.Background(), tt.fieldTypes, tt.breach)
+			if tt.wantStatus != 0 {
+				var appErr *utils.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantStatus {
+					t.Fatalf("ListRemediation() error = %v, want status %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ListRemediation() error = %v", err)
+			}
+			if got := remediationKeys(resp.Remediation); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListRemediation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreachService_Remediation(t *testing.T) {
+	remediation := NewRemediationService(repositories.NewMockRemediationRepository())
+	service := NewBreachService(repositories.NewMockBreachRepository(), nil, nil, remediation)
+
+	result, err := service.BreachSearch(context.Background(), &models.BreachSearchRequest{
+		Mode:   "personal",
+		Fields: map[string]string{"email": linkedinEmailHash, "firstName": linkedinFirstNameHash},
+	})
+	if err != nil {
+		t.Fatalf("BreachSearch() error = %v", err)
+	}
+	want := map[string][]string{
+		"2021-06-18": {"email/breach_linkedin_2021", "email/", "firstName/"},
+		"2019-09-04": {"firstName/"},
+	}
+	for _, match := range result.(*models.PersonalSearchResponse).ExactMatches {
+		if got := remediationKeys(match.Remediation); !reflect.DeepEqual(got, want[match.Date]) {
+			t.Errorf("remediation for %s = %v, want %v", match.Date, got, want[match.Date])
+		}
+	}
+
+	result, err = service.BreachSearch(context.Background(), &models.BreachSearchRequest{
+		Mode:   "sensitive",
+		Fields: map[string]string{"password": "a1b2c3d4"},
+	})
+	if err != nil {
+		t.Fatalf("BreachSearch() error = %v", err)
+	}
+	for _, candidate := range result.(*models.SensitiveSearchResponse).CandidateBreaches {
+		if got := remediationKeys(candidate.Remediation); !reflect.DeepEqual(got, []string{"password/"}) {
+			t.Errorf("remediation for candidate %s = %v, want [password/]", candidate.Date, got)
+		}
+	}
+}
+
+func remediationKeys(remediations []models.Remediation) []string {
+	keys := []string{}
+	for _, r := range remediations {
+		keys = append(keys, r.FieldType+"/"+r.Breach)
+	}
+	return keys
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/scoring"
+)
+
+const (
+	statisticsActivityDays   = 30
+	statisticsTopIndustries  = 5
+	statisticsTopDataTypes   = 8
+	statisticsRecentBreaches = 5
+)
+
+// Human readable names for the field types shown on the statistics page.
+var dataTypeLabels = map[string]string{
+	"email":         "Email Addresses",
+	"password":      "Passwords",
+	"username":      "Usernames",
+	"phone":         "Phone Numbers",
+	"firstName":     "First Names",
+	"lastName":      "Last Names",
+	"address":       "Physical Addresses",
+	"city":          "Cities",
+	"state":         "States",
+	"zipCode":       "Zip Codes",
+	"country":       "Countries",
+	"dateOfBirth":   "Dates of Birth",
+	"ssn":           "Social Security Numbers",
+	"creditCard":    "Credit Cards",
+	"driverLicense": "Driver's Licenses",
+	"passport":      "Passport Numbers",
+}
+
+// StatisticsService computes catalog-wide statistics from breach_metadata.
+// Results are cached and refreshed in the background so page loads never
+// wait on the aggregation.
+type StatisticsService struct {
+	breachRepo repositories.BreachRepository
+	scorer     *scoring.Scorer
+	ttl        time.Duration
+	now        func() time.Time
+
+	// refreshMu makes refreshes single-flight: callers that find the cache
+	// stale while another refresh runs wait for it instead of starting their
+	// own.
+	refreshMu sync.Mutex
+	mu        sync.RWMutex
+	cached    *models.Statistics
+}
+
+// NewStatisticsService rates breach severity with scorer; nil scores with the
+// default weights.
+func NewStatisticsService(breachRepo repositories.BreachRepository, scorer *scoring.Scorer, ttl time.Duration) *StatisticsService {
+	if scorer == nil {
+		scorer = scoring.NewScorer(nil)
+	}
+	return &StatisticsService{breachRepo: breachRepo, scorer: scorer, ttl: ttl, now: time.Now}
+}
+
+// GetStatistics returns the cached statistics, computing them first if the
+// cache is empty or older than the TTL.
+func (s *StatisticsService) GetStatistics(ctx context.Context) (*models.Statistics, error) {
+	if cached := s.fresh(); cached != nil {
+		return cached, nil
+	}
+
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	// Another caller may have refreshed while this one waited
+	if cached := s.fresh(); cached != nil {
+		return cached, nil
+	}
+	return s.refresh(ctx)
+}
+
+// Refresh recomputes the statistics and replaces the cache.
+func (s *StatisticsService) Refresh(ctx context.Context) (*models.Statistics, error) {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+	return s.refresh(ctx)
+}
+
+func (s *StatisticsService) fresh() *models.Statistics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.cached != nil && s.now().Sub(s.cached.GeneratedAt) < s.ttl {
+		return s.cached
+	}
+	return nil
+}
+
+// refresh expects refreshMu to be held.
+func (s *StatisticsService) refresh(ctx context.Context) (*models.Statistics, error) {
+	now := s.now().UTC()
+	today := now.Truncate(24 * time.Hour)
+	periods := repositories.StatisticsPeriods{
+		MonthStart:    time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		ActivityStart: today.AddDate(0, 0, -(statisticsActivityDays - 1)),
+		YearAgo:       now.Add(-365 * 24 * time.Hour),
+		TwoYearsAgo:   now.Add(-2 * 365 * 24 * time.Hour),
+	}
+
+	catalog, err := s.breachRepo.GetCatalogStatistics(ctx, periods)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate breaches for statistics: %w", err)
+	}
+	recent, _, err := s.breachRepo.ListBreaches(ctx, models.BreachListFilter{SortBy: "date", Limit: statisticsRecentBreaches})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recent breaches for statistics: %w", err)
+	}
+
+	// Severity depends on the configurable scoring weights, so breaches are
+	// scored here, one at a time
+	severityCounts := make(map[string]int)
+	err = s.breachRepo.ForEachBreach(ctx, func(breach *models.BreachMetadata) error {
+		severityCounts[s.scorer.ScoreBreach(breach, now).Severity]++
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to score breaches for statistics: %w", err)
+	}
+
+	stats := buildStatistics(catalog, periods, severityCounts, recent, s.scorer, now)
+
+	s.mu.Lock()
+	s.cached = stats
+	s.mu.Unlock()
+
+	return stats, nil
+}
+
+// Run refreshes the cache every TTL until ctx is cancelled.
+func (s *StatisticsService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.ttl)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Refresh(ctx); err != nil {
+			log.Printf("Failed to refresh statistics -> %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// buildStatistics formats the aggregates for the statistics page. recent
+// holds the newest breaches, newest first.
+func buildStatistics(catalog *repositories.CatalogStatistics, periods repositories.StatisticsPeriods, severityCounts map[string]int, recent []models.BreachMetadata, scorer *scoring.Scorer, now time.Time) *models.Statistics {
+	stats := &models.Statistics{
+		TotalRecords:         formatRecordCount(int(catalog.TotalRecords)),
+		TotalRecordsChange:   fmt.Sprintf("+%s this month", formatRecordCount(int(catalog.MonthRecords))),
+		ActiveBreaches:       catalog.Breaches,
+		ActiveBreachesChange: catalog.MonthBreaches,
+		DataSources:          fmt.Sprintf("%d", catalog.Sources),
+		DataSourcesChange:    fmt.Sprintf("+%d new this month", catalog.NewSources),
+		BreachActivity:       make([]int, statisticsActivityDays),
+		BreachSeverity:       severityPercentages(severityCounts, catalog.Breaches),
+		TopIndustries:        topIndustries(catalog.Industries),
+		DataTypes:            dataTypePercentages(catalog.FieldCounts, catalog.Breaches),
+		GeneratedAt:          now,
+	}
+
+	for day := range stats.BreachActivity {
+		stats.BreachActivity[day] = catalog.DailyBreaches[periods.ActivityStart.AddDate(0, 0, day).Format("2006-01-02")]
+	}
+
+	stats.RecentBreaches = []models.RecentBreachStat{}
+	for i := range recent {
+		breach := &recent[i]
+		stats.RecentBreaches = append(stats.RecentBreaches, models.RecentBreachStat{
+			Name:     breachLabel(breach),
+			Records:  formatRecordCount(int(breach.AffectedRecords)) + " records",
+			TimeAgo:  timeAgo(breach.Date, now),
+			Severity: scorer.ScoreBreach(breach, now).Severity,
+		})
+	}
+
+	var other int64
+	for region, records := range catalog.RegionRecords {
+		switch region {
+		case "north_america", "europe", "asia_pacific":
+		default:
+			other += records
+		}
+	}
+	stats.GlobalImpact = models.GlobalImpact{
+		NorthAmerica: formatRecordCount(int(catalog.RegionRecords["north_america"])),
+		Europe:       formatRecordCount(int(catalog.RegionRecords["europe"])),
+		AsiaPacific:  formatRecordCount(int(catalog.RegionRecords["asia_pacific"])),
+		OtherRegions: formatRecordCount(int(other)),
+	}
+
+	return stats
+}
+
+func severityPercentages(counts map[string]int, total int) models.SeverityBreakdown {
+	if total == 0 {
+		return models.SeverityBreakdown{}
+	}
+	pct := func(n int) int { return int(math.Round(float64(n) * 100 / float64(total))) }
+	return models.SeverityBreakdown{
+		Critical: pct(counts["Critical"]),
+		High:     pct(counts["High"]),
+		Medium:   pct(counts["Medium"]),
+		Low:      pct(counts["Low"]),
+	}
+}
+
+// topIndustries ranks industries by breach count. The trend compares the last
+// twelve months with the twelve before.
+func topIndustries(counts []repositories.IndustryCount) []models.IndustryStat {
+	industries := []models.IndustryStat{}
+	for _, count := range counts {
+		trend := "down"
+		if count.LastYear >= count.YearBefore {
+			trend = "up"
+		}
+		industries = append(industries, models.IndustryStat{Industry: count.Industry, Breaches: count.Breaches, Trend: trend})
+	}
+	sort.Slice(industries, func(i, j int) bool {
+		if industries[i].Breaches != industries[j].Breaches {
+			return industries[i].Breaches > industries[j].Breaches
+		}
+		return industries[i].Industry < industries[j].Industry
+	})
+
+	if len(industries) > statisticsTopIndustries {
+		industries = industries[:statisticsTopIndustries]
+	}
+	return industries
+}
+
+// dataTypePercentages reports the share of breaches exposing each field type.
+func dataTypePercentages(fieldCounts map[string]int, total int) []models.DataTypeStat {
+	dataTypes := []models.DataTypeStat{}
+	if total == 0 {
+		return dataTypes
+	}
+
+	for field, count := range fieldCounts {
+		label, ok := dataTypeLabels[field]
+		if !ok {
+			label = field
+		}
+		dataTypes = append(dataTypes, models.DataTypeStat{
+			Type:       label,
+			Percentage: int(math.Round(float64(count) * 100 / float64(total))),
+		})
+	}
+	sort.Slice(dataTypes, func(i, j int) bool {
+		if dataTypes[i].Percentage != dataTypes[j].Percentage {
+			return dataTypes[i].Percentage > dataTypes[j].Percentage
+		}
+		return dataTypes[i].Type < dataTypes[j].Type
+	})
+
+	if len(dataTypes) > statisticsTopDataTypes {
+		dataTypes = dataTypes[:statisticsTopDataTypes]
+	}
+	return dataTypes
+}
+
+func timeAgo(then, now time.Time) string {
+	days := int(now.Sub(then).Hours() / 24)
+	switch {
+	case days <= 0:
+		return "today"
+	case days == 1:
+		return "1 day ago"
+	case days < 60:
+		return fmt.Sprintf("%d days ago", days)
+	case days < 730:
+		return fmt.Sprintf("%d months ago", days/30)
+	default:
+		return fmt.Sprintf("%d years ago", days/365)
+	}
+}
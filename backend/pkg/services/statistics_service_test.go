@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/scoring"
+)
+
+func TestBuildStatistics(t *testing.T) {
+	now := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	periods := repositories.StatisticsPeriods{MonthStart: day(2025, 3, 1), ActivityStart: day(2025, 2, 19)}
+
+	catalog := &repositories.CatalogStatistics{
+		Breaches:      3,
+		MonthBreaches: 1,
+		TotalRecords:  302050000,
+		MonthRecords:  2000000,
+		Sources:       2,
+		NewSources:    1,
+		DailyBreaches: map[string]int{"2025-03-18": 1, "2025-02-25": 1},
+		FieldCounts:   map[string]int{"email": 2, "password": 1, "phone": 1},
+		Industries: []repositories.IndustryCount{
+			{Industry: "Telecom", Breaches: 1},
+			{Industry: "Retail", Breaches: 2, LastYear: 2},
+		},
+		RegionRecords: map[string]int64{"europe": 2000000, "north_america": 50000, "": 300000000},
+	}
+	recent := []models.BreachMetadata{
+		{Name: "recent", Date: day(2025, 3, 18), AffectedRecords: 2000000, Fields: []string{"email", "password"}},
+	}
+
+	stats := buildStatistics(catalog, periods, map[string]int{"High": 1, "Medium": 2}, recent, scoring.NewScorer(nil), now)
+
+	if stats.TotalRecords != "302.1M" || stats.TotalRecordsChange != "+2.0M this month" {
+		t.Errorf("TotalRecords = %s (%s), want 302.1M (+2.0M this month)", stats.TotalRecords, stats.TotalRecordsChange)
+	}
+	if stats.ActiveBreaches != 3 || stats.ActiveBreachesChange != 1 {
+		t.Errorf("ActiveBreaches = %d (+%d), want 3 (+1)", stats.ActiveBreaches, stats.ActiveBreachesChange)
+	}
+	if stats.DataSources != "2" || stats.DataSourcesChange != "+1 new this month" {
+		t.Errorf("DataSources = %s (%s), want 2 (+1 new this month)", stats.DataSources, stats.DataSourcesChange)
+	}
+	if len(stats.BreachActivity) != statisticsActivityDays {
+		t.Fatalf("BreachActivity has %d days, want %d", len(stats.BreachActivity), statisticsActivityDays)
+	}
+	if stats.BreachActivity[statisticsActivityDays-3] != 1 || stats.BreachActivity[statisticsActivityDays-24] != 1 {
+		t.Errorf("BreachActivity = %v", stats.BreachActivity)
+	}
+	if stats.BreachSeverity.High != 33 || stats.BreachSeverity.Medium != 67 {
+		t.Errorf("BreachSeverity = %+v", stats.BreachSeverity)
+	}
+	if len(stats.TopIndustries) != 2 || stats.TopIndustries[0].Industry != "Retail" || stats.TopIndustries[0].Trend != "up" {
+		t.Errorf("TopIndustries = %+v", stats.TopIndustries)
+	}
+	if stats.DataTypes[0].Type != "Email Addresses" || stats.DataTypes[0].Percentage != 67 {
+		t.Errorf("DataTypes = %+v", stats.DataTypes)
+	}
+	if stats.RecentBreaches[0].Name != "recent" || stats.RecentBreaches[0].TimeAgo != "2 days ago" {
+		t.Errorf("RecentBreaches = %+v", stats.RecentBreaches)
+	}
+	if stats.GlobalImpact.Europe != "2.0M" || stats.GlobalImpact.OtherRegions != "300.0M" {
+		t.Errorf("GlobalImpact = %+v", stats.GlobalImpact)
+	}
+}
+
+func TestStatisticsService_FromRepository(t *testing.T) {
+	repo := repositories.NewMockBreachRepository()
+	service := NewStatisticsService(repo, nil, time.Minute)
+	now := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	repo.AddBreach(models.BreachMetadata{ID: 10, Name: "breach_shop_2025", Date: time.Date(2025, 3, 18, 0, 0, 0, 0, time.UTC), AffectedRecords: 1000, Fields: []string{"email"}, SourceURL: "https://shop.example"})
+
+	stats, err := service.GetStatistics(context.Background())
+	if err != nil {
+		t.Fatalf("GetStatistics() error = %v", err)
+	}
+	if stats.ActiveBreaches != 4 || stats.ActiveBreachesChange != 1 || stats.DataSourcesChange != "+1 new this month" {
+		t.Errorf("GetStatistics() = %d (+%d), sources %s", stats.ActiveBreaches, stats.ActiveBreachesChange, stats.DataSourcesChange)
+	}
+	if stats.BreachActivity[statisticsActivityDays-3] != 1 {
+		t.Errorf("BreachActivity = %v", stats.BreachActivity)
+	}
+	if len(stats.RecentBreaches) != 4 || stats.RecentBreaches[0].Name != "breach_shop_2025" {
+		t.Errorf("RecentBreaches = %+v", stats.RecentBreaches)
+	}
+}
+
+func TestStatisticsService_Caches(t *testing.T) {
+	service := NewStatisticsService(repositories.NewMockBreachRepository(), nil, time.Minute)
+	now := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	first, err := service.GetStatistics(ctx)
+	if err != nil {
+		t.Fatalf("GetStatistics() error = %v", err)
+	}
+
+	now = now.Add(30 * time.Second)
+	if second, _ := service.GetStatistics(ctx); second != first {
+		t.Error("GetStatistics() recomputed within the TTL")
+	}
+
+	now = now.Add(time.Minute)
+	if third, _ := service.GetStatistics(ctx); third == first {
+		t.Error("GetStatistics() served stale statistics after the TTL")
+	}
+}
+
+// slowStatisticsRepository counts aggregations and makes them slow enough
+// for callers to overlap.
+type slowStatisticsRepository struct {
+	*repositories.MockBreachRepository
+	calls atomic.Int32
+}
+
+func (r *slowStatisticsRepository) GetCatalogStatistics(ctx context.Context, periods repositories.StatisticsPeriods) (*repositories.CatalogStatistics, error) {
+	r.calls.Add(1)
+	time.Sleep(20 * time.Millisecond)
+	return r.MockBreachRepository.GetCatalogStatistics(ctx, periods)
+}
+
+func TestStatisticsService_SingleFlight(t *testing.T) {
+	repo := &slowStatisticsRepository{MockBreachRepository: repositories.NewMockBreachRepository()}
+	service := NewStatisticsService(repo, nil, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.GetStatistics(context.Background()); err != nil {
+				t.Errorf("GetStatistics() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls := repo.calls.Load(); calls != 1 {
+		t.Errorf("concurrent GetStatistics() aggregated %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+const (
+	maxWatchlistLabelLength = 100
+	maxWatchlistEntries     = 50
+	watchlistScanBatchSize  = 500
+)
+
+var (
+	errWatchlistEntryNotFound = utils.NewAppError(http.StatusNotFound, "Watchlist entry not found")
+	errWatchlistAlertNotFound = utils.NewAppError(http.StatusNotFound, "Alert not found")
+)
+
+// WatchlistService stores identities users want monitored and checks every
+// newly registered breach against them.
+//
+// Only personal fields can be watched. Sensitive fields are searched by
+// partial hash so the server never learns them, and keeping their full
+// hashes around would undo that.
+type WatchlistService struct {
+	watchRepo  repositories.WatchlistRepository
+	breachRepo repositories.BreachRepository
+	schemes    *hashing.Schemes
+	events     EventPublisher
+	interval   time.Duration
+	now        func() time.Time
+}
+
+// NewWatchlistService stores hashes with the latest of schemes; nil means
+// only v1. Like searches, hashes cannot be derived backwards, so watched
+// hashes only match rows of the version they were stored with or a newer
+// one. That covers every breach the ingester writes from then on.
+//
+// Scans publish breach.added for every new breach and breach.match for
+// every alert to events, which may be nil.
+func NewWatchlistService(watchRepo repositories.WatchlistRepository, breachRepo repositories.BreachRepository, schemes *hashing.Schemes, events EventPublisher, interval time.Duration) *WatchlistService {
+	if schemes == nil {
+		schemes = hashing.V1Schemes()
+	}
+	return &WatchlistService{watchRepo: watchRepo, breachRepo: breachRepo, schemes: schemes, events: events, interval: interval, now: time.Now}
+}
+
+func (s *WatchlistService) CreateEntry(ctx context.Context, user *models.User, req *models.CreateWatchlistEntryRequest) (*models.WatchlistEntry, error) {
+	label := strings.TrimSpace(req.Label)
+	if label == "" {
+		return nil, utils.NewAppError(http.StatusBadRequest, "Label is required")
+	}
+	if len(label) > maxWatchlistLabelLength {
+		return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Label must be at most %d characters", maxWatchlistLabelLength))
+	}
+	if len(req.Fields) == 0 {
+		return nil, utils.NewAppError(http.StatusBadRequest, "At least one field is required")
+	}
+
+	latest := s.schemes.Latest()
+	hashes := make(map[string]string, len(req.Fields))
+	for fieldType, hash := range req.Fields {
+		if repositories.PersonalColumn(fieldType) == "" {
+			return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Field %s cannot be watched", fieldType))
+		}
+		if !hashing.IsFullHash(hash) {
+			return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid hash for %s", fieldType))
+		}
+		derived, err := s.schemes.Derive(hash, hashing.SchemeV1, latest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive hash for %s: %w", fieldType, err)
+		}
+		hashes[fieldType] = derived
+	}
+
+	existing, err := s.watchRepo.ListEntries(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watchlist entries: %w", err)
+	}
+	if len(existing) >= maxWatchlistEntries {
+		return nil, utils.NewAppError(http.StatusConflict, fmt.Sprintf("At most %d watchlist entries are allowed", maxWatchlistEntries))
+	}
+
+	entry := &models.WatchlistEntry{
+		UserID:      user.ID,
+		Label:       label,
+		Hashes:      hashes,
+		HashVersion: latest,
+	}
+	if err := s.watchRepo.CreateEntry(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to create watchlist entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+func (s *WatchlistService) ListEntries(ctx context.Context, user *models.User) ([]models.WatchlistEntry, error) {
+	entries, err := s.watchRepo.ListEntries(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watchlist entries: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *WatchlistService) DeleteEntry(ctx context.Context, user *models.User, entryID uint64) error {
+	if err := s.watchRepo.DeleteEntry(ctx, user.ID, entryID); err != nil {
+		if errors.Is(err, repositories.ErrWatchlistEntryNotFound) {
+			return errWatchlistEntryNotFound
+		}
+		return fmt.Errorf("failed to delete watchlist entry: %w", err)
+	}
+	return nil
+}
+
+func (s *WatchlistService) ListAlerts(ctx context.Context, user *models.User, unacknowledgedOnly bool) ([]models.WatchlistAlert, error) {
+	alerts, err := s.watchRepo.ListAlerts(ctx, user.ID, unacknowledgedOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+func (s *WatchlistService) AcknowledgeAlert(ctx context.Context, user *models.User, alertID uint64) error {
+	if err := s.watchRepo.AcknowledgeAlert(ctx, user.ID, alertID, s.now().UTC()); err != nil {
+		if errors.Is(err, repositories.ErrWatchlistAlertNotFound) {
+			return errWatchlistAlertNotFound
+		}
+		return fmt.Errorf("failed to acknowledge alert: %w", err)
+	}
+	return nil
+}
+
+// ScanNewBreaches checks every watchlist entry against the breaches that
+// were registered since the last scan. A breach is only marked scanned
+// together with its alerts. A breach whose scan fails is logged and left
+// unscanned, so it is retried on the next run while the breaches after it
+// are still scanned.
+func (s *WatchlistService) ScanNewBreaches(ctx context.Context) (int, error) {
+	breaches, err := s.watchRepo.FindUnscannedBreaches(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find unscanned breaches: %w", err)
+	}
+
+	total := 0
+	for _, breach := range breaches {
+		alerts, err := s.scanBreach(ctx, breach)
+		if err != nil {
+			RequestLogger(ctx).ErrorContext(ctx, "Failed to scan breach for watchlists", "breach", breach.Name, "error", err)
+			continue
+		}
+		completed, err := s.watchRepo.CompleteBreachScan(ctx, breach.ID, alerts)
+		if err != nil {
+			RequestLogger(ctx).ErrorContext(ctx, "Failed to store watchlist scan", "breach", breach.Name, "error", err)
+			continue
+		}
+		if !completed {
+			continue
+		}
+		total += len(alerts)
+		s.publishScan(ctx, breach, alerts)
+	}
+	return total, nil
+}
+
+// publishScan runs after the scan is stored, so a failure to queue events
+// is logged rather than retried.
+func (s *WatchlistService) publishScan(ctx context.Context, breach models.BreachMetadata, alerts []models.WatchlistAlert) {
+	if s.events == nil {
+		return
+	}
+
+	events := []models.WebhookEvent{{Type: models.EventBreachAdded, Data: breach}}
+	for _, alert := range alerts {
+		events = append(events, models.WebhookEvent{
+			Type:   models.EventBreachMatch,
+			UserID: alert.UserID,
+			Data: models.BreachMatchEvent{
+				EntryID:       alert.EntryID,
+				EntryLabel:    alert.EntryLabel,
+				Breach:        breach.Name,
+				BreachDate:    breach.Date,
+				MatchedFields: alert.MatchedFields,
+			},
+		})
+	}
+
+	for _, event := range events {
+		if err := s.events.Publish(ctx, event); err != nil {
+			RequestLogger(ctx).ErrorContext(ctx, "Failed to publish watchlist event", "event", event.Type, "breach", breach.Name, "error", err)
+		}
+	}
+}
+
+func (s *WatchlistService) scanBreach(ctx context.Context, breach models.BreachMetadata) ([]models.WatchlistAlert, error) {
+	var fieldTypes []string
+	for _, fieldType := range breach.Fields {
+		if repositories.PersonalColumn(fieldType) != "" {
+			fieldTypes = append(fieldTypes, fieldType)
+		}
+	}
+
+	var alerts []models.WatchlistAlert
+	if len(fieldTypes) == 0 {
+		return alerts, nil
+	}
+
+	var afterID uint64
+	for {
+		entries, err := s.watchRepo.ListAllEntries(ctx, afterID, watchlistScanBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list watchlist entries: %w", err)
+		}
+		if len(entries) == 0 {
+			return alerts, nil
+		}
+
+		batchAlerts, err := s.matchEntries(ctx, breach.Name, fieldTypes, entries)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, batchAlerts...)
+		afterID = entries[len(entries)-1].ID
+	}
+}
+
+// matchEntries looks up every watched hash of the batch with one query per
+// field type. Entries stored before a scheme was added are expanded to the
+// newer versions first.
+func (s *WatchlistService) matchEntries(ctx context.Context, breachName string, fieldTypes []string, entries []models.WatchlistEntry) ([]models.WatchlistAlert, error) {
+	matched := make(map[uint64][]string)
+
+	for _, fieldType := range fieldTypes {
+		owners := make(map[string][]uint64)
+		var hashes []string
+		for _, entry := range entries {
+			hash, ok := entry.Hashes[fieldType]
+			if !ok {
+				continue
+			}
+			derived, err := s.schemes.DeriveActive(hash, entry.HashVersion)
+			if err != nil {
+				RequestLogger(ctx).WarnContext(ctx, "Skipping watchlist entry", "entry_id", entry.ID, "breach", breachName, "error", err)
+				continue
+			}
+			for _, candidate := range derived {
+				if _, seen := owners[candidate]; !seen {
+					hashes = append(hashes, candidate)
+				}
+				owners[candidate] = append(owners[candidate], entry.ID)
+			}
+		}
+		if len(hashes) == 0 {
+			continue
+		}
+
+		present, err := s.breachRepo.FindPresentHashes(ctx, breachName, fieldType, hashes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up %s hashes: %w", fieldType, err)
+		}
+		for _, hash := range present {
+			for _, entryID := range owners[hash] {
+				if !containsField(matched[entryID], fieldType) {
+					matched[entryID] = append(matched[entryID], fieldType)
+				}
+			}
+		}
+	}
+
+	var alerts []models.WatchlistAlert
+	for _, entry := range entries {
+		fields := matched[entry.ID]
+		if len(fields) == 0 {
+			continue
+		}
+		sort.Strings(fields)
+		alerts = append(alerts, models.WatchlistAlert{
+			EntryID:       entry.ID,
+			EntryLabel:    entry.Label,
+			UserID:        entry.UserID,
+			Breach:        breachName,
+			MatchedFields: fields,
+		})
+	}
+	return alerts, nil
+}
+
+// Run scans for new breaches every interval until ctx is cancelled.
+func (s *WatchlistService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ScanNewBreaches(ctx); err != nil {
+			log.Printf("Failed to scan new breaches for watchlists -> %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
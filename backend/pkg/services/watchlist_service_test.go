@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/hashing"
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+)
+
+func TestWatchlistService_CreateEntryValidation(t *testing.T) {
+	validHash := strings.Repeat("ab", 64)
+
+	tests := []struct {
+		name       string
+		req        models.CreateWatchlistEntryRequest
+		wantStatus int
+	}{
+		{name: "valid", req: models.CreateWatchlistEntryRequest{Label: "Work email", Fields: map[string]string{"email": validHash}}},
+		{name: "missing label", req: models.CreateWatchlistEntryRequest{Label: "  ", Fields: map[string]string{"email": validHash}}, wantStatus: http.StatusBadRequest},
+		{name: "long label", req: models.CreateWatchlistEntryRequest{Label: strings.Repeat("x", 101), Fields: map[string]string{"email": validHash}}, wantStatus: http.StatusBadRequest},
+		{name: "no fields", req: models.CreateWatchlistEntryRequest{Label: "Empty"}, wantStatus: http.StatusBadRequest},
+		{name: "sensitive field", req: models.CreateWatchlistEntryRequest{Label: "Password", Fields: map[string]string{"password": validHash}}, wantStatus: http.StatusBadRequest},
+		{name: "partial hash", req: models.CreateWatchlistEntryRequest{Label: "Partial", Fields: map[string]string{"email": validHash[:8]}}, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breachRepo := repositories.NewMockBreachRepository()
+			service := NewWatchlistService(repositories.NewMockWatchlistRepository(breachRepo), breachRepo, nil, nil, time.Minute)
+
+			entry, err := service.CreateEntry(context.Background(), &models.User{ID: 1}, &tt.req)
+			if tt.wantStatus != 0 {
+				var appErr *utils.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantStatus {
+					t.Fatalf("CreateEntry() error = %v, want status %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateEntry() error = %v", err)
+			}
+			if !reflect.DeepEqual(entry.Fields, []string{"email"}) {
+				t.Errorf("CreateEntry() fields = %v, want [email]", entry.Fields)
+			}
+		})
+	}
+}
+
+func TestWatchlistService_ScanNewBreaches(t *testing.T) {
+	ctx := context.Background()
+	pepper := strings.Repeat("11", 32)
+	schemes, err := hashing.ParseSchemes("2:"+pepper, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emailHash := strings.Repeat("a1", 64)
+	phoneHash := strings.Repeat("b2", 64)
+	otherHash := strings.Repeat("c3", 64)
+
+	breachRepo := repositories.NewMockBreachRepository()
+	watchRepo := repositories.NewMockWatchlistRepository(breachRepo)
+	events := &recordingPublisher{}
+	service := NewWatchlistService(watchRepo, breachRepo, schemes, events, time.Minute)
+
+	alice := &models.User{ID: 1}
+	bob := &models.User{ID: 2}
+	aliceEntry, err := service.CreateEntry(ctx, alice, &models.CreateWatchlistEntryRequest{
+		Label:  "Personal",
+		Fields: map[string]string{"email": emailHash, "phone": phoneHash},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aliceEntry.HashVersion != 2 || aliceEntry.Hashes["email"] == emailHash {
+		t.Errorf("CreateEntry() stored v%d hashes, want them derived to v2", aliceEntry.HashVersion)
+	}
+	if _, err := service.CreateEntry(ctx, bob, &models.CreateWatchlistEntryRequest{
+		Label:  "Unrelated",
+		Fields: map[string]string{"email": otherHash},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// New breaches are ingested with the latest scheme
+	v2Email, _ := schemes.Derive(emailHash, 1, 2)
+	v2Phone, _ := schemes.Derive(phoneHash, 1, 2)
+	breachRepo.AddBreach(models.BreachMetadata{ID: 10, Name: "breach_new_2024", Fields: []string{"email", "phone", "password"}})
+	breachRepo.AddPersonalRecord("breach_new_2024", map[string]string{"email": v2Email})
+	breachRepo.AddPersonalRecord("breach_new_2024", map[string]string{"phone": v2Phone})
+
+	created, err := service.ScanNewBreaches(ctx)
+	if err != nil {
+		t.Fatalf("ScanNewBreaches() error = %v", err)
+	}
+	if created != 1 {
+		t.Fatalf("ScanNewBreaches() created %d alerts, want 1", created)
+	}
+
+	if len(events.events) != 2 || events.events[0].Type != models.EventBreachAdded ||
+		events.events[1].Type != models.EventBreachMatch || events.events[1].UserID != alice.ID {
+		t.Errorf("published events = %+v, want breach.added then breach.match for alice", events.events)
+	}
+
+	// Breaches that existed before are never scanned, and a rescan is a no-op
+	if created, err := service.ScanNewBreaches(ctx); err != nil || created != 0 {
+		t.Errorf("second ScanNewBreaches() = %d, %v, want 0, nil", created, err)
+	}
+
+	alerts, err := service.ListAlerts(ctx, alice, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("ListAlerts() = %d alerts, want 1", len(alerts))
+	}
+	alert := alerts[0]
+	if alert.Breach != "breach_new_2024" || alert.EntryLabel != "Personal" || !reflect.DeepEqual(alert.MatchedFields, []string{"email", "phone"}) {
+		t.Errorf("ListAlerts() = %+v", alert)
+	}
+
+	if bobAlerts, _ := service.ListAlerts(ctx, bob, false); len(bobAlerts) != 0 {
+		t.Errorf("ListAlerts() for bob = %d alerts, want 0", len(bobAlerts))
+	}
+
+	var appErr *utils.AppError
+	if err := service.AcknowledgeAlert(ctx, bob, alert.ID); !errors.As(err, &appErr) || appErr.Code != http.StatusNotFound {
+		t.Errorf("AcknowledgeAlert() by another user error = %v, want 404", err)
+	}
+	if err := service.AcknowledgeAlert(ctx, alice, alert.ID); err != nil {
+		t.Fatalf("AcknowledgeAlert() error = %v", err)
+	}
+	if unacknowledged, _ := service.ListAlerts(ctx, alice, true); len(unacknowledged) != 0 {
+		t.Errorf("ListAlerts(unacknowledged) = %d alerts, want 0", len(unacknowledged))
+	}
+
+	if err := service.DeleteEntry(ctx, bob, aliceEntry.ID); !errors.As(err, &appErr) || appErr.Code != http.StatusNotFound {
+		t.Errorf("DeleteEntry() by another user error = %v, want 404", err)
+	}
+	if err := service.DeleteEntry(ctx, alice, aliceEntry.ID); err != nil {
+		t.Fatalf("DeleteEntry() error = %v", err)
+	}
+	if alerts, _ := service.ListAlerts(ctx, alice, false); len(alerts) != 0 {
+		t.Errorf("ListAlerts() after delete = %d alerts, want 0", len(alerts))
+	}
+}
+
+type recordingPublisher struct {
+	events []models.WebhookEvent
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event models.WebhookEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestWatchlistService_ScanSkipsFailedBreach(t *testing.T) {
+	ctx := context.Background()
+	emailHash := strings.Repeat("a1", 64)
+
+	breachRepo := &failingBreachRepository{MockBreachRepository: repositories.NewMockBreachRepository(), broken: "breach_broken_2024"}
+	watchRepo := repositories.NewMockWatchlistRepository(breachRepo.MockBreachRepository)
+	service := NewWatchlistService(watchRepo, breachRepo, nil, nil, time.Minute)
+
+	alice := &models.User{ID: 1}
+	if _, err := service.CreateEntry(ctx, alice, &models.CreateWatchlistEntryRequest{Label: "Personal", Fields: map[string]string{"email": emailHash}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The broken breach comes first; the good one is still scanned
+	breachRepo.AddBreach(models.BreachMetadata{ID: 10, Name: "breach_broken_2024", Fields: []string{"email"}})
+	breachRepo.AddBreach(models.BreachMetadata{ID: 11, Name: "breach_good_2024", Fields: []string{"email"}})
+	breachRepo.AddPersonalRecord("breach_broken_2024", map[string]string{"email": emailHash})
+	breachRepo.AddPersonalRecord("breach_good_2024", map[string]string{"email": emailHash})
+
+	if created, err := service.ScanNewBreaches(ctx); err != nil || created != 1 {
+		t.Fatalf("ScanNewBreaches() = %d, %v, want 1 alert from the good breach", created, err)
+	}
+
+	// Only the failed breach is left to retry
+	unscanned, err := watchRepo.FindUnscannedBreaches(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unscanned) != 1 || unscanned[0].Name != "breach_broken_2024" {
+		t.Fatalf("unscanned breaches = %+v, want breach_broken_2024 only", unscanned)
+	}
+
+	breachRepo.broken = ""
+	if created, err := service.ScanNewBreaches(ctx); err != nil || created != 1 {
+		t.Fatalf("ScanNewBreaches() retry = %d, %v, want 1 alert from the fixed breach", created, err)
+	}
+}
@@ -0,0 +1,453 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Rikjimue/breach-radar/backend/pkg/models"
+	"github.com/Rikjimue/breach-radar/backend/pkg/repositories"
+	"github.com/Rikjimue/breach-radar/backend/pkg/utils"
+	"github.com/Rikjimue/breach-radar/backend/pkg/webhook"
+)
+
+const (
+	maxWebhooksPerUser     = 10
+	minWebhookSecretLength = 16
+	maxWebhookSecretLength = 256
+	maxWebhookURLLength    = 2048
+
+	webhookBatchSize     = 50
+	webhookTimeout       = 10 * time.Second
+	webhookLease         = time.Minute // longer than webhookTimeout, as a batch is sent at once
+	webhookMaxAttempts   = 10
+	webhookRetryBase     = 30 * time.Second
+	webhookMaxRetryDelay = 6 * time.Hour
+	webhookLogLimit      = 100
+	webhookErrorLength   = 200
+)
+
+var (
+	errWebhookAddress          = utils.NewAppError(http.StatusBadRequest, "Webhook URL must not point to a private or local address")
+	errWebhookNotFound         = utils.NewAppError(http.StatusNotFound, "Webhook not found")
+	errWebhookDeliveryNotFound = utils.NewAppError(http.StatusNotFound, "Dead delivery not found")
+)
+
+// EventPublisher hands events to the webhooks subscribed to them.
+type EventPublisher interface {
+	Publish(ctx context.Context, event models.WebhookEvent) error
+}
+
+// WebhookService manages webhook subscriptions and delivers events to them.
+// Publishing only queues deliveries; Run sends them, retrying failures with
+// exponential backoff until webhookMaxAttempts, after which they are dead
+// until retried through the API.
+type WebhookService struct {
+	webhookRepo repositories.WebhookRepository
+	client      *http.Client
+	interval    time.Duration
+	now         func() time.Time
+	maxAttempts int
+	retryBase   time.Duration
+
+	// allowAddress reports whether webhooks may reach ip. CreateWebhook
+	// checks the addresses a URL resolves to through lookupIP, and the
+	// default client checks the address it actually dials, so a host that
+	// resolves elsewhere later is still refused.
+	allowAddress func(ip netip.Addr) bool
+	lookupIP     func(ctx context.Context, host string) ([]netip.Addr, error)
+}
+
+// NewWebhookService sends with client; nil uses a client with a
+// webhookTimeout timeout that only dials public addresses. Redirects are
+// never followed, so a signed request only ever reaches the configured URL.
+func NewWebhookService(webhookRepo repositories.WebhookRepository, client *http.Client, interval time.Duration) *WebhookService {
+	s := &WebhookService{
+		webhookRepo:  webhookRepo,
+		interval:     interval,
+		now:          time.Now,
+		maxAttempts:  webhookMaxAttempts,
+		retryBase:    webhookRetryBase,
+		allowAddress: publicAddress,
+		lookupIP: func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		},
+	}
+
+	if client == nil {
+		allow := func(ip netip.Addr) bool { return s.allowAddress(ip) }
+		client = &http.Client{Timeout: webhookTimeout, Transport: guardedTransport(webhookTimeout, allow)}
+	}
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	s.client = &noRedirects
+
+	return s
+}
+
+// guardedTransport dials hosts directly, never through a proxy, and refuses
+// addresses allow rejects once the host is resolved. It is used wherever the
+// server fetches a URL a customer chose.
+func guardedTransport(timeout time.Duration, allow func(ip netip.Addr) bool) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !allow(addrPort.Addr()) {
+				return fmt.Errorf("address %s is not allowed", addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// publicAddress rejects loopback, private, link-local, multicast and
+// unspecified addresses, so customer URLs cannot reach the server's own
+// network.
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate()
+}
+
+func (s *WebhookService) CreateWebhook(ctx context.Context, user *models.User, req *models.CreateWebhookRequest) (*models.CreateWebhookResponse, error) {
+	endpoint, err := validateWebhookURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWebhookHost(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	eventTypes, err := validateWebhookEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateToken(); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+	} else if len(secret) < minWebhookSecretLength || len(secret) > maxWebhookSecretLength {
+		return nil, utils.NewAppError(http.StatusBadRequest, fmt.Sprintf("Secret must be %d to %d characters", minWebhookSecretLength, maxWebhookSecretLength))
+	}
+
+	existing, err := s.webhookRepo.ListWebhooks(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	if len(existing) >= maxWebhooksPerUser {
+		return nil, utils.NewAppError(http.StatusConflict, fmt.Sprintf("At most %d webhooks are allowed", maxWebhooksPerUser))
+	}
+
+	hook := &models.Webhook{
+		UserID:     user.ID,
+		URL:        endpoint,
+		Secret:     secret,
+		EventTypes: eventTypes,
+	}
+	if err := s.webhookRepo.CreateWebhook(ctx, hook); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return &models.CreateWebhookResponse{Secret: secret, Webhook: hook}, nil
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context, user *models.User) ([]models.Webhook, error) {
+	webhooks, err := s.webhookRepo.ListWebhooks(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, user *models.User, webhookID uint64) error {
+	if err := s.webhookRepo.DeleteWebhook(ctx, user.ID, webhookID); err != nil {
+		if errors.Is(err, repositories.ErrWebhookNotFound) {
+			return errWebhookNotFound
+		}
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first. An
+// empty status lists every delivery; "dead" is the dead-letter list.
+func (s *WebhookService) ListDeliveries(ctx context.Context, user *models.User, webhookID uint64, status string) ([]models.WebhookDelivery, error) {
+	switch status {
+	case "", models.DeliveryPending, models.DeliverySucceeded, models.DeliveryDead:
+	default:
+		return nil, utils.NewAppError(http.StatusBadRequest, "Unknown delivery status "+status)
+	}
+
+	if err := s.requireWebhook(ctx, user, webhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.webhookRepo.ListDeliveries(ctx, user.ID, webhookID, status, webhookLogLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RetryDelivery queues a dead delivery again with a fresh set of attempts.
+func (s *WebhookService) RetryDelivery(ctx context.Context, user *models.User, deliveryID uint64) error {
+	if err := s.webhookRepo.RetryDelivery(ctx, user.ID, deliveryID, s.now().UTC()); err != nil {
+		if errors.Is(err, repositories.ErrWebhookDeliveryNotFound) {
+			return errWebhookDeliveryNotFound
+		}
+		return fmt.Errorf("failed to retry webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (s *WebhookService) requireWebhook(ctx context.Context, user *models.User, webhookID uint64) error {
+	webhooks, err := s.webhookRepo.ListWebhooks(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	for _, hook := range webhooks {
+		if hook.ID == webhookID {
+			return nil
+		}
+	}
+	return errWebhookNotFound
+}
+
+// Publish queues event for every webhook subscribed to it. The payload is
+// fixed here, so retries send the same body.
+func (s *WebhookService) Publish(ctx context.Context, event models.WebhookEvent) error {
+	now := s.now().UTC()
+	if event.ID == "" {
+		id, err := generateToken()
+		if err != nil {
+			return fmt.Errorf("failed to generate event id: %w", err)
+		}
+		event.ID = id
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = now
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+	}
+	if _, err := s.webhookRepo.EnqueueEvent(ctx, event, payload, now); err != nil {
+		return fmt.Errorf("failed to queue %s event: %w", event.Type, err)
+	}
+	return nil
+}
+
+// DeliverDue sends every delivery that is due and returns how many were
+// attempted. A claimed batch is sent concurrently, so each delivery is
+// recorded within webhookTimeout of the claim and never outlives its lease.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		now := s.now().UTC()
+		deliveries, err := s.webhookRepo.ClaimDueDeliveries(ctx, now, now.Add(webhookLease), webhookBatchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+		}
+		if len(deliveries) == 0 {
+			return total, nil
+		}
+
+		errs := make([]error, len(deliveries))
+		var wg sync.WaitGroup
+		for i, delivery := range deliveries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = s.deliver(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err == nil {
+				total++
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return total, err
+		}
+	}
+}
+
+func (s *WebhookService) deliver(ctx context.Context, delivery models.WebhookDelivery) error {
+	started := s.now().UTC()
+	attempt := models.WebhookAttempt{AttemptedAt: started}
+
+	statusCode, err := s.send(ctx, delivery, started)
+	attempt.StatusCode = statusCode
+	attempt.DurationMs = s.now().Sub(started).Milliseconds()
+	if err != nil {
+		attempt.Error = truncate(err.Error(), webhookErrorLength)
+	}
+
+	status := models.DeliverySucceeded
+	var nextAttemptAt *time.Time
+	if err != nil {
+		if delivery.Attempts+1 >= s.maxAttempts {
+			status = models.DeliveryDead
+		} else {
+			status = models.DeliveryPending
+			next := started.Add(s.backoff(delivery.Attempts + 1))
+			nextAttemptAt = &next
+		}
+	}
+
+	if err := s.webhookRepo.RecordAttempt(ctx, delivery.ID, attempt, status, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	if status == models.DeliveryDead {
+		RequestLogger(ctx).WarnContext(ctx, "Webhook delivery is dead",
+			"delivery_id", delivery.ID, "url", delivery.URL, "attempts", s.maxAttempts, "error", attempt.Error)
+	}
+	return nil
+}
+
+// send signs the payload with the time of this attempt, so a retry is not
+// rejected as a replay.
+func (s *WebhookService) send(ctx context.Context, delivery models.WebhookDelivery, at time.Time) (int, error) {
+	// Bounded here too, as a client passed in may have no timeout
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := at.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "BreachRadar-Webhooks/1.0")
+	req.Header.Set(webhook.EventHeader, delivery.EventType)
+	req.Header.Set(webhook.DeliveryHeader, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(webhook.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the attempt after the given number of
+// failed ones: retryBase, doubling each time, capped at
+// webhookMaxRetryDelay.
+func (s *WebhookService) backoff(failed int) time.Duration {
+	delay := s.retryBase
+	for i := 1; i < failed && delay < webhookMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookMaxRetryDelay)
+}
+
+// Run delivers due webhooks every interval until ctx is cancelled.
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.DeliverDue(ctx); err != nil {
+			log.Printf("Failed to deliver webhooks -> %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func validateWebhookURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || len(raw) > maxWebhookURLLength {
+		return "", utils.NewAppError(http.StatusBadRequest, "A webhook URL is required")
+	}
+	endpoint, err := url.Parse(raw)
+	if err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Host == "" {
+		return "", utils.NewAppError(http.StatusBadRequest, "Webhook URL must be an absolute http or https URL")
+	}
+	if endpoint.User != nil {
+		return "", utils.NewAppError(http.StatusBadRequest, "Webhook URL must not contain credentials")
+	}
+	return endpoint.String(), nil
+}
+
+// checkWebhookHost rejects a webhook URL whose host resolves to an address
+// webhooks may not reach. It only fails fast; the dialer checks again on
+// every delivery.
+func (s *WebhookService) checkWebhookHost(ctx context.Context, endpoint string) error {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return utils.NewAppError(http.StatusBadRequest, "Webhook URL must be an absolute http or https URL")
+	}
+	host := parsed.Hostname()
+
+	var addrs []netip.Addr
+	if ip, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, ip)
+	} else if addrs, err = s.lookupIP(ctx, host); err != nil {
+		return utils.NewAppError(http.StatusBadRequest, "Webhook host "+host+" could not be resolved")
+	}
+	for _, ip := range addrs {
+		if !s.allowAddress(ip) {
+			return errWebhookAddress
+		}
+	}
+	return nil
+}
+
+func validateWebhookEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return nil, utils.NewAppError(http.StatusBadRequest, "At least one event type is required")
+	}
+	var valid []string
+	for _, eventType := range eventTypes {
+		if !containsField(models.WebhookEventTypes, eventType) {
+			return nil, utils.NewAppError(http.StatusBadRequest, "Unknown event type "+eventType)
+		}
+		if !containsField(valid, eventType) {
+			valid = append(valid, eventType)
+		}
+	}
+	return valid, nil
+}
+
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return s[:length]
+}
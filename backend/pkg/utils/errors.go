@@ -3,8 +3,36 @@ package utils
 type AppError struct {
 	Message string
 	Code    int
+	Err     error // underlying cause, logged but never shown to clients
+}
+
+func NewAppError(code int, message string) *AppError {
+	return &AppError{Message: message, Code: code}
+}
+
+func WrapAppError(code int, message string, err error) *AppError {
+	return &AppError{Message: message, Code: code, Err: err}
 }
 
 func (e *AppError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
 	return e.Message
 }
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// PartialResultError is returned when a request could only be answered in
+// part, e.g. some breaches could not be checked. Unchecked names what was
+// left out.
+type PartialResultError struct {
+	*AppError
+	Unchecked []string
+}
+
+func (e *PartialResultError) Unwrap() error {
+	return e.AppError
+}
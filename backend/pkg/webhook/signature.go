@@ -0,0 +1,70 @@
+// Package webhook signs webhook requests and lets receivers verify them.
+//
+// Every request carries the Unix time it was signed at in TimestampHeader
+// and "v1=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>", keyed
+// with the webhook secret, in SignatureHeader. Receivers should reject
+// requests whose timestamp is older than a few minutes, which stops a
+// captured request from being replayed later.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-BreachRadar-Signature"
+	TimestampHeader = "X-BreachRadar-Timestamp"
+	EventHeader     = "X-BreachRadar-Event"
+	DeliveryHeader  = "X-BreachRadar-Delivery"
+
+	// DefaultTolerance is how far a timestamp may be from the receiver's
+	// clock, in either direction.
+	DefaultTolerance = 5 * time.Minute
+
+	signatureVersion = "v1="
+)
+
+var (
+	ErrMissingSignature = errors.New("webhook: missing signature or timestamp")
+	ErrInvalidTimestamp = errors.New("webhook: timestamp outside tolerance")
+	ErrInvalidSignature = errors.New("webhook: signature mismatch")
+)
+
+// Sign returns the SignatureHeader value for body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signatureVersion + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the header values of a received request. The signature
+// header may list several comma separated signatures, so secrets can be
+// rotated without dropping requests.
+func Verify(secret, timestampHeader, signatureHeader string, body []byte, now time.Time, tolerance time.Duration) error {
+	if timestampHeader == "" || signatureHeader == "" {
+		return ErrMissingSignature
+	}
+	timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	if skew := now.Sub(time.Unix(timestamp, 0)); skew > tolerance || skew < -tolerance {
+		return ErrInvalidTimestamp
+	}
+
+	expected := Sign(secret, timestamp, body)
+	for _, signature := range strings.Split(signatureHeader, ",") {
+		if hmac.Equal([]byte(strings.TrimSpace(signature)), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"type":"breach.added"}`)
+	signed := strconv.FormatInt(now.Unix(), 10)
+	signature := Sign("secret", now.Unix(), body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		now       time.Time
+		wantErr   error
+	}{
+		{name: "valid", secret: "secret", timestamp: signed, signature: signature, body: body, now: now},
+		{name: "valid within tolerance", secret: "secret", timestamp: signed, signature: signature, body: body, now: now.Add(4 * time.Minute)},
+		{name: "rotated secret", secret: "secret", timestamp: signed, signature: Sign("old", now.Unix(), body) + ", " + signature, body: body, now: now},
+		{name: "replayed", secret: "secret", timestamp: signed, signature: signature, body: body, now: now.Add(10 * time.Minute), wantErr: ErrInvalidTimestamp},
+		{name: "from the future", secret: "secret", timestamp: signed, signature: signature, body: body, now: now.Add(-10 * time.Minute), wantErr: ErrInvalidTimestamp},
+		{name: "timestamp swapped", secret: "secret", timestamp: strconv.FormatInt(now.Unix()+1, 10), signature: signature, body: body, now: now, wantErr: ErrInvalidSignature},
+		{name: "tampered body", secret: "secret", timestamp: signed, signature: signature, body: []byte(`{"type":"breach.match"}`), now: now, wantErr: ErrInvalidSignature},
+		{name: "wrong secret", secret: "other", timestamp: signed, signature: signature, body: body, now: now, wantErr: ErrInvalidSignature},
+		{name: "missing signature", secret: "secret", timestamp: signed, body: body, now: now, wantErr: ErrMissingSignature},
+		{name: "malformed timestamp", secret: "secret", timestamp: "yesterday", signature: signature, body: body, now: now, wantErr: ErrInvalidTimestamp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.timestamp, tt.signature, tt.body, tt.now, DefaultTolerance)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
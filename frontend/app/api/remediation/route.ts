@@ -0,0 +1,31 @@
+import { type NextRequest, NextResponse } from "next/server"
+
+const BACKEND_URL = process.env.BACKEND_URL ?? "http://localhost:8080"
+
+export async function GET(request: NextRequest) {
+  try {
+    // Remediation advice comes from the Go backend knowledge base
+    // (GET /api/v0/remediation?field=email&field=password).
+    // Response shape:
+    // {
+    //   remediation: [{ fieldType, urgency, actions: string[] }],
+    // }
+    const params = new URLSearchParams(
+      request.nextUrl.searchParams.getAll("field").map((field) => ["field", field]),
+    )
+    const response = await fetch(`${BACKEND_URL}/api/v0/remediation?${params}`, {
+      next: { revalidate: 3600 },
+    })
+
+    if (!response.ok) {
+      console.error("Remediation backend error:", response.status)
+      return NextResponse.json({ error: "Internal server error" }, { status: 502 })
+    }
+
+    const remediation = await response.json()
+    return NextResponse.json(remediation)
+  } catch (error) {
+    console.error("Remediation error:", error)
+    return NextResponse.json({ error: "Internal server error" }, { status: 500 })
+  }
+}
@@ -1,59 +1,35 @@
 import { NextResponse } from "next/server"
 
+const BACKEND_URL = process.env.BACKEND_URL ?? "http://localhost:8080"
+
 export async function GET() {
   try {
-    // TODO: Replace with actual database queries
-    // This is where you would:
-    // 1. Query your breach database for real statistics
-    // 2. Calculate trends and percentages
-    // 3. Aggregate data by industry, severity, etc.
-    // 4. Return real-time statistics
+    // Statistics are computed and cached by the Go backend (GET /api/v0/statistics).
+    // Response shape:
+    // {
+    //   totalRecords: "15.2B",
+    //   totalRecordsChange: "+2.3B this month",
+    //   activeBreaches: 8547,
+    //   activeBreachesChange: 127,
+    //   dataSources: "450",
+    //   dataSourcesChange: "+12 new this month",
+    //   breachActivity: number[30], // breaches per day, oldest first
+    //   breachSeverity: { critical, high, medium, low }, // percentages
+    //   topIndustries: [{ industry, breaches, trend: "up" | "down" }],
+    //   dataTypes: [{ type: "Email Addresses", percentage }],
+    //   recentBreaches: [{ name, records: "X.XM records", timeAgo, severity }],
+    //   globalImpact: { northAmerica, europe, asiaPacific, otherRegions },
+    // }
+    const response = await fetch(`${BACKEND_URL}/api/v0/statistics`, {
+      next: { revalidate: 60 },
+    })
 
-    // Placeholder response structure
-    const statistics = {
-      totalRecords: "0", // e.g., "15.2B"
-      totalRecordsChange: "+0 this month", // e.g., "+2.3B this month"
-      activeBreaches: 1, // e.g., 8547
-      activeBreachesChange: 1, // e.g., 127
-      dataSources: "1", // e.g., "450+"
-      dataSourcesChange: "+1 verified this month", // e.g., "+12 verified this month"
-      privacyScore: "99.9%",
-      breachActivity: Array(30).fill(0), // Array of 30 numbers representing daily breach counts
-      breachSeverity: {
-        critical: 0, // Percentage
-        high: 0,
-        medium: 0,
-        low: 0,
-      },
-      topIndustries: [
-        // {
-        //   industry: "Technology",
-        //   breaches: 0,
-        //   trend: "up" | "down"
-        // }
-      ],
-      dataTypes: [
-        // {
-        //   type: "Email Addresses",
-        //   percentage: 0
-        // }
-      ],
-      recentBreaches: [
-        // {
-        //   name: "Breach Name",
-        //   records: "X.XM records",
-        //   timeAgo: "X days ago",
-        //   severity: "Critical" | "High" | "Medium" | "Low"
-        // }
-      ],
-      globalImpact: {
-        northAmerica: "0", // e.g., "4.2B"
-        europe: "0", // e.g., "3.8B"
-        asiaPacific: "0", // e.g., "5.1B"
-        otherRegions: "0", // e.g., "2.1B"
-      },
+    if (!response.ok) {
+      console.error("Statistics backend error:", response.status)
+      return NextResponse.json({ error: "Internal server error" }, { status: 502 })
     }
 
+    const statistics = await response.json()
     return NextResponse.json(statistics)
   } catch (error) {
     console.error("Statistics error:", error)
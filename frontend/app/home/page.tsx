@@ -27,76 +27,7 @@ import {
   MapPin,
   Calendar,
 } from "lucide-react"
-
-// Universal salt - in production, get this from environment or server
-const UNIVERSAL_SALT = "o129SGl7g21";
-
-// Hashing service
-class HashingService {
-  private universalSalt: string;
-  
-  constructor() {
-    this.universalSalt = UNIVERSAL_SALT;
-  }
-  
-  async generateFullHash(value: string, fieldType: string): Promise<string> {
-    const fieldSalt = this.getFieldSalt(fieldType);
-    const normalized = this.normalizeInput(value, fieldType);
-    const combined = this.universalSalt + fieldSalt + normalized;
-    
-    const encoder = new TextEncoder();
-    const data = encoder.encode(combined);
-    const hashBuffer = await crypto.subtle.digest('SHA-256', data);
-    
-    const hashArray = Array.from(new Uint8Array(hashBuffer));
-    return hashArray.map(b => b.toString(16).padStart(2, '0')).join('');
-  }
-  
-  generatePartialHash(fullHash: string): string {
-    return fullHash.substring(0, 6);
-  }
-  
-  private getFieldSalt(fieldType: string): string {
-    const fieldSalts: Record<string, string> = {
-      email: "email_salt",
-      phone: "phone_salt",
-      firstName: "fname_salt", 
-      lastName: "lname_salt",
-      ssn: "ssn_salt",
-      creditCard: "cc_salt",
-      password: "password_salt",
-      username: "username_salt",
-      address: "address_salt",
-      city: "city_salt",
-      state: "state_salt",
-      zipCode: "zip_salt",
-      country: "country_salt",
-      dateOfBirth: "dob_salt",
-      driverLicense: "dl_salt",
-      passport: "passport_salt"
-    };
-    return fieldSalts[fieldType] || "default_salt";
-  }
-  
-  private normalizeInput(value: string, fieldType: string): string {
-    value = value.trim();
-    switch (fieldType) {
-      case "email":
-        return value.toLowerCase();
-      case "phone":
-        return value.replace(/\D/g, '');
-      case "firstName":
-      case "lastName":
-        return value.toLowerCase();
-      case "ssn":
-      case "creditCard":
-      case "driverLicense":
-        return value.replace(/\D/g, '');
-      default:
-        return value.toLowerCase();
-    }
-  }
-}
+import { HashingService } from "@/lib/hashingService"
 
 interface SearchField {
   id: string
@@ -311,7 +242,9 @@ export default function SearchPage() {
     const processedBreaches: BreachSummary[] = [];
     
     for (const breach of serverResults.exactMatches || []) {
-      const severity = calculateDynamicSeverity(breach.matchedFields, breach.affectedRecords);
+      // The backend scores breaches with weights analysts can tune; the
+      // local estimate only covers older backends
+      const severity = breach.severity ?? calculateDynamicSeverity(breach.matchedFields, breach.affectedRecords);
       
       processedBreaches.push({
         name: breach.name,
@@ -320,7 +253,7 @@ export default function SearchPage() {
         severity: severity,
         matchedFields: breach.matchedFields,
         partialMatch: breach.partialMatch,
-        riskScore: calculateRiskScore(breach.matchedFields),
+        riskScore: breach.riskScore ?? calculateRiskScore(breach.matchedFields),
         timeAgo: formatTimeAgo(breach.date)
       });
     }
@@ -367,7 +300,7 @@ export default function SearchPage() {
       }
       
       if (matchedFields.length > 0) {
-        const severity = calculateDynamicSeverity(matchedFields, breach.affectedRecords);
+        const severity = breach.severity ?? calculateDynamicSeverity(matchedFields, breach.affectedRecords);
         
         verifiedBreaches.push({
           name: breach.name,
@@ -376,7 +309,7 @@ export default function SearchPage() {
           severity: severity,
           matchedFields: matchedFields,
           partialMatch: false,
-          riskScore: calculateRiskScore(matchedFields),
+          riskScore: breach.riskScore ?? calculateRiskScore(matchedFields),
           timeAgo: formatTimeAgo(breach.date)
         });
       }
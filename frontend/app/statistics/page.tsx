@@ -67,6 +67,37 @@ interface AccessibleStats {
   }>
 }
 
+// Data types whose protection tips come from the backend remediation
+// knowledge base, keyed by the field types they cover. The tips written
+// below are kept when the knowledge base can't be reached.
+const dataTypeFields: Record<string, string[]> = {
+  "Credit Card Information": ["creditCard"],
+  "Social Security Numbers": ["ssn"],
+  "Email & Passwords": ["email", "password"],
+}
+
+interface Remediation {
+  fieldType: string
+  urgency: string
+  actions: string[]
+}
+
+const fetchRemediationActions = async (): Promise<Record<string, string[]>> => {
+  const fields = Object.values(dataTypeFields).flat()
+  const params = new URLSearchParams(fields.map((field) => ["field", field]))
+  const response = await fetch(`/api/remediation?${params}`)
+  if (!response.ok) {
+    throw new Error(`Remediation request failed: ${response.status}`)
+  }
+
+  const { remediation } = (await response.json()) as { remediation: Remediation[] }
+  const actions: Record<string, string[]> = {}
+  for (const entry of remediation) {
+    actions[entry.fieldType] = [...(actions[entry.fieldType] ?? []), ...entry.actions]
+  }
+  return actions
+}
+
 export default function StatisticsPage() {
   const [stats, setStats] = useState<AccessibleStats | null>(null)
   const [loading, setLoading] = useState(true)
@@ -356,6 +387,20 @@ export default function StatisticsPage() {
           },
         ],
       }
+
+      try {
+        const actions = await fetchRemediationActions()
+        for (const data of statistics.dataWorthAndRisk) {
+          const fields = dataTypeFields[data.dataType]
+          const tips = fields?.flatMap((field) => actions[field] ?? []) ?? []
+          if (tips.length > 0) {
+            data.protectionTips = tips
+          }
+        }
+      } catch (err) {
+        console.warn("Could not load protection tips:", err)
+      }
+
       setStats(statistics)
       setError(null)
     } catch (err) {
@@ -0,0 +1,25 @@
+// Run with `npm test` (Node 22.6+). The vectors are shared with
+// backend/pkg/hashing, which must produce the same hashes.
+import { test } from "node:test"
+import assert from "node:assert/strict"
+import { readFileSync } from "node:fs"
+import { HashingService } from "./hashingService.ts"
+
+interface GoldenVectors {
+  universalSalt: string
+  vectors: { fieldType: string; value: string; normalized: string; fullHash: string; partialHash: string }[]
+}
+
+const golden: GoldenVectors = JSON.parse(
+  readFileSync(new URL("../../testdata/hashing-vectors.json", import.meta.url), "utf8"),
+)
+
+test("hashes match the golden vectors", async () => {
+  const hashingService = new HashingService(golden.universalSalt)
+
+  for (const vector of golden.vectors) {
+    const fullHash = await hashingService.generateFullHash(vector.value, vector.fieldType)
+    assert.equal(fullHash, vector.fullHash, `${vector.fieldType} ${JSON.stringify(vector.value)}`)
+    assert.equal(hashingService.generatePartialHash(fullHash), vector.partialHash)
+  }
+})
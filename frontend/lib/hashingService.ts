@@ -1,10 +1,13 @@
-const UNIVERSAL_SALT = "your-super-secret-universal-salt-breachguard";
+// Must match the backend's UNIVERSAL_SALT. The normalization and salting
+// rules below are mirrored by backend/pkg/hashing; both are checked against
+// testdata/hashing-vectors.json.
+const UNIVERSAL_SALT = process.env.NEXT_PUBLIC_UNIVERSAL_SALT ?? "o129SGl7g21";
 
 export class HashingService {
   private universalSalt: string;
   
-  constructor() {
-    this.universalSalt = UNIVERSAL_SALT;
+  constructor(universalSalt: string = UNIVERSAL_SALT) {
+    this.universalSalt = universalSalt;
   }
   
   // Generate full hash for user data